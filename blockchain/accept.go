@@ -12,56 +12,138 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
 )
 
-// checkCoinbaseUniqueHeight checks to ensure that for all blocks height > 1
-// that the coinbase contains the height encoding to make coinbase hash collisions
-// impossible.
-func checkCoinbaseUniqueHeight(blockHeight int64, block *dcrutil.Block) error {
+// extractCoinbaseTxHeight returns the block height encoded in the second
+// output of the passed coinbase transaction.
+func extractCoinbaseTxHeight(tx *wire.MsgTx) (uint32, error) {
 	// Coinbase TxOut[0] is always tax, TxOut[1] is always
 	// height + extranonce, so at least two outputs must
 	// exist.
-	if len(block.MsgBlock().Transactions[0].TxOut) < 2 {
-		str := fmt.Sprintf("block %v is missing necessary coinbase "+
-			"outputs", block.Hash())
-		return ruleError(ErrFirstTxNotCoinbase, str)
+	if len(tx.TxOut) < 2 {
+		str := fmt.Sprintf("coinbase transaction %v is missing "+
+			"necessary outputs", tx.TxHash())
+		return 0, ruleError(ErrFirstTxNotCoinbase, str)
 	}
 
 	// The first 4 bytes of the NullData output must be the
 	// encoded height of the block, so that every coinbase
 	// created has a unique transaction hash.
-	nullData, err := txscript.GetNullDataContent(
-		block.MsgBlock().Transactions[0].TxOut[1].Version,
-		block.MsgBlock().Transactions[0].TxOut[1].PkScript)
+	nullData, err := txscript.GetNullDataContent(tx.TxOut[1].Version,
+		tx.TxOut[1].PkScript)
 	if err != nil {
-		str := fmt.Sprintf("block %v txOut 1 has wrong pkScript "+
-			"type", block.Hash())
-		return ruleError(ErrFirstTxNotCoinbase, str)
+		str := fmt.Sprintf("coinbase transaction %v txOut 1 has wrong "+
+			"pkScript type", tx.TxHash())
+		return 0, ruleError(ErrFirstTxNotCoinbase, str)
 	}
 
 	if len(nullData) < 4 {
-		str := fmt.Sprintf("block %v txOut 1 has too short nullData "+
-			"push to contain height", block.Hash())
-		return ruleError(ErrFirstTxNotCoinbase, str)
+		str := fmt.Sprintf("coinbase transaction %v txOut 1 has too "+
+			"short nullData push to contain height", tx.TxHash())
+		return 0, ruleError(ErrFirstTxNotCoinbase, str)
+	}
+
+	return binary.LittleEndian.Uint32(nullData[0:4]), nil
+}
+
+// ExtractCoinbaseHeight returns the block height encoded in the passed coinbase
+// transaction.  Every coinbase after the first block is required to encode the
+// height of the block which contains it in the data pushed by the provably
+// pruneable script of its second output as a 4-byte little-endian integer.
+//
+// A RuleError with the ErrFirstTxNotCoinbase error code is returned when the
+// transaction is not a coinbase or it does not encode a height.
+func ExtractCoinbaseHeight(coinbase *dcrutil.Tx) (int64, error) {
+	msgTx := coinbase.MsgTx()
+	if !IsCoinBaseTx(msgTx) {
+		str := fmt.Sprintf("transaction %v is not a coinbase",
+			coinbase.Hash())
+		return 0, ruleError(ErrFirstTxNotCoinbase, str)
+	}
+
+	height, err := extractCoinbaseTxHeight(msgTx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(height), nil
+}
+
+// checkCoinbaseTxHeight checks to ensure that the passed coinbase transaction
+// contains the encoding of the provided block height in its second output
+// which makes coinbase hash collisions impossible.
+func checkCoinbaseTxHeight(blockHeight int64, tx *wire.MsgTx) error {
+	cbHeight, err := extractCoinbaseTxHeight(tx)
+	if err != nil {
+		return err
 	}
 
 	// Check the height and ensure it is correct.
-	cbHeight := binary.LittleEndian.Uint32(nullData[0:4])
 	if cbHeight != uint32(blockHeight) {
-		prevBlock := block.MsgBlock().Header.PrevBlock
-		str := fmt.Sprintf("block %v txOut 1 has wrong height in "+
-			"coinbase; want %v, got %v; prevBlock %v, header height %v",
-			block.Hash(), blockHeight, cbHeight, prevBlock,
-			block.MsgBlock().Header.Height)
+		str := fmt.Sprintf("coinbase transaction %v txOut 1 has wrong "+
+			"height; want %v, got %v", tx.TxHash(), blockHeight,
+			cbHeight)
 		return ruleError(ErrCoinbaseHeight, str)
 	}
 
 	return nil
 }
 
+// checkCoinbaseUniqueHeight checks to ensure that for all blocks height > 1
+// that the coinbase contains the height encoding to make coinbase hash collisions
+// impossible.
+func checkCoinbaseUniqueHeight(blockHeight int64, block *dcrutil.Block) error {
+	return checkCoinbaseTxHeight(blockHeight, block.MsgBlock().Transactions[0])
+}
+
+// CheckCoinbase performs the checks that are applied to the coinbase of a block
+// at the provided height that can be done on the coinbase transaction in
+// isolation.  This is useful for verifying coinbase transactions built for
+// block templates prior to mining them.
+//
+// The transaction must be a coinbase that passes the same sanity checks as any
+// other transaction, encode the passed height in its second output for all
+// heights after the first block, and claim exactly the expected subsidy in its
+// input.  The expected subsidy is the sum of the proof-of-work and
+// organization subsidies for the block, or the block one subsidy for the first
+// block, and does not include any transaction fees.
+//
+// Note that the checks which depend on the rest of the block, such as the
+// total output value relative to the fees of the other transactions in the
+// block and the organization subsidy relative to the number of voters, are
+// NOT performed.
+//
+// Each failure is returned as a RuleError.
+func CheckCoinbase(tx *dcrutil.Tx, height int64, expectedSubsidy int64, params *chaincfg.Params) error {
+	msgTx := tx.MsgTx()
+	if !IsCoinBaseTx(msgTx) {
+		str := fmt.Sprintf("transaction %v is not a coinbase", tx.Hash())
+		return ruleError(ErrFirstTxNotCoinbase, str)
+	}
+
+	if err := CheckTransactionSanity(msgTx, params); err != nil {
+		return err
+	}
+
+	if height > 1 {
+		if err := checkCoinbaseTxHeight(height, msgTx); err != nil {
+			return err
+		}
+	}
+
+	if height > 0 && msgTx.TxIn[0].ValueIn != expectedSubsidy {
+		str := fmt.Sprintf("bad coinbase subsidy in input; got %v, "+
+			"expected %v", msgTx.TxIn[0].ValueIn, expectedSubsidy)
+		return ruleError(ErrBadCoinbaseAmountIn, str)
+	}
+
+	return nil
+}
+
 // IsFinalizedTransaction determines whether or not a transaction is finalized.
 func IsFinalizedTransaction(tx *dcrutil.Tx, blockHeight int64, blockTime time.Time) bool {
 	// Lock time of zero means the transaction is finalized.
@@ -105,7 +187,10 @@ func IsFinalizedTransaction(tx *dcrutil.Tx, blockHeight int64, blockTime time.Ti
 //
 // The flags are also passed to checkBlockHeaderContext.  See its documentation
 // for how the flags modify its behavior.
-func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode, flags BehaviorFlags) error {
+//
+// The passed rule overrider, which may be nil, is consulted for each failed
+// check.
+func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode, flags BehaviorFlags, overrider *ruleOverrider) error {
 	// The genesis block is valid by definition.
 	if prevNode == nil {
 		return nil
@@ -113,7 +198,7 @@ func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode
 
 	// Perform all block header related validation checks.
 	header := &block.MsgBlock().Header
-	err := b.checkBlockHeaderContext(header, prevNode, flags)
+	err := b.checkBlockHeaderContext(header, prevNode, flags, overrider)
 	if err != nil {
 		return err
 	}
@@ -132,7 +217,10 @@ func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode
 			str := fmt.Sprintf("serialized block is too big - "+
 				"got %d, max %d", serializedSize,
 				maxBlockSize)
-			return ruleError(ErrBlockTooBig, str)
+			err := overrider.check(ruleError(ErrBlockTooBig, str))
+			if err != nil {
+				return err
+			}
 		}
 
 		// Switch to using the past median time of the block prior to
@@ -161,14 +249,22 @@ func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode
 			if !IsFinalizedTransaction(tx, blockHeight, blockTime) {
 				str := fmt.Sprintf("block contains unfinalized regular "+
 					"transaction %v", tx.Hash())
-				return ruleError(ErrUnfinalizedTx, str)
+				err := overrider.check(ruleError(
+					ErrUnfinalizedTx, str))
+				if err != nil {
+					return err
+				}
 			}
 		}
 		for _, stx := range block.STransactions() {
 			if !IsFinalizedTransaction(stx, blockHeight, blockTime) {
 				str := fmt.Sprintf("block contains unfinalized stake "+
 					"transaction %v", stx.Hash())
-				return ruleError(ErrUnfinalizedTx, str)
+				err := overrider.check(ruleError(
+					ErrUnfinalizedTx, str))
+				if err != nil {
+					return err
+				}
 			}
 		}
 
@@ -177,13 +273,18 @@ func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode
 		if blockHeight != int64(block.MsgBlock().Header.Height) {
 			errStr := fmt.Sprintf("Block header height invalid; expected %v"+
 				" but %v was found", blockHeight, header.Height)
-			return ruleError(ErrBadBlockHeight, errStr)
+			err := overrider.check(ruleError(ErrBadBlockHeight,
+				errStr))
+			if err != nil {
+				return err
+			}
 		}
 
 		// Check that the coinbase contains at minimum the block
 		// height in output 1.
 		if blockHeight > 1 {
-			err := checkCoinbaseUniqueHeight(blockHeight, block)
+			err := overrider.check(checkCoinbaseUniqueHeight(
+				blockHeight, block))
 			if err != nil {
 				return err
 			}
@@ -262,7 +363,8 @@ func (b *BlockChain) maybeAcceptBlock(block *dcrutil.Block, flags BehaviorFlags)
 
 	// The block must pass all of the validation rules which depend on the
 	// position of the block within the block chain.
-	err = b.checkBlockContext(block, prevNode, flags)
+	err = b.checkBlockContext(block, prevNode, flags,
+		b.ruleOverrider(block))
 	if err != nil {
 		return false, err
 	}
@@ -302,10 +404,16 @@ func (b *BlockChain) maybeAcceptBlock(block *dcrutil.Block, flags BehaviorFlags)
 		return false, err
 	}
 
+	// Cache the accepted block since it is likely to be fetched again soon,
+	// such as when connecting its children or serving it to peers.
+	if !dryRun {
+		b.bodyCache.add(block)
+	}
+
 	// Notify the caller that the new block was accepted into the block
 	// chain.  The caller would typically want to react by relaying the
 	// inventory to other peers.
-	if !dryRun {
+	if !dryRun && !b.quietNotifications {
 		b.chainLock.Unlock()
 		b.sendNotification(NTBlockAccepted,
 			&BlockAcceptedNtfnsData{isMainChain, block})
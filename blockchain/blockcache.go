@@ -0,0 +1,128 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+)
+
+// blockBodyCache houses recently accessed blocks in memory so repeated fetches
+// of the same blocks, such as those performed during reorganizes and while
+// serving RPC clients, do not need to load them from the database.  The total
+// serialized size of the cached blocks is limited to a maximum number of bytes
+// and the least recently used blocks are evicted to make room for new ones.
+type blockBodyCache struct {
+	mtx       sync.Mutex
+	maxBytes  uint64
+	usedBytes uint64
+	lru       *list.List // Contains *dcrutil.Block.
+	elems     map[chainhash.Hash]*list.Element
+	hits      uint64
+	misses    uint64
+}
+
+// newBlockBodyCache returns a block body cache which holds blocks up to the
+// passed total serialized size in bytes.  A size of zero disables the cache.
+func newBlockBodyCache(maxBytes uint64) *blockBodyCache {
+	return &blockBodyCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		elems:    make(map[chainhash.Hash]*list.Element),
+	}
+}
+
+// lookup returns the cached block for the passed hash and marks it as the most
+// recently used block.  It returns nil when the block is not cached.
+//
+// This function is safe for concurrent access.
+func (c *blockBodyCache) lookup(hash *chainhash.Hash) *dcrutil.Block {
+	if c.maxBytes == 0 {
+		return nil
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	elem, ok := c.elems[*hash]
+	if !ok {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*dcrutil.Block)
+}
+
+// add adds the passed block to the cache as the most recently used block and
+// evicts the least recently used blocks as needed to remain within the maximum
+// size.  Blocks which are larger than the maximum size are not cached.
+//
+// This function is safe for concurrent access.
+func (c *blockBodyCache) add(block *dcrutil.Block) {
+	size := uint64(block.MsgBlock().SerializeSize())
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if elem, ok := c.elems[*block.Hash()]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	for c.usedBytes+size > c.maxBytes {
+		c.removeElem(c.lru.Back())
+	}
+	c.elems[*block.Hash()] = c.lru.PushFront(block)
+	c.usedBytes += size
+}
+
+// remove removes the block for the passed hash from the cache if it is cached.
+//
+// This function is safe for concurrent access.
+func (c *blockBodyCache) remove(hash *chainhash.Hash) {
+	c.mtx.Lock()
+	if elem, ok := c.elems[*hash]; ok {
+		c.removeElem(elem)
+	}
+	c.mtx.Unlock()
+}
+
+// removeElem removes the passed list element along with the block it houses
+// from the cache.
+//
+// This function MUST be called with the cache mutex held.
+func (c *blockBodyCache) removeElem(elem *list.Element) {
+	block := c.lru.Remove(elem).(*dcrutil.Block)
+	delete(c.elems, *block.Hash())
+	c.usedBytes -= uint64(block.MsgBlock().SerializeSize())
+}
+
+// hitRate returns the fraction of the lookups which found the requested block
+// in the cache.  It is zero when no lookups have been performed or the cache is
+// disabled.
+//
+// This function is safe for concurrent access.
+func (c *blockBodyCache) hitRate() float64 {
+	c.mtx.Lock()
+	hits, misses := c.hits, c.misses
+	c.mtx.Unlock()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// BlockCacheHitRate returns the fraction of the block fetches which consulted
+// the block body cache and were satisfied by it instead of the database.  It is
+// zero when the cache is disabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockCacheHitRate() float64 {
+	return b.bodyCache.hitRate()
+}
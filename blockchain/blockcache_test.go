@@ -0,0 +1,94 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// TestBlockBodyCache ensures the block body cache evicts the least recently
+// used blocks to remain within its maximum size and tracks its hit rate.
+func TestBlockBodyCache(t *testing.T) {
+	t.Parallel()
+
+	// newBlock returns a block with a unique hash for each passed nonce.
+	// All of the blocks have the same serialized size.
+	newBlock := func(nonce uint32) *dcrutil.Block {
+		var msgBlock wire.MsgBlock
+		msgBlock.Header.Nonce = nonce
+		msgBlock.AddTransaction(wire.NewMsgTx())
+		return dcrutil.NewBlock(&msgBlock)
+	}
+	blocks := []*dcrutil.Block{newBlock(0), newBlock(1), newBlock(2)}
+	blockSize := uint64(blocks[0].MsgBlock().SerializeSize())
+
+	// Ensure a disabled cache never holds blocks and does not count
+	// lookups.
+	cache := newBlockBodyCache(0)
+	cache.add(blocks[0])
+	if block := cache.lookup(blocks[0].Hash()); block != nil {
+		t.Fatalf("lookup: disabled cache returned block %v",
+			block.Hash())
+	}
+	if rate := cache.hitRate(); rate != 0 {
+		t.Fatalf("hitRate: unexpected disabled cache rate %v", rate)
+	}
+
+	// Fill a cache with room for two blocks, access the first one so the
+	// second one becomes the least recently used, and ensure adding the
+	// third one evicts the second one.
+	cache = newBlockBodyCache(blockSize * 2)
+	cache.add(blocks[0])
+	cache.add(blocks[1])
+	if block := cache.lookup(blocks[0].Hash()); block != blocks[0] {
+		t.Fatalf("lookup: did not find cached block %v",
+			blocks[0].Hash())
+	}
+	cache.add(blocks[2])
+	if cache.usedBytes != blockSize*2 {
+		t.Fatalf("unexpected used bytes - got %d, want %d",
+			cache.usedBytes, blockSize*2)
+	}
+	if block := cache.lookup(blocks[1].Hash()); block != nil {
+		t.Fatalf("lookup: least recently used block %v was not evicted",
+			blocks[1].Hash())
+	}
+	for _, block := range []*dcrutil.Block{blocks[0], blocks[2]} {
+		if cache.lookup(block.Hash()) != block {
+			t.Fatalf("lookup: did not find cached block %v",
+				block.Hash())
+		}
+	}
+
+	// Ensure removed blocks are no longer returned and free their space.
+	cache.remove(blocks[0].Hash())
+	if block := cache.lookup(blocks[0].Hash()); block != nil {
+		t.Fatalf("lookup: removed block %v was returned", block.Hash())
+	}
+	if cache.usedBytes != blockSize {
+		t.Fatalf("unexpected used bytes after removal - got %d, want "+
+			"%d", cache.usedBytes, blockSize)
+	}
+
+	// Ensure blocks larger than the cache are not added.
+	cache = newBlockBodyCache(blockSize - 1)
+	cache.add(blocks[0])
+	if block := cache.lookup(blocks[0].Hash()); block != nil {
+		t.Fatalf("lookup: oversized block %v was cached", block.Hash())
+	}
+
+	// Ensure the hit rate accounts for both hits and misses.
+	cache = newBlockBodyCache(blockSize)
+	cache.add(blocks[0])
+	cache.lookup(blocks[0].Hash())
+	cache.lookup(blocks[1].Hash())
+	if rate := cache.hitRate(); rate != 0.5 {
+		t.Fatalf("hitRate: unexpected rate - got %v, want %v", rate,
+			0.5)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/decred/dcrutil"
+)
+
+// BlocksEqual returns whether or not the passed blocks are identical.  The
+// blocks are compared by their serialized bytes, which are the authoritative
+// representation of a block, so that differences in the cached fields of the
+// blocks, such as their cached hashes and transactions, do not affect the
+// result.  The blocks are serialized anew rather than using any cached
+// serialization since the underlying wire blocks might have been modified after
+// it was cached.
+//
+// Two nil blocks are considered equal, while a nil block is never equal to a
+// non-nil block.  Blocks which fail to serialize are not considered equal.
+func BlocksEqual(a, b *dcrutil.Block) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	aBytes, err := a.MsgBlock().Bytes()
+	if err != nil {
+		return false
+	}
+	bBytes, err := b.MsgBlock().Bytes()
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
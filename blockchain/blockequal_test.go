@@ -0,0 +1,65 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrutil"
+)
+
+// TestBlocksEqual ensures blocks are compared by their serialized bytes
+// regardless of their cached fields and modifications made to them after their
+// serialization was cached.
+func TestBlocksEqual(t *testing.T) {
+	t.Parallel()
+
+	// newBlock returns a new block for the simnet genesis block which does
+	// not share any state with other blocks returned by it.
+	newBlock := func() *dcrutil.Block {
+		msgBlock := *chaincfg.SimNetParams.GenesisBlock
+		blockBytes, err := msgBlock.Bytes()
+		if err != nil {
+			t.Fatalf("unable to serialize block: %v", err)
+		}
+		block, err := dcrutil.NewBlockFromBytes(blockBytes)
+		if err != nil {
+			t.Fatalf("unable to deserialize block: %v", err)
+		}
+		return block
+	}
+
+	// Blocks which only differ in their cached fields.
+	cached := newBlock()
+	cached.Hash()
+	cached.Transactions()
+
+	// Block which was modified after its serialization was cached.
+	modified := newBlock()
+	if _, err := modified.Bytes(); err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+	modified.MsgBlock().Header.Nonce++
+
+	tests := []struct {
+		name string
+		a, b *dcrutil.Block
+		want bool
+	}{
+		{"same block", cached, cached, true},
+		{"different cached fields", cached, newBlock(), true},
+		{"modified after caching", modified, newBlock(), false},
+		{"both nil", nil, nil, true},
+		{"first nil", nil, newBlock(), false},
+		{"second nil", newBlock(), nil, false},
+	}
+	for i, test := range tests {
+		if got := BlocksEqual(test.a, test.b); got != test.want {
+			t.Errorf("Test #%d (%s) unexpected result - got %v, want %v",
+				i, test.name, got, test.want)
+		}
+	}
+}
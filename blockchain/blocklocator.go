@@ -178,3 +178,43 @@ func (b *BlockChain) LatestBlockLocator() (BlockLocator, error) {
 	b.chainLock.RUnlock()
 	return locator, nil
 }
+
+// LocatorFork returns the hash and height of the most recent block in the
+// passed block locator which is part of the main chain.  Since block locators
+// list hashes from the most recent block backwards, this is the first hash in
+// the locator the main chain contains, and it identifies the point where the
+// chain of the peer which sent the locator forks from the main chain.  This is
+// the serving-side counterpart to building block locators and is typically
+// used to determine where to start serving blocks or headers to the peer.
+//
+// The genesis block is returned when none of the hashes in the locator are part
+// of the main chain, including when the locator is empty.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LocatorFork(locator BlockLocator) (*chainhash.Hash, int64, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	forkHash := *b.chainParams.GenesisHash
+	var forkHeight int64
+	err := b.db.View(func(dbTx database.Tx) error {
+		for _, hash := range locator {
+			height, err := dbFetchHeightByHash(dbTx, hash)
+			if err != nil {
+				if isNotInMainChainErr(err) {
+					continue
+				}
+				return err
+			}
+
+			forkHash, forkHeight = *hash, height
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &forkHash, forkHeight, nil
+}
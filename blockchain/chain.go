@@ -203,9 +203,17 @@ type BlockChain struct {
 	dbInfo              *databaseInfo
 	chainParams         *chaincfg.Params
 	timeSource          MedianTimeSource
-	notifications       NotificationCallback
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
+	txLocator           TxLocator
+	quietProcessLog     bool
+	blockProcessedHook  BlockProcessedHook
+	preAcceptFilter     func(block *dcrutil.Block) error
+	ruleOverrides       map[ErrorCode]RuleOverrideFunc
+	blockSizeLimit      int64
+	scriptValWorkers    int
+	preferLowestHashTip bool
+	maxReorgDepth       int64
 
 	// subsidyCache is the cache that provides quick lookup of subsidy
 	// values.
@@ -226,6 +234,28 @@ type BlockChain struct {
 	index    map[chainhash.Hash]*blockNode
 	depNodes map[chainhash.Hash][]*blockNode
 
+	// validatedBlocks houses the hashes of blocks which have been proven to
+	// have valid scripts during this session.  It is protected by the chain
+	// lock.
+	validatedBlocks *validatedBlockCache
+
+	// avgBlockProcessTime is an exponentially weighted moving average of
+	// the time it takes to process a block.  It is used to estimate the
+	// time remaining to sync the chain.  It is protected by the chain lock.
+	avgBlockProcessTime time.Duration
+
+	// processLatencies houses the times it took to process the most recent
+	// blocks in order to provide processing latency percentiles.  It is
+	// protected by the chain lock.
+	processLatencies *latencyWindow
+
+	// dryRunView and dryRunStxos hold the utxo view and spent txouts that
+	// result from the most recent dry run of a block which extends the main
+	// chain.  They are used to provide the utxo diff for ProcessBlockDryRun
+	// and are protected by the chain lock.
+	dryRunView  *UtxoViewpoint
+	dryRunStxos []spentTxOut
+
 	// These fields are related to handling of orphan blocks.  They are
 	// protected by a combination of the chain lock and the orphan lock.
 	orphanLock     sync.RWMutex
@@ -235,12 +265,32 @@ type BlockChain struct {
 	blockCacheLock sync.RWMutex
 	blockCache     map[chainhash.Hash]*dcrutil.Block
 
+	// These fields house the callbacks which are subscribed to chain
+	// notifications.  They are protected by the notifications lock rather
+	// than the chain lock since notifications are sent while the chain lock
+	// is held.
+	notificationsLock sync.RWMutex
+	notifications     []*notificationSubscription
+
+	// notificationQueue houses notifications waiting to be delivered when
+	// asynchronous notification delivery is enabled.  It is nil otherwise.
+	notificationQueue *notificationQueue
+
+	// quietNotifications indicates notifications are suppressed because a
+	// block is being processed with the BFQuiet flag.  It is protected by
+	// the chain lock.
+	quietNotifications bool
+
 	// The block cache for mainchain blocks, to facilitate faster
 	// reorganizations.
 	mainchainBlockCacheLock sync.RWMutex
 	mainchainBlockCache     map[chainhash.Hash]*dcrutil.Block
 	mainchainBlockCacheSize int
 
+	// bodyCache houses recently accessed blocks regardless of the chain
+	// they are part of in order to reduce database fetches.
+	bodyCache *blockBodyCache
+
 	// These fields are related to checkpoint handling.  They are protected
 	// by the chain lock.
 	nextCheckpoint  *chaincfg.Checkpoint
@@ -424,6 +474,20 @@ func (b *BlockChain) FetchSubsidyCache() *SubsidyCache {
 	return b.subsidyCache
 }
 
+// SigCacheHitRate returns the fraction of the signature verifications performed
+// with the signature cache used by the chain instance which were satisfied by
+// the cache.  This includes any verifications performed by other users of a
+// shared cache, such as a transaction memory pool.  It is zero when the chain
+// instance does not use a signature cache.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SigCacheHitRate() float64 {
+	if b.sigCache == nil {
+		return 0
+	}
+	return b.sigCache.HitRate()
+}
+
 // HaveBlock returns whether or not the chain instance has the block represented
 // by the passed hash.  This includes checking the various places a block can
 // be like part of the main chain, on a side chain, or in the orphan pool.
@@ -440,6 +504,55 @@ func (b *BlockChain) HaveBlock(hash *chainhash.Hash) (bool, error) {
 	return exists || b.IsKnownOrphan(hash), nil
 }
 
+// FilterKnownBlocks returns the hashes from the passed list that represent
+// blocks the chain instance does not have in the same order they appear in the
+// list.  The same places HaveBlock checks are checked for each hash, however,
+// the checks are performed for all hashes while only acquiring the locks and a
+// database transaction once.
+//
+// Any hashes which can't be checked due to a database error are treated as
+// unknown.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FilterKnownBlocks(hashes []chainhash.Hash) []chainhash.Hash {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+
+	// Filter the hashes for blocks in the memory chain index (could be main
+	// chain or side chain blocks) and orphans first.
+	var unknown []chainhash.Hash
+	for i := range hashes {
+		if _, ok := b.index[hashes[i]]; ok {
+			continue
+		}
+		if _, ok := b.orphans[hashes[i]]; ok {
+			continue
+		}
+		unknown = append(unknown, hashes[i])
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	// Filter the remaining hashes for blocks in the database.
+	var filtered []chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		for i := range unknown {
+			exists, err := dbTx.HasBlock(&unknown[i])
+			if err != nil || !exists {
+				filtered = append(filtered, unknown[i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return unknown
+	}
+	return filtered
+}
+
 // IsKnownOrphan returns whether the passed hash is currently a known orphan.
 // Keep in mind that only a limited number of orphans are held onto for a
 // limited amount of time, so this function must not be used as an absolute
@@ -648,10 +761,11 @@ func (b *BlockChain) loadBlockNode(dbTx database.Tx, hash *chainhash.Hash) (*blo
 		node.parent = parentNode
 	} else if childNodes, ok := b.depNodes[*hash]; ok {
 		// Case 2 -- This node is the parent of one or more nodes.
-		// Update the node's work sum by subtracting this node's work
-		// from the sum of its first child, and connect the node to all
-		// of its children.
-		node.workSum.Sub(childNodes[0].workSum, node.workSum)
+		// Update the node's work sum by subtracting the work of its
+		// first child from the sum of that child, and connect the node
+		// to all of its children.
+		child := childNodes[0]
+		node.workSum.Sub(child.workSum, CalcWork(child.header.Bits))
 		for _, childNode := range childNodes {
 			childNode.parent = node
 			node.children = append(node.children, childNode)
@@ -831,18 +945,101 @@ func (b *BlockChain) ancestorNode(node *blockNode, height int64) (*blockNode, er
 	return iterNode, nil
 }
 
-// fetchBlockFromHash searches the internal chain block stores and the database in
-// an attempt to find the block.  If it finds the block, it returns it.
+// lookupNode returns the block node for the passed hash.  Side chain nodes and
+// recent main chain nodes are always in the block index, while main chain
+// nodes that have been pruned from memory are dynamically loaded from the
+// database.  An error is returned when the block is not known.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) lookupNode(hash *chainhash.Hash) (*blockNode, error) {
+	if node, ok := b.index[*hash]; ok {
+		return node, nil
+	}
+
+	var inMainChain bool
+	b.db.View(func(dbTx database.Tx) error {
+		inMainChain = dbMainChainHasBlock(dbTx, hash)
+		return nil
+	})
+	if !inMainChain {
+		return nil, fmt.Errorf("block %v is not known", hash)
+	}
+	return b.findNode(hash, 0)
+}
+
+// CommonAncestor returns the hash of the most recent block that is an
+// ancestor of both of the passed blocks, which is the fork point when the
+// blocks are on different branches of the chain.  When one of the blocks is an
+// ancestor of the other, its hash is returned.  The blocks may be on either
+// the main chain or a side chain, and an error is returned when either of them
+// is not known.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CommonAncestor(hashA, hashB *chainhash.Hash) (*chainhash.Hash, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	nodeA, err := b.lookupNode(hashA)
+	if err != nil {
+		return nil, err
+	}
+	nodeB, err := b.lookupNode(hashB)
+	if err != nil {
+		return nil, err
+	}
+
+	// The main chain is linear, so the common ancestor of two blocks which
+	// are both in it is simply the one with the lower height.
+	if nodeA.inMainChain && nodeB.inMainChain {
+		if nodeA.height < nodeB.height {
+			return &nodeA.hash, nil
+		}
+		return &nodeB.hash, nil
+	}
+
+	// Move the higher of the two nodes back to the height of the lower one
+	// so both branches can be walked backwards in lockstep.
+	if nodeA.height > nodeB.height {
+		nodeA, err = b.ancestorNode(nodeA, nodeB.height)
+	} else if nodeB.height > nodeA.height {
+		nodeB, err = b.ancestorNode(nodeB, nodeA.height)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk both branches backwards until they meet.  Since both nodes are
+	// at the same height, they meet at the fork point.
+	for nodeA != nil && nodeB != nil && nodeA.hash != nodeB.hash {
+		nodeA, err = b.getPrevNodeFromNode(nodeA)
+		if err != nil {
+			return nil, err
+		}
+		nodeB, err = b.getPrevNodeFromNode(nodeB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if nodeA == nil || nodeB == nil {
+		return nil, AssertError(fmt.Sprintf("blocks %v and %v do not "+
+			"share a common ancestor", hashA, hashB))
+	}
+
+	return &nodeA.hash, nil
+}
+
+// fetchCachedBlock searches the internal chain block stores in an attempt to
+// find the block without consulting the database.  It returns nil when the
+// block is not in any of them.
 //
 // This function is NOT safe for concurrent access.
-func (b *BlockChain) fetchBlockFromHash(hash *chainhash.Hash) (*dcrutil.Block,
-	error) {
+func (b *BlockChain) fetchCachedBlock(hash *chainhash.Hash) *dcrutil.Block {
 	// Check side chain block cache
 	b.blockCacheLock.RLock()
 	blockSidechain, existsSidechain := b.blockCache[*hash]
 	b.blockCacheLock.RUnlock()
 	if existsSidechain {
-		return blockSidechain, nil
+		return blockSidechain
 	}
 
 	// Check orphan cache
@@ -850,7 +1047,7 @@ func (b *BlockChain) fetchBlockFromHash(hash *chainhash.Hash) (*dcrutil.Block,
 	orphan, existsOrphans := b.orphans[*hash]
 	b.orphanLock.RUnlock()
 	if existsOrphans {
-		return orphan.block, nil
+		return orphan.block
 	}
 
 	// Check main chain
@@ -858,6 +1055,20 @@ func (b *BlockChain) fetchBlockFromHash(hash *chainhash.Hash) (*dcrutil.Block,
 	block, ok := b.mainchainBlockCache[*hash]
 	b.mainchainBlockCacheLock.RUnlock()
 	if ok {
+		return block
+	}
+
+	// Check recently accessed blocks
+	return b.bodyCache.lookup(hash)
+}
+
+// fetchBlockFromHash searches the internal chain block stores and the database in
+// an attempt to find the block.  If it finds the block, it returns it.
+//
+// This function is NOT safe for concurrent access.
+func (b *BlockChain) fetchBlockFromHash(hash *chainhash.Hash) (*dcrutil.Block,
+	error) {
+	if block := b.fetchCachedBlock(hash); block != nil {
 		return block, nil
 	}
 
@@ -868,6 +1079,7 @@ func (b *BlockChain) fetchBlockFromHash(hash *chainhash.Hash) (*dcrutil.Block,
 		return err
 	})
 	if errFetchMainchain == nil && blockMainchain != nil {
+		b.bodyCache.add(blockMainchain)
 		return blockMainchain, nil
 	}
 
@@ -975,6 +1187,75 @@ func (b *BlockChain) isMajorityVersion(minVer int32, startNode *blockNode, numRe
 	return numFound >= numRequired
 }
 
+// maxEnforcedBlockVersion returns the newest block version the block version
+// upgrade rules enforce once a majority of the network has upgraded to it.
+// Version 6 is only enforced on networks other than the main network.
+func (b *BlockChain) maxEnforcedBlockVersion() int32 {
+	if b.chainParams.Net != wire.MainNet {
+		return 6
+	}
+	return 5
+}
+
+// calcMinRequiredBlockVersion returns the minimum version a block which builds
+// on the passed node must have in order to not be rejected by the block version
+// upgrade rules.  Each version from version 2 onwards is required once a
+// majority of the network has upgraded to it.  Version 1 is returned when a
+// majority has not yet upgraded to version 2, in which case no versions are
+// rejected.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) calcMinRequiredBlockVersion(prevNode *blockNode) int32 {
+	for version := b.maxEnforcedBlockVersion(); version > 1; version-- {
+		if b.isMajorityVersion(version, prevNode,
+			b.chainParams.BlockRejectNumRequired) {
+
+			return version
+		}
+	}
+	return 1
+}
+
+// isBlockVersionTooOld returns whether or not a block with the passed version
+// which builds on the passed node is rejected by the block version upgrade
+// rules.  This is the case when a majority of the network has upgraded to a
+// newer version.  Unlike calcMinRequiredBlockVersion, only the versions newer
+// than the passed version are checked, so blocks which already have the newest
+// enforced version do not require any of the previous blocks to be examined.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) isBlockVersionTooOld(blockVersion int32, prevNode *blockNode) bool {
+	maxVersion := b.maxEnforcedBlockVersion()
+	if blockVersion >= maxVersion {
+		return false
+	}
+	version := blockVersion + 1
+	if version < 2 {
+		version = 2
+	}
+	for ; version <= maxVersion; version++ {
+		if b.isMajorityVersion(version, prevNode,
+			b.chainParams.BlockRejectNumRequired) {
+
+			return true
+		}
+	}
+	return false
+}
+
+// CalcNextBlockVersion returns the minimum version the block after the end of
+// the current best chain must have in order to not be rejected by the block
+// version upgrade rules, which depend on the versions of the recent blocks in
+// the chain.  Miners may use a newer version, but must never use an older one.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CalcNextBlockVersion() int32 {
+	b.chainLock.Lock()
+	version := b.calcMinRequiredBlockVersion(b.bestNode)
+	b.chainLock.Unlock()
+	return version
+}
+
 // calcPastMedianTime calculates the median time of the previous few blocks
 // prior to, and including, the passed block node.  It is primarily used to
 // validate new blocks have sane timestamps.
@@ -1257,44 +1538,51 @@ func (b *BlockChain) connectBlock(node *blockNode, block *dcrutil.Block, view *U
 	b.stateSnapshot = state
 	b.stateLock.Unlock()
 
-	// Send stake notifications about the new block.
-	if node.height >= b.chainParams.StakeEnabledHeight {
+	// Gather the stake notifications about the new block so they can be
+	// sent once the chain lock is released.
+	var spentAndMissedNtfn, newTicketsNtfn *TicketNotificationsData
+	if node.height >= b.chainParams.StakeEnabledHeight &&
+		!b.quietNotifications {
+
 		nextStakeDiff, err := b.calcNextRequiredStakeDifficulty(node)
 		if err != nil {
 			return err
 		}
 
-		// Notify of spent and missed tickets
-		b.sendNotification(NTSpentAndMissedTickets,
-			&TicketNotificationsData{
-				Hash:            node.hash,
-				Height:          node.height,
-				StakeDifficulty: nextStakeDiff,
-				TicketsSpent:    node.stakeNode.SpentByBlock(),
-				TicketsMissed:   node.stakeNode.MissedByBlock(),
-				TicketsNew:      []chainhash.Hash{},
-			})
-		// Notify of new tickets
-		b.sendNotification(NTNewTickets,
-			&TicketNotificationsData{
-				Hash:            node.hash,
-				Height:          node.height,
-				StakeDifficulty: nextStakeDiff,
-				TicketsSpent:    []chainhash.Hash{},
-				TicketsMissed:   []chainhash.Hash{},
-				TicketsNew:      node.stakeNode.NewTickets(),
-			})
+		spentAndMissedNtfn = &TicketNotificationsData{
+			Hash:            node.hash,
+			Height:          node.height,
+			StakeDifficulty: nextStakeDiff,
+			TicketsSpent:    node.stakeNode.SpentByBlock(),
+			TicketsMissed:   node.stakeNode.MissedByBlock(),
+			TicketsNew:      []chainhash.Hash{},
+		}
+		newTicketsNtfn = &TicketNotificationsData{
+			Hash:            node.hash,
+			Height:          node.height,
+			StakeDifficulty: nextStakeDiff,
+			TicketsSpent:    []chainhash.Hash{},
+			TicketsMissed:   []chainhash.Hash{},
+			TicketsNew:      node.stakeNode.NewTickets(),
+		}
 	}
 
 	// Assemble the current block and the parent into a slice.
 	blockAndParent := []*dcrutil.Block{block, parent}
 
-	// Notify the caller that the block was connected to the main chain.
-	// The caller would typically want to react with actions such as
-	// updating wallets.
-	b.chainLock.Unlock()
-	b.sendNotification(NTBlockConnected, blockAndParent)
-	b.chainLock.Lock()
+	// Notify the caller of the spent, missed, and new tickets followed by
+	// the block being connected to the main chain.  The caller would
+	// typically want to react with actions such as updating wallets.
+	if !b.quietNotifications {
+		b.chainLock.Unlock()
+		if spentAndMissedNtfn != nil {
+			b.sendNotification(NTSpentAndMissedTickets,
+				spentAndMissedNtfn)
+			b.sendNotification(NTNewTickets, newTicketsNtfn)
+		}
+		b.sendNotification(NTBlockConnected, blockAndParent)
+		b.chainLock.Lock()
+	}
 
 	// Optimization: Before checkpoints, immediately dump the parent's stake
 	// node because we no longer need it.
@@ -1461,9 +1749,11 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *dcrutil.Block, view
 	// Notify the caller that the block was disconnected from the main
 	// chain.  The caller would typically want to react with actions such as
 	// updating wallets.
-	b.chainLock.Unlock()
-	b.sendNotification(NTBlockDisconnected, blockAndParent)
-	b.chainLock.Lock()
+	if !b.quietNotifications {
+		b.chainLock.Unlock()
+		b.sendNotification(NTBlockDisconnected, blockAndParent)
+		b.chainLock.Lock()
+	}
 
 	b.dropMainChainBlockCache(block)
 
@@ -1649,9 +1939,11 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List,
 		newHash,
 		newHeight,
 	}
-	b.chainLock.Unlock()
-	b.sendNotification(NTReorganization, reorgData)
-	b.chainLock.Lock()
+	if !b.quietNotifications {
+		b.chainLock.Unlock()
+		b.sendNotification(NTReorganization, reorgData)
+		b.chainLock.Lock()
+	}
 
 	// Reset the view for the actual connection code below.  This is
 	// required because the view was previously modified when checking if
@@ -1740,6 +2032,10 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List,
 	log.Infof("REORGANIZE: New best chain head is %v, height %v",
 		lastAttachNode.hash,
 		lastAttachNode.height)
+	logEvent("reorganize", "hash", lastAttachNode.hash.String(),
+		"height", lastAttachNode.height, "oldhash", formerBestHash.String(),
+		"oldheight", formerBestHeight, "forklength", attachNodes.Len(),
+		"detached", detachNodes.Len())
 
 	return nil
 }
@@ -1816,7 +2112,8 @@ func (b *BlockChain) forceHeadReorganization(formerBest chainhash.Hash, newBest
 		return err
 	}
 
-	err = checkBlockSanity(newBestBlock, b.timeSource, BFNone, b.chainParams)
+	err = checkBlockSanity(newBestBlock, b.timeSource, BFNone, b.chainParams,
+		nil)
 	if err != nil {
 		return err
 	}
@@ -1841,6 +2138,40 @@ func (b *BlockChain) ForceHeadReorganization(formerBest chainhash.Hash, newBest
 	return b.forceHeadReorganization(formerBest, newBest)
 }
 
+// removeSideChainNode removes the passed side chain node from the block index
+// and the side chain cache and disconnects it from its parent node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) removeSideChainNode(node *blockNode) {
+	node.parent.children = removeChildNode(node.parent.children, node)
+
+	delete(b.index, node.hash)
+	b.blockCacheLock.Lock()
+	delete(b.blockCache, node.hash)
+	b.blockCacheLock.Unlock()
+	b.bodyCache.remove(&node.hash)
+}
+
+// isBetterTip returns whether or not the passed node, which is the tip of a
+// side chain, should become the tip of the main chain in place of the current
+// best node.  This is the case when the cumulative work of the side chain is
+// greater than that of the main chain.
+//
+// When the cumulative work of both chains is the same, the current best node,
+// which was seen first, is kept.  When the chain is configured to prefer the
+// tip with the lowest hash, the tie is instead broken in favor of the tip with
+// the lowest block hash, treated as a 256-bit number, so the selected tip does
+// not depend on the order the tips were seen in.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isBetterTip(node *blockNode) bool {
+	cmp := node.workSum.Cmp(b.bestNode.workSum)
+	if cmp != 0 || !b.preferLowestHashTip {
+		return cmp > 0
+	}
+	return HashToBig(&node.hash).Cmp(HashToBig(&b.bestNode.hash)) < 0
+}
+
 // connectBestChain handles connecting the passed block to the chain while
 // respecting proper chain selection according to the chain with the most
 // proof of work.  In the typical case, the new block simply extends the main
@@ -1850,6 +2181,9 @@ func (b *BlockChain) ForceHeadReorganization(formerBest chainhash.Hash, newBest
 // ended up on the main chain (either due to extending the main chain or causing
 // a reorganization to become the main chain).
 //
+// Competing tips with the same cumulative proof of work are handled according
+// to isBetterTip.
+//
 // The flags modify the behavior of this function as follows:
 //  - BFFastAdd: Avoids several expensive transaction validation operations.
 //    This is useful when using checkpoints.
@@ -1886,11 +2220,6 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *dcrutil.Block, fla
 			}
 		}
 
-		// Don't connect the block if performing a dry run.
-		if dryRun {
-			return true, nil
-		}
-
 		// In the fast add case the code to check the block connection
 		// was skipped, so the utxo view needs to load the referenced
 		// utxos, spend them, and add the new utxos being created by
@@ -1906,6 +2235,15 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *dcrutil.Block, fla
 			}
 		}
 
+		// Don't connect the block if performing a dry run.  The
+		// resulting view and spent txouts are retained so the changes
+		// the block would make to the utxo set can be provided.
+		if dryRun {
+			b.dryRunView = view
+			b.dryRunStxos = stxos
+			return true, nil
+		}
+
 		// Connect the block to the main chain.
 		err = b.connectBlock(node, block, view, stxos)
 		if err != nil {
@@ -1954,21 +2292,13 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *dcrutil.Block, fla
 	// Remove the block from the side chain cache and disconnect it from the
 	// parent node when the function returns when running in dry run mode.
 	if dryRun {
-		defer func() {
-			children := node.parent.children
-			children = removeChildNode(children, node)
-			node.parent.children = children
-
-			delete(b.index, node.hash)
-			b.blockCacheLock.Lock()
-			delete(b.blockCache, node.hash)
-			b.blockCacheLock.Unlock()
-		}()
+		defer b.removeSideChainNode(node)
 	}
 
 	// We're extending (or creating) a side chain, but the cumulative
-	// work for this new side chain is not enough to make it the new chain.
-	if node.workSum.Cmp(b.bestNode.workSum) <= 0 {
+	// work for this new side chain is not enough to make it the new chain
+	// or it loses the tie-break against the current best chain.
+	if !b.isBetterTip(node) {
 		// Skip Logging info when the dry run flag is set.
 		if dryRun {
 			return false, nil
@@ -2004,13 +2334,18 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *dcrutil.Block, fla
 				fork.height,
 				fork.hash)
 		}
+		logEvent("side chain block", "hash", node.hash.String(),
+			"height", node.height, "forkhash", fork.hash.String(),
+			"forkheight", fork.height,
+			"forklength", node.height-fork.height)
 
 		return false, nil
 	}
 
 	// We're extending (or creating) a side chain and the cumulative work
-	// for this new side chain is more than the old best chain, so this side
-	// chain needs to become the main chain.  In order to accomplish that,
+	// for this new side chain is more than the old best chain, or the same
+	// and it wins the tie-break, so this side chain needs to become the
+	// main chain.  In order to accomplish that,
 	// find the common ancestor of both sides of the fork, disconnect the
 	// blocks that form the (now) old fork from the main chain, and attach
 	// the blocks that form the new chain to the main chain starting at the
@@ -2020,6 +2355,20 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *dcrutil.Block, fla
 		return false, err
 	}
 
+	// Reject the block when the reorganize would disconnect more blocks
+	// than allowed.  The block is removed from the side chain cache as
+	// well since it is rejected.  This is already handled for dry runs.
+	if b.maxReorgDepth > 0 && int64(detachNodes.Len()) > b.maxReorgDepth {
+		if !dryRun {
+			b.removeSideChainNode(node)
+		}
+		str := fmt.Sprintf("block %v would cause a reorganize which "+
+			"disconnects %d blocks from the main chain, exceeding the "+
+			"maximum reorganize depth of %d", node.hash,
+			detachNodes.Len(), b.maxReorgDepth)
+		return false, ruleError(ErrReorgTooDeep, str)
+	}
+
 	// Reorganize the chain.
 	if !dryRun {
 		log.Infof("REORGANIZE: Block %v is causing a reorganize.",
@@ -2083,11 +2432,58 @@ func (b *BlockChain) BestSnapshot() *BestState {
 	return snapshot
 }
 
+// BestHeader returns the hash and height of the header with the most
+// cumulative work known to the chain instance.
+//
+// The block index of this implementation only tracks headers for which the full
+// block has been processed and, once ProcessBlock finishes with a block, the
+// main chain is always the chain with the most cumulative work.  Consequently,
+// the best header is currently always the header of the best block as reported
+// by BestSnapshot.  Callers which need to know how far the headers extend, such
+// as sync progress estimates and decisions about which blocks to request next,
+// should nevertheless use this function instead of BestSnapshot so they
+// continue to work as intended should headers ever be tracked ahead of the
+// blocks that ProcessBlock has connected.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BestHeader() (chainhash.Hash, int64) {
+	b.chainLock.RLock()
+	hash, height := b.bestNode.hash, b.bestNode.height
+	b.chainLock.RUnlock()
+	return hash, height
+}
+
+// GenesisHash returns the hash of the genesis block of the network parameters
+// the chain instance was created with.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) GenesisHash() chainhash.Hash {
+	return *b.chainParams.GenesisHash
+}
+
 // MaximumBlockSize returns the maximum permitted block size for the block
-// AFTER the given node.
+// AFTER the given node.  This is the size permitted by the consensus rules
+// unless a smaller size has been configured via MaxBlockSizeOverride.
 //
 // This function MUST be called with the chain state lock held (for reads).
 func (b *BlockChain) maxBlockSize(prevNode *blockNode) (int64, error) {
+	maxSize, err := b.consensusMaxBlockSize(prevNode)
+	if err != nil {
+		return maxSize, err
+	}
+
+	// Restrict the size further when a smaller size has been configured.
+	if b.blockSizeLimit > 0 && b.blockSizeLimit < maxSize {
+		return b.blockSizeLimit, nil
+	}
+	return maxSize, nil
+}
+
+// consensusMaxBlockSize returns the maximum permitted block size for the block
+// AFTER the provided node according to the consensus rules.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) consensusMaxBlockSize(prevNode *blockNode) (int64, error) {
 	// Hard fork voting on block size is only enabled on testnet v1 and
 	// simnet.
 	if b.chainParams.Net != wire.SimNet {
@@ -2141,6 +2537,23 @@ type IndexManager interface {
 	DisconnectBlock(database.Tx, *dcrutil.Block, *dcrutil.Block, *UtxoViewpoint) error
 }
 
+// BlockProcessedHook is the signature of a callback which is invoked with the
+// hash and height of each block passed to ProcessBlock along with the amount of
+// time it took to process, whether or not it was accepted.
+//
+// The callback is invoked with the chain state lock held, so it must not call
+// back into the chain instance and should return quickly.
+type BlockProcessedHook func(hash *chainhash.Hash, height int64, elapsed time.Duration)
+
+// TxLocator provides the location of transactions in the main chain, such as
+// the transaction index provided by the indexers package.
+type TxLocator interface {
+	// TxBlockRegion returns the block region of the transaction with the
+	// provided hash in the main chain.  Both the region and the error
+	// must be nil when the transaction is not in the main chain.
+	TxBlockRegion(hash chainhash.Hash) (*database.BlockRegion, error)
+}
+
 // Config is a descriptor which specifies the blockchain instance configuration.
 type Config struct {
 	// DB defines the database which houses the blocks and will be used to
@@ -2169,24 +2582,181 @@ type Config struct {
 	// contents of notifications.
 	//
 	// This field can be nil if the caller is not interested in receiving
-	// notifications.
+	// notifications.  Additional callbacks may be registered after the
+	// chain instance is created via Subscribe.
 	Notifications NotificationCallback
 
+	// NotificationQueueSize enables asynchronous notification delivery when
+	// it is positive.  In that case, notifications are queued once the
+	// chain lock is released and delivered to subscribers, in order, by a
+	// dedicated goroutine so slow subscribers do not hold up processing of
+	// the chain.  It is the maximum number of notifications that may be
+	// queued.  Notifications are delivered synchronously when it is zero.
+	//
+	// Callers may use WaitForNotifications to wait for the queued
+	// notifications to be delivered.
+	NotificationQueueSize int
+
+	// DropNotificationsWhenFull specifies the policy when the asynchronous
+	// notification queue is full.  When it is set, new notifications are
+	// dropped and counted by DroppedNotifications.  Otherwise, processing
+	// of the chain blocks until there is room in the queue.
+	//
+	// NOTE: When blocking, notification callbacks must not process blocks
+	// since doing so might deadlock once the queue is full.
+	DropNotificationsWhenFull bool
+
 	// SigCache defines a signature cache to use when when validating
 	// signatures.  This is typically most useful when individual
 	// transactions are already being validated prior to their inclusion in
 	// a block such as what is usually done via a transaction memory pool.
 	//
 	// This field can be nil if the caller is not interested in using a
-	// signature cache.
+	// signature cache or would like the chain instance to create its own
+	// cache as specified by SigCacheSize.
 	SigCache *txscript.SigCache
 
+	// SigCacheSize defines the maximum number of entries in the signature
+	// cache created by the chain instance when SigCache is nil.  It is
+	// ignored when SigCache is provided, since the size of a provided cache
+	// is already set.  Callers which validate transactions prior to their
+	// inclusion in a block should typically provide a cache shared with
+	// that validation instead so the entries are reused.
+	//
+	// This field can be zero to not use a signature cache when SigCache is
+	// nil.
+	SigCacheSize uint
+
+	// QuietProcessBlockLogging suppresses the per-block debug and trace
+	// lines written by ProcessBlock independently of the level of the
+	// logger.  The time it takes to process each block is still measured
+	// and provided to BlockProcessedHook, so callers which are interested
+	// in it, such as metrics collectors, may use the hook instead.
+	QuietProcessBlockLogging bool
+
+	// BlockProcessedHook defines a callback which is invoked each time
+	// ProcessBlock finishes processing a block.  See the BlockProcessedHook
+	// type for details.
+	//
+	// This field can be nil if the caller is not interested in it.
+	BlockProcessedHook BlockProcessedHook
+
+	// TxLocator defines the transaction locator, which is typically the
+	// transaction index, used to look up which block contains a
+	// transaction via BlockByTx and TxConfirmations.
+	//
+	// This field can be nil if the caller does not maintain a transaction
+	// index, in which case BlockByTx always returns an error and
+	// TxConfirmations only locates transactions with unspent outputs.
+	TxLocator TxLocator
+
 	// IndexManager defines an index manager to use when initializing the
 	// chain and connecting and disconnecting blocks.
 	//
 	// This field can be nil if the caller does not wish to make use of an
 	// index manager.
 	IndexManager IndexManager
+
+	// PreAcceptFilter defines a function which is invoked by ProcessBlock
+	// with every block that has passed the context-free sanity checks, but
+	// before it is accepted into the block chain or added to the orphan
+	// pool.  It allows a local policy to be layered on top of the consensus
+	// rules.  A non-nil error rejects the block.  Errors which are not
+	// already a RuleError are returned as a RuleError with the
+	// ErrRejectedByPolicy error code.
+	//
+	// The function is invoked with the chain lock held, so it MUST NOT call
+	// back into the chain instance.
+	//
+	// This field can be nil if the caller does not wish to apply any
+	// additional policy.
+	PreAcceptFilter func(block *dcrutil.Block) error
+
+	// ScriptValidationWorkers defines the maximum number of goroutines used
+	// to validate the scripts of the transactions in a block in parallel.
+	//
+	// This field can be zero to use a default based on the number of
+	// processors usable by the runtime.
+	ScriptValidationWorkers int
+
+	// ValidatedBlockCacheSize defines the maximum number of hashes of
+	// blocks known to have valid scripts that are kept in memory in order
+	// to avoid validating the scripts of those blocks again when they are
+	// reconnected, such as during reorganizations.
+	//
+	// This field can be zero to use a default size or negative to disable
+	// the cache.
+	ValidatedBlockCacheSize int
+
+	// PreferLowestHashTip enables a deterministic tie-break between
+	// competing chain tips which have the same cumulative work.  When it
+	// is set, the tip with the lowest block hash, treated as a 256-bit
+	// number, is selected so that all nodes which know about the same tips
+	// converge on the same one regardless of the order they received them
+	// in.  Otherwise, the tip which was seen first remains the tip of the
+	// main chain.
+	PreferLowestHashTip bool
+
+	// MaxReorgDepth defines the maximum number of blocks a reorganize may
+	// disconnect from the main chain.  A block which would cause a deeper
+	// reorganize is rejected with a RuleError with the ErrReorgTooDeep
+	// error code instead.  This provides a finality safeguard against
+	// deep reorganizes, whether accidental or malicious.
+	//
+	// This field can be zero to allow reorganizes of any depth.
+	MaxReorgDepth int64
+
+	// ProcessingLatencyWindow defines the number of the most recent block
+	// processing times which are kept in memory in order to calculate the
+	// percentiles returned by ProcessingLatencyStats.
+	//
+	// This field can be zero to use a default size.
+	ProcessingLatencyWindow int
+
+	// BlockCacheSize defines the maximum total serialized size in bytes of
+	// the recently accessed blocks which are kept in memory in order to
+	// reduce database fetches of the same blocks, such as those which
+	// happen during reorganizes and while serving RPC clients.  Blocks are
+	// added to the cache when they are accepted and when they are loaded
+	// from the database.  The hit rate is available via BlockCacheHitRate.
+	//
+	// This field can be zero to disable the cache.
+	BlockCacheSize uint64
+
+	// RuleOverrides defines functions which override the result of
+	// specific consensus rule checks, keyed by the error code of the
+	// RuleError the check results in when it fails.  See RuleOverrideFunc
+	// for details.  It is intended to allow experimenting with changes to
+	// the consensus rules, such as relaxing a particular stake rule, on
+	// test networks.
+	//
+	// The overrides are only consulted by ProcessBlock for the context-free
+	// sanity checks and the checks which depend on the position of a block
+	// within the block chain.  They are consulted for each individual
+	// check, so accepting a failed check via an override does not skip any
+	// of the remaining checks.  The check which ensures a block contains at
+	// least one regular transaction and the checks performed against the
+	// utxo set while connecting a block can't be overridden.
+	//
+	// WARNING: Overriding consensus rules causes the chain instance to
+	// accept blocks the rest of the network rejects and is therefore
+	// dangerous.  It must only be used for testing and research purposes.
+	// New returns an error when any overrides are provided for the main
+	// network.
+	//
+	// This field can be nil to enforce all consensus rules.
+	RuleOverrides map[ErrorCode]RuleOverrideFunc
+
+	// MaxBlockSizeOverride restricts the maximum serialized size of the
+	// blocks the chain instance accepts to a value smaller than the one
+	// permitted by the consensus rules.  It is intended to allow simulating
+	// networks with smaller blocks on test networks.  Values larger than the
+	// maximum size permitted by the consensus rules have no effect.  New
+	// returns an error when it is set for the main network.
+	//
+	// This field can be zero to use the maximum size permitted by the
+	// consensus rules.
+	MaxBlockSizeOverride int64
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -2198,6 +2768,18 @@ func New(config *Config) (*BlockChain, error) {
 	if config.ChainParams == nil {
 		return nil, AssertError("blockchain.New chain parameters nil")
 	}
+	if len(config.RuleOverrides) > 0 && config.ChainParams.Net == wire.MainNet {
+		return nil, AssertError("blockchain.New rule overrides are not " +
+			"allowed on the main network")
+	}
+	if config.MaxBlockSizeOverride < 0 {
+		return nil, AssertError("blockchain.New max block size override " +
+			"is negative")
+	}
+	if config.MaxBlockSizeOverride > 0 && config.ChainParams.Net == wire.MainNet {
+		return nil, AssertError("blockchain.New max block size override " +
+			"is not allowed on the main network")
+	}
 
 	// Generate a checkpoint by height map from the provided checkpoints.
 	params := config.ChainParams
@@ -2210,22 +2792,50 @@ func New(config *Config) (*BlockChain, error) {
 		}
 	}
 
+	validatedBlockCacheSize := config.ValidatedBlockCacheSize
+	if validatedBlockCacheSize == 0 {
+		validatedBlockCacheSize = defaultValidatedBlockCacheSize
+	} else if validatedBlockCacheSize < 0 {
+		validatedBlockCacheSize = 0
+	}
+
+	processingLatencyWindow := config.ProcessingLatencyWindow
+	if processingLatencyWindow <= 0 {
+		processingLatencyWindow = defaultProcessingLatencyWindow
+	}
+
+	sigCache := config.SigCache
+	if sigCache == nil && config.SigCacheSize > 0 {
+		sigCache = txscript.NewSigCache(config.SigCacheSize)
+	}
+
 	b := BlockChain{
 		checkpointsByHeight:           checkpointsByHeight,
 		db:                            config.DB,
 		chainParams:                   params,
 		timeSource:                    config.TimeSource,
-		notifications:                 config.Notifications,
-		sigCache:                      config.SigCache,
+		sigCache:                      sigCache,
 		indexManager:                  config.IndexManager,
+		txLocator:                     config.TxLocator,
+		quietProcessLog:               config.QuietProcessBlockLogging,
+		blockProcessedHook:            config.BlockProcessedHook,
+		preAcceptFilter:               config.PreAcceptFilter,
+		ruleOverrides:                 config.RuleOverrides,
+		blockSizeLimit:                config.MaxBlockSizeOverride,
+		scriptValWorkers:              config.ScriptValidationWorkers,
+		preferLowestHashTip:           config.PreferLowestHashTip,
+		maxReorgDepth:                 config.MaxReorgDepth,
 		bestNode:                      nil,
 		index:                         make(map[chainhash.Hash]*blockNode),
 		depNodes:                      make(map[chainhash.Hash][]*blockNode),
+		validatedBlocks:               newValidatedBlockCache(validatedBlockCacheSize),
+		processLatencies:              newLatencyWindow(processingLatencyWindow),
 		orphans:                       make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:                   make(map[chainhash.Hash][]*orphanBlock),
 		blockCache:                    make(map[chainhash.Hash]*dcrutil.Block),
 		mainchainBlockCache:           make(map[chainhash.Hash]*dcrutil.Block),
 		mainchainBlockCacheSize:       mainchainBlockCacheSize,
+		bodyCache:                     newBlockBodyCache(config.BlockCacheSize),
 		deploymentCaches:              newThresholdCaches(params),
 		isVoterMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
 		isStakeMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
@@ -2233,6 +2843,14 @@ func New(config *Config) (*BlockChain, error) {
 		calcVoterVersionIntervalCache: make(map[[chainhash.HashSize]byte]uint32),
 		calcStakeVersionCache:         make(map[[chainhash.HashSize]byte]uint32),
 	}
+	if config.NotificationQueueSize > 0 {
+		b.notificationQueue = newNotificationQueue(
+			config.NotificationQueueSize,
+			config.DropNotificationsWhenFull)
+	}
+	if config.Notifications != nil {
+		b.Subscribe(config.Notifications)
+	}
 
 	// Initialize the chain state from the passed database.  When the db
 	// does not yet contain any chain state, both it and the chain state
@@ -9,13 +9,24 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btclog"
 	"github.com/decred/dcrd/blockchain"
+	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
+	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
 )
 
@@ -129,3 +140,1887 @@ func TestBlockchainFunctions(t *testing.T) {
 			totalSubsidy)
 	}
 }
+
+// legacyTestParams returns a copy of the simnet parameters updated to reflect
+// what is expected by the legacy test data.
+func legacyTestParams() *chaincfg.Params {
+	params := cloneParams(&chaincfg.SimNetParams)
+	params.GenesisBlock.Header.MerkleRoot = *mustParseHash("a216ea043f0d481a072424af646787794c32bcefd3ed181a090319bbf8a37105")
+	genesisHash := params.GenesisBlock.BlockHash()
+	params.GenesisHash = &genesisHash
+	return params
+}
+
+// processTestBlocks processes the test blocks from the start height through the
+// end height, inclusive, with the passed chain instance.
+func processTestBlocks(chain *blockchain.BlockChain, blocks map[int64][]byte, start, end int64) error {
+	for height := start; height <= end; height++ {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			return fmt.Errorf("NewBlockFromBytes error at height %v: %v",
+				height, err)
+		}
+		_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+		if err != nil {
+			return fmt.Errorf("ProcessBlock error at height %v: %v",
+				height, err)
+		}
+	}
+	return nil
+}
+
+// legacyChainSetup creates a new database and chain instance with the passed
+// legacy parameters and processes the legacy test blocks through the provided
+// height.  The test blocks are returned so callers are able to process the
+// remaining ones.  The returned teardown function must be called when the
+// caller is done with the chain instance.
+func legacyChainSetup(dbName string, params *chaincfg.Params, height int64) (*blockchain.BlockChain, map[int64][]byte, func(), error) {
+	return legacyChainSetupWithConfig(dbName, params, blockchain.Config{},
+		height)
+}
+
+// legacyChainSetupWithConfig is the same as legacyChainSetup except the chain
+// instance is created with the passed configuration as described by
+// chainSetupWithConfig.
+func legacyChainSetupWithConfig(dbName string, params *chaincfg.Params, config blockchain.Config, height int64) (*blockchain.BlockChain, map[int64][]byte, func(), error) {
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	chain, teardownFunc, err := chainSetupWithConfig(dbName, params, config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := processTestBlocks(chain, blocks, 1, height); err != nil {
+		teardownFunc()
+		return nil, nil, nil, err
+	}
+	return chain, blocks, teardownFunc, nil
+}
+
+// legacyTipAndParent returns the final two legacy test blocks.
+func legacyTipAndParent(blocks map[int64][]byte) (*dcrutil.Block, *dcrutil.Block, error) {
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		return nil, nil, err
+	}
+	parent, err := dcrutil.NewBlockFromBytes(blocks[167])
+	if err != nil {
+		return nil, nil, err
+	}
+	return tip, parent, nil
+}
+
+// spentTestInputs returns the inputs which spend outputs when the passed tip
+// block is connected to the passed parent in the order they are spent.
+func spentTestInputs(tip, parent *dcrutil.Block) []*wire.TxIn {
+	var spentInputs []*wire.TxIn
+	if dcrutil.IsFlagSet16(tip.MsgBlock().Header.VoteBits,
+		dcrutil.BlockValid) {
+		for _, tx := range parent.MsgBlock().Transactions[1:] {
+			spentInputs = append(spentInputs, tx.TxIn...)
+		}
+	}
+	for _, tx := range tip.MsgBlock().STransactions {
+		txIns := tx.TxIn
+		if stake.DetermineTxType(tx) == stake.TxTypeSSGen {
+			txIns = txIns[1:]
+		}
+		spentInputs = append(spentInputs, txIns...)
+	}
+	return spentInputs
+}
+
+// TestCalcNextRequiredStakeDifficulty ensures the next required stake
+// difficulty calculated from the current tip matches the one committed to by
+// the next block.
+func TestCalcNextRequiredStakeDifficulty(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup("nextsdiffunittests",
+		params, 0)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	for i := int64(1); i <= 168; i++ {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[i])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+
+		nextSDiff, err := chain.CalcNextRequiredStakeDifficulty()
+		if err != nil {
+			t.Fatalf("CalcNextRequiredStakeDifficulty error at height "+
+				"%v: %v", i, err)
+		}
+		if nextSDiff != bl.MsgBlock().Header.SBits {
+			t.Errorf("CalcNextRequiredStakeDifficulty at height %v: "+
+				"got %v, want %v", i, nextSDiff,
+				bl.MsgBlock().Header.SBits)
+		}
+
+		_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock error at height %v: %v", i, err)
+		}
+	}
+}
+
+// TestCalcTxFee ensures the fees of the stake transactions in the final test
+// block calculated from the utxo set match the fees implied by the input
+// amounts committed to by the transactions.
+func TestCalcTxFee(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup("calctxfeeunittests",
+		params, 167)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	bl, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	for _, stx := range bl.STransactions() {
+		view, err := chain.FetchUtxoView(stx, true)
+		if err != nil {
+			t.Fatalf("FetchUtxoView: unexpected error: %v", err)
+		}
+		fee, err := blockchain.CalcTxFee(stx, view)
+		if err != nil {
+			t.Fatalf("CalcTxFee: unexpected error for tx %v: %v",
+				stx.Hash(), err)
+		}
+		var wantFee int64
+		for _, txIn := range stx.MsgTx().TxIn {
+			wantFee += txIn.ValueIn
+		}
+		for _, txOut := range stx.MsgTx().TxOut {
+			wantFee -= txOut.Value
+		}
+		if fee != wantFee {
+			t.Fatalf("CalcTxFee: unexpected fee for tx %v - got %v, "+
+				"want %v", stx.Hash(), fee, wantFee)
+		}
+	}
+
+	// Ensure the coinbase pays no fee and a missing input is detected.
+	fee, err := blockchain.CalcTxFee(bl.Transactions()[0],
+		blockchain.NewUtxoViewpoint())
+	if err != nil || fee != 0 {
+		t.Fatalf("CalcTxFee: unexpected result for coinbase - got %v, "+
+			"%v, want 0, nil", fee, err)
+	}
+	_, err = blockchain.CalcTxFee(bl.STransactions()[0],
+		blockchain.NewUtxoViewpoint())
+	if rerr, ok := err.(blockchain.RuleError); !ok ||
+		rerr.ErrorCode != blockchain.ErrMissingTx {
+		t.Fatalf("CalcTxFee: unexpected error for missing input - got "+
+			"%v, want %v", err, blockchain.ErrMissingTx)
+	}
+}
+
+// TestFetchSpendJournal ensures the spend journal for the tip block contains an
+// entry for every spent output in the expected order.
+func TestFetchSpendJournal(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"spendjournalunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	stxos, err := chain.FetchSpendJournal(tip)
+	if err != nil {
+		t.Fatalf("FetchSpendJournal: unexpected error: %v", err)
+	}
+	spentInputs := spentTestInputs(tip, parent)
+	if len(stxos) != len(spentInputs) {
+		t.Fatalf("FetchSpendJournal: unexpected number of entries - "+
+			"got %d, want %d", len(stxos), len(spentInputs))
+	}
+	for i, txIn := range spentInputs {
+		if stxos[i].Amount != txIn.ValueIn ||
+			stxos[i].Height != txIn.BlockHeight ||
+			stxos[i].Index != txIn.BlockIndex {
+			t.Fatalf("FetchSpendJournal: entry %d does not match "+
+				"input %v", i, txIn.PreviousOutPoint)
+		}
+	}
+}
+
+// dryRunTestTip performs a dry run of the final legacy test block with the
+// passed chain instance, which must have the test blocks processed through its
+// parent, and then processes it.  The utxo diff from the dry run is returned.
+func dryRunTestTip(chain *blockchain.BlockChain, tip *dcrutil.Block) (*blockchain.UtxoDiff, error) {
+	diff, err := chain.ProcessBlockDryRun(tip, blockchain.BFNone)
+	if err != nil {
+		return nil, fmt.Errorf("ProcessBlockDryRun: unexpected error: %v",
+			err)
+	}
+	if diff == nil {
+		return nil, fmt.Errorf("ProcessBlockDryRun: unexpected nil diff")
+	}
+	if height := chain.BestSnapshot().Height; height != tip.Height()-1 {
+		return nil, fmt.Errorf("ProcessBlockDryRun: best height %d "+
+			"after dry run, want %d", height, tip.Height()-1)
+	}
+	if _, _, err := chain.ProcessBlock(tip, blockchain.BFNone); err != nil {
+		return nil, fmt.Errorf("ProcessBlock error: %v", err)
+	}
+	return diff, nil
+}
+
+// TestProcessBlockDryRun ensures the utxo diff from a dry run of the tip block
+// matches what was actually spent and created when the block was connected.
+func TestProcessBlockDryRun(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup("dryrununittests",
+		params, 167)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	dryRunDiff, err := dryRunTestTip(chain, tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stxos, err := chain.FetchSpendJournal(tip)
+	if err != nil {
+		t.Fatalf("FetchSpendJournal: unexpected error: %v", err)
+	}
+	spentInputs := spentTestInputs(tip, parent)
+	if len(dryRunDiff.Spent) != len(stxos) {
+		t.Fatalf("ProcessBlockDryRun: unexpected number of spent "+
+			"outputs - got %d, want %d", len(dryRunDiff.Spent),
+			len(stxos))
+	}
+	for i, spent := range dryRunDiff.Spent {
+		if spent.OutPoint != spentInputs[i].PreviousOutPoint ||
+			spent.Amount != stxos[i].Amount ||
+			!bytes.Equal(spent.PkScript, stxos[i].PkScript) {
+			t.Fatalf("ProcessBlockDryRun: spent output %d does not "+
+				"match input %v", i, spentInputs[i].PreviousOutPoint)
+		}
+	}
+	if len(dryRunDiff.Created) == 0 {
+		t.Fatal("ProcessBlockDryRun: no created outputs")
+	}
+	for _, created := range dryRunDiff.Created {
+		entry, err := chain.FetchUtxoEntry(&created.OutPoint.Hash)
+		if err != nil {
+			t.Fatalf("FetchUtxoEntry: unexpected error: %v", err)
+		}
+		if entry == nil || entry.IsOutputSpent(created.OutPoint.Index) ||
+			entry.AmountByIndex(created.OutPoint.Index) != created.Amount {
+			t.Fatalf("ProcessBlockDryRun: created output %v is not "+
+				"in the utxo set", created.OutPoint)
+		}
+	}
+}
+
+// TestBlockNewOutputs ensures the new outputs of the tip block consist of the
+// outputs its dry run added to the utxo set, in the same order, along with the
+// ones which were spent by the same set of transactions.
+func TestBlockNewOutputs(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"newoutputsunittests", params, 167)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	dryRunDiff, err := dryRunTestTip(chain, tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newOutputs, err := chain.BlockNewOutputs(tip)
+	if err != nil {
+		t.Fatalf("BlockNewOutputs: unexpected error: %v", err)
+	}
+	spentInBlock := make(map[wire.OutPoint]struct{})
+	for _, spent := range dryRunDiff.Spent {
+		spentInBlock[spent.OutPoint] = struct{}{}
+	}
+	var createdIdx int
+	for _, output := range newOutputs {
+		if createdIdx < len(dryRunDiff.Created) &&
+			output.OutPoint == dryRunDiff.Created[createdIdx].OutPoint {
+
+			created := dryRunDiff.Created[createdIdx]
+			if output.Amount != created.Amount ||
+				output.ScriptVersion != created.ScriptVersion ||
+				!bytes.Equal(output.PkScript, created.PkScript) {
+				t.Fatalf("BlockNewOutputs: output %v does not "+
+					"match the created output", output.OutPoint)
+			}
+			createdIdx++
+			continue
+		}
+		if _, ok := spentInBlock[output.OutPoint]; !ok {
+			t.Fatalf("BlockNewOutputs: unexpected output %v",
+				output.OutPoint)
+		}
+	}
+	if createdIdx != len(dryRunDiff.Created) {
+		t.Fatalf("BlockNewOutputs: missing created output %v",
+			dryRunDiff.Created[createdIdx].OutPoint)
+	}
+}
+
+// TestFilterKnownBlocks ensures only the unknown hashes are returned when
+// filtering a list of hashes for known blocks and they retain their order.
+func TestFilterKnownBlocks(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"filterknownunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	unknownHash1 := chainhash.Hash{0x01}
+	unknownHash2 := chainhash.Hash{0x02}
+	filterHashes := []chainhash.Hash{unknownHash2, *tip.Hash(),
+		*params.GenesisHash, unknownHash1, *parent.Hash()}
+	unknownHashes := chain.FilterKnownBlocks(filterHashes)
+	wantUnknown := []chainhash.Hash{unknownHash2, unknownHash1}
+	if !reflect.DeepEqual(unknownHashes, wantUnknown) {
+		t.Fatalf("FilterKnownBlocks: unexpected hashes - got %v, want %v",
+			unknownHashes, wantUnknown)
+	}
+}
+
+// TestBlockSize ensures the stored size of a block is its serialized size and
+// an unknown block is rejected.
+func TestBlockSize(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"blocksizeunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tipSize, err := chain.BlockSize(tip.Hash())
+	if err != nil {
+		t.Fatalf("BlockSize: unexpected error: %v", err)
+	}
+	if wantSize := tip.MsgBlock().SerializeSize(); tipSize != wantSize {
+		t.Fatalf("BlockSize: unexpected size - got %d, want %d",
+			tipSize, wantSize)
+	}
+	_, err = chain.BlockSize(&chainhash.Hash{0x01})
+	if dbErr, ok := err.(database.Error); !ok ||
+		dbErr.ErrorCode != database.ErrBlockNotFound {
+		t.Fatalf("BlockSize: unexpected error for unknown block: %v",
+			err)
+	}
+}
+
+// TestBestHeader ensures the best header is the header of the best block when
+// all of the known headers have had their blocks processed.
+func TestBestHeader(t *testing.T) {
+	params := legacyTestParams()
+	chain, _, teardownFunc, err := legacyChainSetup("bestheaderunittests",
+		params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	bestHeaderHash, bestHeaderHeight := chain.BestHeader()
+	best := chain.BestSnapshot()
+	if bestHeaderHash != *best.Hash || bestHeaderHeight != best.Height {
+		t.Fatalf("BestHeader: unexpected best header - got %v (height "+
+			"%d), want %v (height %d)", bestHeaderHash,
+			bestHeaderHeight, best.Hash, best.Height)
+	}
+}
+
+// TestGenesisHash ensures the genesis hash is the one from the parameters the
+// chain was created with and matches the main chain block at height 0.
+func TestGenesisHash(t *testing.T) {
+	params := legacyTestParams()
+	chain, _, teardownFunc, err := legacyChainSetup("genesishashunittests",
+		params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	genesis := chain.GenesisHash()
+	if genesis != *params.GenesisHash {
+		t.Fatalf("GenesisHash: unexpected hash - got %v, want %v",
+			genesis, params.GenesisHash)
+	}
+	heightZeroHash, err := chain.BlockHashByHeight(0)
+	if err != nil {
+		t.Fatalf("BlockHashByHeight: unexpected error: %v", err)
+	}
+	if genesis != *heightZeroHash {
+		t.Fatalf("GenesisHash: hash %v does not match block %v at "+
+			"height 0", genesis, heightZeroHash)
+	}
+}
+
+// TestChainNextBlockVersion ensures the next block version of a chain instance
+// is the newest version which a majority of the recent blocks in the chain
+// have upgraded to.
+func TestChainNextBlockVersion(t *testing.T) {
+	params := legacyTestParams()
+	chain, _, teardownFunc, err := legacyChainSetup(
+		"nextblockversionunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	var versions []int32
+	for height := chain.BestSnapshot().Height; height > 0 &&
+		int64(len(versions)) < int64(params.BlockUpgradeNumToCheck); height-- {
+
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockByHeight: unexpected error: %v", err)
+		}
+		versions = append(versions, block.MsgBlock().Header.Version)
+	}
+	wantVersion := int32(1)
+	for version := int32(6); version > 1; version-- {
+		var numFound uint64
+		for _, v := range versions {
+			if v >= version {
+				numFound++
+			}
+		}
+		if numFound >= params.BlockRejectNumRequired {
+			wantVersion = version
+			break
+		}
+	}
+	if version := chain.CalcNextBlockVersion(); version != wantVersion {
+		t.Fatalf("CalcNextBlockVersion: unexpected version - got %d, "+
+			"want %d", version, wantVersion)
+	}
+}
+
+// TestProcessingLatencyStats ensures the processing latency stats account for
+// every processed block.
+func TestProcessingLatencyStats(t *testing.T) {
+	params := legacyTestParams()
+	chain, _, teardownFunc, err := legacyChainSetup("latencyunittests",
+		params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	latencyStats := chain.ProcessingLatencyStats()
+	if latencyStats.Samples != 168 {
+		t.Fatalf("ProcessingLatencyStats: unexpected number of samples "+
+			"- got %d, want %d", latencyStats.Samples, 168)
+	}
+	if latencyStats.P50 > latencyStats.P90 ||
+		latencyStats.P90 > latencyStats.P99 {
+		t.Fatalf("ProcessingLatencyStats: percentiles are not ordered "+
+			"- got %+v", latencyStats)
+	}
+}
+
+// TestDifficultyAtHeight ensures the difficulty at a height is that of the
+// main chain block at the height and heights beyond the tip are rejected.
+func TestDifficultyAtHeight(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"difficultyunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	bits, difficulty, err := chain.DifficultyAtHeight(parent.Height())
+	if err != nil {
+		t.Fatalf("DifficultyAtHeight: unexpected error: %v", err)
+	}
+	if wantBits := parent.MsgBlock().Header.Bits; bits != wantBits {
+		t.Fatalf("DifficultyAtHeight: unexpected bits - got %08x, "+
+			"want %08x", bits, wantBits)
+	}
+	maxTarget := blockchain.CompactToBig(params.PowLimitBits)
+	target := blockchain.CompactToBig(bits)
+	wantDifficulty, _ := new(big.Rat).SetFrac(maxTarget, target).Float64()
+	if difficulty != wantDifficulty {
+		t.Fatalf("DifficultyAtHeight: unexpected difficulty - got %v, "+
+			"want %v", difficulty, wantDifficulty)
+	}
+	_, _, err = chain.DifficultyAtHeight(tip.Height() + 1)
+	if err == nil {
+		t.Fatal("DifficultyAtHeight: did not receive expected error " +
+			"for height beyond the tip")
+	}
+}
+
+// TestMinimumWorkCheckpoint ensures work checkpoints identify the main chain
+// block at the requested height, commit to the cumulative work of the chain,
+// and survive a JSON round trip.
+func TestMinimumWorkCheckpoint(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"workcheckpointunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tipCheckpoint, err := chain.MinimumWorkCheckpoint(tip.Height())
+	if err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+	}
+	parentCheckpoint, err := chain.MinimumWorkCheckpoint(parent.Height())
+	if err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+	}
+	if parentCheckpoint.Hash != *parent.Hash() {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected hash - got %v, "+
+			"want %v", parentCheckpoint.Hash, parent.Hash())
+	}
+	wantWork := blockchain.CalcWork(tip.MsgBlock().Header.Bits)
+	wantWork.Add(wantWork, parentCheckpoint.CumulativeWork)
+	if tipCheckpoint.CumulativeWork.Cmp(wantWork) != 0 {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected cumulative work - "+
+			"got %v, want %v", tipCheckpoint.CumulativeWork, wantWork)
+	}
+	checkpointJSON, err := json.Marshal(tipCheckpoint)
+	if err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected marshal error: %v",
+			err)
+	}
+	var decodedCheckpoint blockchain.WorkCheckpoint
+	if err := json.Unmarshal(checkpointJSON, &decodedCheckpoint); err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected unmarshal error: %v",
+			err)
+	}
+	if decodedCheckpoint.Height != tipCheckpoint.Height ||
+		decodedCheckpoint.Hash != tipCheckpoint.Hash ||
+		decodedCheckpoint.CumulativeWork.Cmp(wantWork) != 0 {
+		t.Fatalf("MinimumWorkCheckpoint: mismatched checkpoint after "+
+			"JSON round trip - got %s", checkpointJSON)
+	}
+	if _, err := chain.MinimumWorkCheckpoint(tip.Height() + 1); err == nil {
+		t.Fatal("MinimumWorkCheckpoint: did not receive expected error " +
+			"for height after the tip")
+	}
+}
+
+// TestWorkToReorg ensures the work to reorg is the work the main chain contains
+// after the fork point and unknown fork points are rejected.
+func TestWorkToReorg(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"worktoreorgunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tipCheckpoint, err := chain.MinimumWorkCheckpoint(tip.Height())
+	if err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+	}
+	genesisCheckpoint, err := chain.MinimumWorkCheckpoint(0)
+	if err != nil {
+		t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+	}
+	reorgTests := []struct {
+		name      string
+		forkPoint *chainhash.Hash
+		work      *big.Int
+	}{
+		{"tip", tip.Hash(), big.NewInt(0)},
+		{"parent of tip", parent.Hash(),
+			blockchain.CalcWork(tip.MsgBlock().Header.Bits)},
+		{"genesis", params.GenesisHash, new(big.Int).Sub(
+			tipCheckpoint.CumulativeWork,
+			genesisCheckpoint.CumulativeWork)},
+	}
+	for i, test := range reorgTests {
+		work, err := chain.WorkToReorg(test.forkPoint)
+		if err != nil {
+			t.Fatalf("Test #%d (%s) WorkToReorg: unexpected error: %v",
+				i, test.name, err)
+		}
+		if work.Cmp(test.work) != 0 {
+			t.Fatalf("Test #%d (%s) WorkToReorg: unexpected work - "+
+				"got %v, want %v", i, test.name, work, test.work)
+		}
+	}
+	if _, err := chain.WorkToReorg(&chainhash.Hash{0x01}); err == nil {
+		t.Fatal("WorkToReorg: did not receive expected error for " +
+			"unknown fork point")
+	}
+}
+
+// TestEstimateStakeDifficulty ensures stake difficulty estimates based on a
+// ticket purchase rate project the tickets purchased in the remaining blocks of
+// the current interval from the rate and invalid rates are rejected.
+func TestEstimateStakeDifficulty(t *testing.T) {
+	params := legacyTestParams()
+	chain, _, teardownFunc, err := legacyChainSetup(
+		"estimatesdiffunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	windowSize := params.StakeDiffWindowSize
+	tipHeight := chain.BestSnapshot().Height
+	remainingBlocks := windowSize - tipHeight%windowSize - 1
+	maxRate := windowSize * int64(params.MaxFreshStakePerBlock)
+	for _, rate := range []int64{0, maxRate / 2, maxRate} {
+		gotDiff, err := chain.EstimateStakeDifficulty(rate)
+		if err != nil {
+			t.Fatalf("EstimateStakeDifficulty(%d): unexpected error: %v",
+				rate, err)
+		}
+		wantDiff, err := chain.EstimateNextStakeDifficulty(
+			rate*remainingBlocks/windowSize, false)
+		if err != nil {
+			t.Fatalf("EstimateNextStakeDifficulty: unexpected error: %v",
+				err)
+		}
+		if gotDiff != wantDiff {
+			t.Fatalf("EstimateStakeDifficulty(%d): unexpected "+
+				"difficulty - got %d, want %d", rate, gotDiff,
+				wantDiff)
+		}
+	}
+	maxDiff, err := chain.EstimateNextStakeDifficulty(0, true)
+	if err != nil {
+		t.Fatalf("EstimateNextStakeDifficulty: unexpected error: %v", err)
+	}
+	gotMaxDiff, err := chain.EstimateStakeDifficulty(maxRate)
+	if err != nil {
+		t.Fatalf("EstimateStakeDifficulty: unexpected error: %v", err)
+	}
+	if gotMaxDiff > maxDiff {
+		t.Fatalf("EstimateStakeDifficulty: estimate for the maximum rate "+
+			"%d exceeds the maximum estimate %d", gotMaxDiff, maxDiff)
+	}
+	for _, rate := range []int64{-1, maxRate + 1} {
+		if _, err := chain.EstimateStakeDifficulty(rate); err == nil {
+			t.Fatalf("EstimateStakeDifficulty: did not receive "+
+				"expected error for rate %d", rate)
+		}
+	}
+}
+
+// TestBlocksByHashes ensures fetching multiple blocks returns them in the
+// requested order and reports missing blocks via nil entries and the returned
+// error.
+func TestBlocksByHashes(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"blocksbyhashesunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	unknownHash := chainhash.Hash{0x01}
+	batchHashes := []chainhash.Hash{*tip.Hash(), unknownHash,
+		*parent.Hash(), *params.GenesisHash}
+	batchBlocks, err := chain.BlocksByHashes(batchHashes)
+	missingErr, ok := err.(blockchain.MissingBlocksError)
+	if !ok || !reflect.DeepEqual(missingErr,
+		blockchain.MissingBlocksError{unknownHash}) {
+		t.Fatalf("BlocksByHashes: unexpected error - got %v, want "+
+			"missing %v", err, unknownHash)
+	}
+	if len(batchBlocks) != len(batchHashes) {
+		t.Fatalf("BlocksByHashes: unexpected number of blocks - got "+
+			"%d, want %d", len(batchBlocks), len(batchHashes))
+	}
+	for i, hash := range batchHashes {
+		if hash == unknownHash {
+			if batchBlocks[i] != nil {
+				t.Fatalf("BlocksByHashes: unexpected block %v for "+
+					"missing hash at index %d",
+					batchBlocks[i].Hash(), i)
+			}
+			continue
+		}
+		if batchBlocks[i] == nil || *batchBlocks[i].Hash() != hash {
+			t.Fatalf("BlocksByHashes: unexpected block at index %d - "+
+				"want %v", i, hash)
+		}
+	}
+	batchBlocks, err = chain.BlocksByHashes(batchHashes[2:])
+	if err != nil {
+		t.Fatalf("BlocksByHashes: unexpected error: %v", err)
+	}
+	if len(batchBlocks) != 2 || *batchBlocks[0].Hash() != *parent.Hash() ||
+		*batchBlocks[1].Hash() != *params.GenesisHash {
+		t.Fatal("BlocksByHashes: unexpected blocks when all are found")
+	}
+}
+
+// TestStakePoolSizes ensures the stake pool sizes are reported from the best
+// block backwards and the immature tickets are those purchased within the
+// ticket maturity.
+func TestStakePoolSizes(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"poolsizesunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	poolSizes, err := chain.StakePoolSizes(3)
+	if err != nil {
+		t.Fatalf("StakePoolSizes: unexpected error: %v", err)
+	}
+	if len(poolSizes) != 3 {
+		t.Fatalf("StakePoolSizes: unexpected number of entries - got "+
+			"%d, want 3", len(poolSizes))
+	}
+	for i, poolSize := range poolSizes {
+		height := int64(168 - i)
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		var wantImmature int64
+		for j := int64(0); j < int64(params.TicketMaturity); j++ {
+			ancestor, err := dcrutil.NewBlockFromBytes(blocks[height-j])
+			if err != nil {
+				t.Fatalf("NewBlockFromBytes error: %v", err)
+			}
+			wantImmature += int64(ancestor.MsgBlock().Header.FreshStake)
+		}
+		header := &bl.MsgBlock().Header
+		if poolSize.Hash != *bl.Hash() || poolSize.Height != height ||
+			poolSize.PoolSize != header.PoolSize ||
+			poolSize.ImmatureTickets != wantImmature {
+			t.Fatalf("StakePoolSizes: unexpected entry %d - got %+v, "+
+				"want pool size %d and %d immature tickets at "+
+				"height %d", i, poolSize, header.PoolSize,
+				wantImmature, height)
+		}
+	}
+	poolSizes, err = chain.StakePoolSizes(1000)
+	if err != nil {
+		t.Fatalf("StakePoolSizes: unexpected error: %v", err)
+	}
+	if len(poolSizes) != 169 || poolSizes[168].Hash != *params.GenesisHash {
+		t.Fatalf("StakePoolSizes: unexpected entries when requesting "+
+			"more than the chain height - got %d", len(poolSizes))
+	}
+	if _, err := chain.StakePoolSizes(0); err == nil {
+		t.Fatal("StakePoolSizes: did not receive expected error for " +
+			"zero count")
+	}
+}
+
+// TestBlockHashesByPrefix ensures block hashes are found by odd length and
+// mixed case prefixes of their string representation and invalid prefixes are
+// rejected.
+func TestBlockHashesByPrefix(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"hashprefixunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tipHashStr := tip.Hash().String()
+	prefixTests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   int
+	}{
+		{"odd length prefix", strings.ToUpper(tipHashStr[:3]), 200, -1},
+		{"full hash", tipHashStr, 200, 1},
+		{"empty prefix limited", "", 5, 5},
+	}
+	for i, test := range prefixTests {
+		hashes, err := chain.BlockHashesByPrefix(test.prefix, test.limit)
+		if err != nil {
+			t.Fatalf("BlockHashesByPrefix #%d (%s): unexpected "+
+				"error: %v", i, test.name, err)
+		}
+		if test.want >= 0 && len(hashes) != test.want {
+			t.Fatalf("BlockHashesByPrefix #%d (%s): unexpected "+
+				"number of hashes - got %d, want %d", i,
+				test.name, len(hashes), test.want)
+		}
+		foundTip := false
+		for _, hash := range hashes {
+			if !strings.HasPrefix(hash.String(),
+				strings.ToLower(test.prefix)) {
+				t.Fatalf("BlockHashesByPrefix #%d (%s): hash %v "+
+					"does not have the prefix", i, test.name,
+					hash)
+			}
+			foundTip = foundTip || hash == *tip.Hash()
+		}
+		if test.prefix != "" && !foundTip {
+			t.Fatalf("BlockHashesByPrefix #%d (%s): tip hash not "+
+				"found", i, test.name)
+		}
+	}
+	if _, err := chain.BlockHashesByPrefix("0x12", 10); err == nil {
+		t.Fatal("BlockHashesByPrefix: did not receive expected error " +
+			"for non-hex prefix")
+	}
+}
+
+// TestTxConfirmations ensures the confirmations for transactions are reported
+// as expected both with and without a transaction locator.  Without one, only
+// transactions which still have unspent outputs are found, so regular
+// transactions in the tip and fully spent transactions are not.
+func TestTxConfirmations(t *testing.T) {
+	params := legacyTestParams()
+	locator := make(mapTxLocator)
+	chain, blocks, teardownFunc, err := legacyChainSetupWithConfig(
+		"txconfirmationsunittests", params,
+		blockchain.Config{TxLocator: locator}, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	noLocatorChain, _, teardownFunc2, err := legacyChainSetup(
+		"txconfirmationsnolocator", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc2()
+
+	// Add the transactions of all blocks to the locator and find the most
+	// recent fully spent transaction.
+	var spentTx *dcrutil.Tx
+	var spentTxHeight int64
+	for height := int64(1); height <= 168; height++ {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		for _, stx := range bl.STransactions() {
+			locator[*stx.Hash()] = &database.BlockRegion{Hash: bl.Hash()}
+		}
+		for _, tx := range bl.Transactions() {
+			locator[*tx.Hash()] = &database.BlockRegion{Hash: bl.Hash()}
+			entry, err := noLocatorChain.FetchUtxoEntry(tx.Hash())
+			if err != nil {
+				t.Fatalf("FetchUtxoEntry: unexpected error: %v", err)
+			}
+			if entry == nil && height < 168 {
+				spentTx, spentTxHeight = tx, height
+			}
+		}
+	}
+	if spentTx == nil {
+		t.Fatal("unable to find a fully spent transaction")
+	}
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tests := []struct {
+		name           string
+		hash           *chainhash.Hash
+		confs          int64
+		noLocatorConfs int64
+	}{{
+		name:           "tip regular tx",
+		hash:           tip.Transactions()[0].Hash(),
+		confs:          0,
+		noLocatorConfs: -1,
+	}, {
+		name:           "tip stake tx",
+		hash:           tip.STransactions()[0].Hash(),
+		confs:          0,
+		noLocatorConfs: 0,
+	}, {
+		name:           "parent stake tx",
+		hash:           parent.STransactions()[0].Hash(),
+		confs:          1,
+		noLocatorConfs: 1,
+	}, {
+		name:           "fully spent tx",
+		hash:           spentTx.Hash(),
+		confs:          168 - spentTxHeight,
+		noLocatorConfs: -1,
+	}, {
+		name:           "unknown tx",
+		hash:           &chainhash.Hash{},
+		confs:          -1,
+		noLocatorConfs: -1,
+	}}
+	for i, test := range tests {
+		for _, chainTest := range []struct {
+			name  string
+			chain *blockchain.BlockChain
+			confs int64
+		}{
+			{"locator", chain, test.confs},
+			{"no locator", noLocatorChain, test.noLocatorConfs},
+		} {
+			confs, err := chainTest.chain.TxConfirmations(test.hash)
+			if (err != nil) != (chainTest.confs == -1) {
+				t.Errorf("Test #%d (%s, %s) TxConfirmations: "+
+					"unexpected error: %v", i, test.name,
+					chainTest.name, err)
+				continue
+			}
+			if confs != chainTest.confs {
+				t.Errorf("Test #%d (%s, %s) TxConfirmations: "+
+					"mismatched confirmations - got %d, want %d",
+					i, test.name, chainTest.name, confs,
+					chainTest.confs)
+			}
+		}
+	}
+}
+
+// TestExtractCoinbaseHeight ensures the height encoded in the coinbase of the
+// final test block is extracted and transactions which are not a coinbase are
+// rejected.
+func TestExtractCoinbaseHeight(t *testing.T) {
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	coinbase := tip.Transactions()[0]
+	cbHeight, err := blockchain.ExtractCoinbaseHeight(coinbase)
+	if err != nil {
+		t.Fatalf("ExtractCoinbaseHeight: unexpected error: %v", err)
+	}
+	if cbHeight != tip.Height() {
+		t.Fatalf("ExtractCoinbaseHeight: unexpected height - got %d, "+
+			"want %d", cbHeight, tip.Height())
+	}
+	_, err = blockchain.ExtractCoinbaseHeight(tip.STransactions()[0])
+	if rerr, ok := err.(blockchain.RuleError); !ok ||
+		rerr.ErrorCode != blockchain.ErrFirstTxNotCoinbase {
+		t.Fatalf("ExtractCoinbaseHeight: unexpected error for non "+
+			"coinbase - got %v, want %v", err,
+			blockchain.ErrFirstTxNotCoinbase)
+	}
+	malformed := dcrutil.NewTx(coinbase.MsgTx().Copy())
+	malformed.MsgTx().TxOut = malformed.MsgTx().TxOut[:1]
+	_, err = blockchain.ExtractCoinbaseHeight(malformed)
+	if rerr, ok := err.(blockchain.RuleError); !ok ||
+		rerr.ErrorCode != blockchain.ErrFirstTxNotCoinbase {
+		t.Fatalf("ExtractCoinbaseHeight: unexpected error for "+
+			"coinbase without height - got %v, want %v", err,
+			blockchain.ErrFirstTxNotCoinbase)
+	}
+}
+
+// TestCheckCoinbase ensures the coinbase of the final test block passes the
+// isolated coinbase checks and that the checks detect a bad height, subsidy,
+// and non-coinbase.
+func TestCheckCoinbase(t *testing.T) {
+	params := legacyTestParams()
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	subsidyCache := blockchain.NewSubsidyCache(0, params)
+	tipHeight := tip.Height()
+	voters := tip.MsgBlock().Header.Voters
+	cbSubsidy := subsidyCache.CalcWorkSubsidy(tipHeight, voters) +
+		subsidyCache.CalcTreasurySubsidy(tipHeight, voters)
+	coinbase := tip.Transactions()[0]
+
+	tests := []struct {
+		name    string
+		tx      *dcrutil.Tx
+		height  int64
+		subsidy int64
+		err     error
+	}{{
+		name:    "valid coinbase",
+		tx:      coinbase,
+		height:  tipHeight,
+		subsidy: cbSubsidy,
+		err:     nil,
+	}, {
+		name:    "wrong height",
+		tx:      coinbase,
+		height:  tipHeight + 1,
+		subsidy: cbSubsidy,
+		err:     blockchain.RuleError{ErrorCode: blockchain.ErrCoinbaseHeight},
+	}, {
+		name:    "wrong subsidy",
+		tx:      coinbase,
+		height:  tipHeight,
+		subsidy: cbSubsidy + 1,
+		err:     blockchain.RuleError{ErrorCode: blockchain.ErrBadCoinbaseAmountIn},
+	}, {
+		name:    "not coinbase",
+		tx:      tip.STransactions()[0],
+		height:  tipHeight,
+		subsidy: cbSubsidy,
+		err:     blockchain.RuleError{ErrorCode: blockchain.ErrFirstTxNotCoinbase},
+	}}
+	for i, test := range tests {
+		err := blockchain.CheckCoinbase(test.tx, test.height,
+			test.subsidy, params)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) CheckCoinbase: unexpected "+
+					"error: %v", i, test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		wantCode := test.err.(blockchain.RuleError).ErrorCode
+		if !ok || rerr.ErrorCode != wantCode {
+			t.Errorf("Test #%d (%s) CheckCoinbase: unexpected error "+
+				"- got %v, want %v", i, test.name, err, wantCode)
+		}
+	}
+}
+
+// TestBlockHeightByHash ensures the height of main chain blocks can be looked
+// up by their hash and unknown blocks are rejected.
+func TestBlockHeightByHash(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"heightbyhashunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		hash    *chainhash.Hash
+		height  int64
+		wantErr bool
+	}{
+		{"tip", tip.Hash(), 168, false},
+		{"parent of tip", parent.Hash(), 167, false},
+		{"genesis", params.GenesisHash, 0, false},
+		{"unknown", &chainhash.Hash{}, 0, true},
+	}
+	for i, test := range tests {
+		height, err := chain.BlockHeightByHash(test.hash)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Test #%d (%s) BlockHeightByHash: did not "+
+					"receive expected error", i, test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test #%d (%s) BlockHeightByHash: unexpected "+
+				"error: %v", i, test.name, err)
+			continue
+		}
+		if height != test.height {
+			t.Errorf("Test #%d (%s) BlockHeightByHash: mismatched "+
+				"height - got %d, want %d", i, test.name, height,
+				test.height)
+		}
+	}
+}
+
+// TestLocatorFork ensures the fork point of block locators is the first hash
+// they contain which is part of the main chain, or the genesis block when none
+// are.
+func TestLocatorFork(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"locatorforkunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, parent, err := legacyTipAndParent(blocks)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	tipLocator, err := chain.LatestBlockLocator()
+	if err != nil {
+		t.Fatalf("LatestBlockLocator: unexpected error: %v", err)
+	}
+	unknownHash := &chainhash.Hash{0x01}
+	tests := []struct {
+		name       string
+		locator    blockchain.BlockLocator
+		wantHash   *chainhash.Hash
+		wantHeight int64
+	}{
+		{"tip locator", tipLocator, tip.Hash(), 168},
+		{"unknown then parent", blockchain.BlockLocator{unknownHash,
+			parent.Hash(), tip.Hash()}, parent.Hash(), 167},
+		{"all unknown", blockchain.BlockLocator{unknownHash},
+			params.GenesisHash, 0},
+		{"empty", nil, params.GenesisHash, 0},
+	}
+	for i, test := range tests {
+		hash, height, err := chain.LocatorFork(test.locator)
+		if err != nil {
+			t.Errorf("Test #%d (%s) LocatorFork: unexpected error: %v",
+				i, test.name, err)
+			continue
+		}
+		if *hash != *test.wantHash || height != test.wantHeight {
+			t.Errorf("Test #%d (%s) LocatorFork: unexpected fork - "+
+				"got %v (height %d), want %v (height %d)", i,
+				test.name, hash, height, test.wantHash,
+				test.wantHeight)
+		}
+	}
+}
+
+// TestUtxoSetAtHeight ensures the utxo set reconstructed by unwinding the spend
+// journal from the tip matches the one saved when the block at that height was
+// the tip and that heights outside of the main chain are rejected.
+func TestUtxoSetAtHeight(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"utxosetatheightunittests", params, 150)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Save the utxo set while the block beyond stake validation height is
+	// the tip before connecting the remaining blocks.
+	utxoSet150, err := chain.UtxoSetAtHeight(150)
+	if err != nil {
+		t.Fatalf("UtxoSetAtHeight: unexpected error: %v", err)
+	}
+	if err := processTestBlocks(chain, blocks, 151, 168); err != nil {
+		t.Fatal(err)
+	}
+
+	gotUtxoSet150, err := chain.UtxoSetAtHeight(150)
+	if err != nil {
+		t.Fatalf("UtxoSetAtHeight: unexpected error: %v", err)
+	}
+	if *gotUtxoSet150.BestHash() != *utxoSet150.BestHash() {
+		t.Fatalf("UtxoSetAtHeight: unexpected best hash - got %v, "+
+			"want %v", gotUtxoSet150.BestHash(), utxoSet150.BestHash())
+	}
+	if err := compareUtxoViews(gotUtxoSet150, utxoSet150); err != nil {
+		t.Fatalf("UtxoSetAtHeight: %v", err)
+	}
+	for _, height := range []int64{-1, 169} {
+		if _, err := chain.UtxoSetAtHeight(height); err == nil {
+			t.Fatalf("UtxoSetAtHeight: did not receive expected "+
+				"error for height %d", height)
+		}
+	}
+}
+
+// TestWriteUtxoSet ensures the utxo set written to a snapshot is identified by
+// the current tip and contains every entry of the utxo set in ascending order of
+// their transaction hashes.
+func TestWriteUtxoSet(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"writeutxosetunittests", params, 168)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	tip, err := dcrutil.NewBlockFromBytes(blocks[168])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	var snapshot bytes.Buffer
+	if err := chain.WriteUtxoSet(&snapshot); err != nil {
+		t.Fatalf("WriteUtxoSet: unexpected error: %v", err)
+	}
+	r := bytes.NewReader(snapshot.Bytes())
+	header, err := blockchain.ReadUtxoSnapshotHeader(r)
+	if err != nil {
+		t.Fatalf("ReadUtxoSnapshotHeader: unexpected error: %v", err)
+	}
+	if header.TipHash != *tip.Hash() || header.TipHeight != 168 {
+		t.Fatalf("ReadUtxoSnapshotHeader: unexpected tip - got %v "+
+			"(height %d), want %v (height 168)", header.TipHash,
+			header.TipHeight, tip.Hash())
+	}
+
+	// Decode the entries from the snapshot into a view and ensure it
+	// matches the current utxo set.
+	snapshotView := blockchain.NewUtxoViewpoint()
+	var prevHash chainhash.Hash
+	for i := uint64(0); i < header.NumEntries; i++ {
+		var txHash chainhash.Hash
+		if _, err := io.ReadFull(r, txHash[:]); err != nil {
+			t.Fatalf("unable to read entry %d hash: %v", i, err)
+		}
+		if i > 0 && bytes.Compare(txHash[:], prevHash[:]) <= 0 {
+			t.Fatalf("snapshot entry %v is not in ascending order",
+				txHash)
+		}
+		serialized, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+			"serialized utxo")
+		if err != nil {
+			t.Fatalf("unable to read entry %d: %v", i, err)
+		}
+		entry, err := blockchain.TstDeserializeUtxoEntry(serialized)
+		if err != nil {
+			t.Fatalf("unable to deserialize entry %d: %v", i, err)
+		}
+		snapshotView.Entries()[txHash] = entry
+		prevHash = txHash
+	}
+	if r.Len() != 0 {
+		t.Fatalf("snapshot has %d unexpected trailing bytes", r.Len())
+	}
+	utxoSet, err := chain.UtxoSetAtHeight(168)
+	if err != nil {
+		t.Fatalf("UtxoSetAtHeight: unexpected error: %v", err)
+	}
+	if err := compareUtxoViews(snapshotView, utxoSet); err != nil {
+		t.Fatalf("WriteUtxoSet: %v", err)
+	}
+}
+
+// compareUtxoViews returns an error describing the first difference between
+// the unspent outputs of the passed utxo views, if any.
+func compareUtxoViews(got, want *blockchain.UtxoViewpoint) error {
+	gotEntries, wantEntries := got.Entries(), want.Entries()
+	if len(gotEntries) != len(wantEntries) {
+		return fmt.Errorf("mismatched number of entries - got %d, want "+
+			"%d", len(gotEntries), len(wantEntries))
+	}
+	for txHash, wantEntry := range wantEntries {
+		gotEntry := gotEntries[txHash]
+		if gotEntry == nil {
+			return fmt.Errorf("missing entry for tx %v", txHash)
+		}
+		if gotEntry.BlockHeight() != wantEntry.BlockHeight() ||
+			gotEntry.BlockIndex() != wantEntry.BlockIndex() ||
+			gotEntry.TxVersion() != wantEntry.TxVersion() ||
+			gotEntry.TransactionType() != wantEntry.TransactionType() {
+			return fmt.Errorf("mismatched entry details for tx %v",
+				txHash)
+		}
+
+		// Decred transactions are limited to far fewer outputs in
+		// practice, so checking the first 256 indices covers all of
+		// them in the test data.
+		for i := uint32(0); i < 256; i++ {
+			if gotEntry.IsOutputSpent(i) != wantEntry.IsOutputSpent(i) ||
+				gotEntry.AmountByIndex(i) != wantEntry.AmountByIndex(i) ||
+				!bytes.Equal(gotEntry.PkScriptByIndex(i),
+					wantEntry.PkScriptByIndex(i)) {
+				return fmt.Errorf("mismatched output %v:%d", txHash,
+					i)
+			}
+		}
+	}
+	return nil
+}
+
+// mapTxLocator provides a transaction locator backed by a map for testing
+// purposes.
+type mapTxLocator map[chainhash.Hash]*database.BlockRegion
+
+// TxBlockRegion returns the block region for the provided transaction hash or
+// nil when it is not in the map.  It is part of the blockchain.TxLocator
+// interface implementation.
+func (l mapTxLocator) TxBlockRegion(hash chainhash.Hash) (*database.BlockRegion, error) {
+	return l[hash], nil
+}
+
+// TestBlockByTx ensures the block which contains a transaction is found via the
+// configured transaction locator and that an error is returned for unknown
+// transactions and when there is no transaction locator.
+func TestBlockByTx(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	params := legacyTestParams()
+	locator := make(mapTxLocator)
+	chain, testBlocks, teardownFunc, err := legacyChainSetupWithConfig(
+		"blockbytxunittests", params,
+		blockchain.Config{TxLocator: locator}, 3)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Add the transactions of the processed blocks to the locator.
+	var blocks []*dcrutil.Block
+	for i := int64(1); i <= 3; i++ {
+		bl, err := dcrutil.NewBlockFromBytes(testBlocks[i])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		for _, tx := range bl.Transactions() {
+			locator[*tx.Hash()] = &database.BlockRegion{Hash: bl.Hash()}
+		}
+		blocks = append(blocks, bl)
+	}
+
+	// Ensure the containing block is found for the transactions of each
+	// block.
+	for _, bl := range blocks {
+		for _, tx := range bl.Transactions() {
+			hash, height, err := chain.BlockByTx(tx.Hash())
+			if err != nil {
+				t.Fatalf("BlockByTx: unexpected error for tx %v: %v",
+					tx.Hash(), err)
+			}
+			if hash != *bl.Hash() || height != bl.Height() {
+				t.Fatalf("BlockByTx: unexpected block for tx %v "+
+					"- got %v (height %d), want %v (height %d)",
+					tx.Hash(), hash, height, bl.Hash(),
+					bl.Height())
+			}
+		}
+	}
+
+	// Ensure an error is returned for an unknown transaction.
+	if _, _, err := chain.BlockByTx(&chainhash.Hash{0x01}); err == nil {
+		t.Fatal("BlockByTx: did not receive expected error for unknown " +
+			"transaction")
+	}
+
+	// Ensure an error is returned when there is no transaction locator.
+	noLocatorChain, teardownFunc2, err := chainSetup("blockbytxnolocator",
+		params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc2()
+	coinbaseHash := blocks[0].Transactions()[0].Hash()
+	if _, _, err := noLocatorChain.BlockByTx(coinbaseHash); err == nil {
+		t.Fatal("BlockByTx: did not receive expected error without a " +
+			"transaction locator")
+	}
+}
+
+// TestNotificationSubscriptions ensures multiple callbacks may be subscribed
+// to chain notifications, that they are invoked in the order they were
+// subscribed, and that unsubscribing works, including from within a callback.
+func TestNotificationSubscriptions(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"notifysubunittests", params, 0)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	processBlock := func(height int64, flags blockchain.BehaviorFlags) {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		_, _, err = chain.ProcessBlock(bl, flags)
+		if err != nil {
+			t.Fatalf("ProcessBlock error at height %v: %v", height, err)
+		}
+	}
+
+	// Subscribe several callbacks which record the order they are invoked
+	// in for block connected notifications.  The second callback
+	// unsubscribes itself the first time it is invoked.
+	var calls []int
+	record := func(id int) blockchain.NotificationCallback {
+		return func(n *blockchain.Notification) {
+			if n.Type == blockchain.NTBlockConnected {
+				calls = append(calls, id)
+			}
+		}
+	}
+	unsubscribe1 := chain.Subscribe(record(1))
+	var unsubscribe2 func()
+	unsubscribe2 = chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type == blockchain.NTBlockConnected {
+			calls = append(calls, 2)
+			unsubscribe2()
+		}
+	})
+	chain.Subscribe(record(3))
+
+	processBlock(1, blockchain.BFNone)
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("unexpected callback order - got %v, want %v", calls,
+			want)
+	}
+
+	// Ensure the callback which unsubscribed itself and the one which is
+	// unsubscribed now no longer receive notifications.  Unsubscribing more
+	// than once must be harmless.
+	calls = nil
+	unsubscribe1()
+	unsubscribe1()
+	processBlock(2, blockchain.BFNone)
+	if want := []int{3}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("unexpected callbacks after unsubscribe - got %v, "+
+			"want %v", calls, want)
+	}
+
+	// Ensure no notifications of any type are sent while processing a
+	// block with the quiet flag even though the chain still advances and
+	// that they resume for the next block.
+	calls = nil
+	var numNtfns int
+	chain.Subscribe(func(n *blockchain.Notification) {
+		numNtfns++
+	})
+	processBlock(3, blockchain.BFQuiet)
+	if len(calls) != 0 || numNtfns != 0 {
+		t.Fatalf("unexpected notifications for quiet block - got %d "+
+			"callbacks (%v)", numNtfns, calls)
+	}
+	if best := chain.BestSnapshot(); best.Height != 3 {
+		t.Fatalf("unexpected best height after quiet block - got %d, "+
+			"want %d", best.Height, 3)
+	}
+	processBlock(4, blockchain.BFNone)
+	if want := []int{3}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("unexpected callbacks after quiet block - got %v, "+
+			"want %v", calls, want)
+	}
+	if numNtfns == 0 {
+		t.Fatal("no notifications after quiet block")
+	}
+
+	// Ensure the connected blocks channels receive the connected blocks in
+	// order and that the one which drops the oldest events only retains
+	// the most recent one when its reader does not keep up.
+	allConnected, closeAll := chain.ConnectedBlocks(3, false)
+	latestConnected, closeLatest := chain.ConnectedBlocks(1, true)
+	for height := int64(5); height <= 7; height++ {
+		processBlock(height, blockchain.BFNone)
+	}
+	for height := int64(5); height <= 7; height++ {
+		event := <-allConnected
+		if event.Block.Height() != height ||
+			event.Parent.Height() != height-1 {
+			t.Fatalf("unexpected connected block event - got block "+
+				"%d (parent %d), want block %d", event.Block.Height(),
+				event.Parent.Height(), height)
+		}
+	}
+	event := <-latestConnected
+	if event.Block.Height() != 7 {
+		t.Fatalf("unexpected latest connected block - got %d, want 7",
+			event.Block.Height())
+	}
+
+	// Ensure the channels are closed once they are unsubscribed and that
+	// processing more blocks and closing them again is harmless.
+	closeAll()
+	closeLatest()
+	closeLatest()
+	processBlock(8, blockchain.BFNone)
+	if _, ok := <-allConnected; ok {
+		t.Fatal("connected blocks channel not closed")
+	}
+	if _, ok := <-latestConnected; ok {
+		t.Fatal("latest connected blocks channel not closed")
+	}
+}
+
+// TestAsyncNotificationChainAccess ensures subscribers are able to access the
+// chain from their callbacks when asynchronous notification delivery is
+// enabled with a queue that blocks when it is full.  The queue only holds a
+// single notification, so connecting a block beyond stake enabled height,
+// which sends multiple notifications, fills it while the callback is waiting
+// on the chain.
+func TestAsyncNotificationChainAccess(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetupWithConfig(
+		"asyncntfnunittests", params,
+		blockchain.Config{NotificationQueueSize: 1}, 0)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	var numNtfns int
+	chain.Subscribe(func(n *blockchain.Notification) {
+		numNtfns++
+		if _, err := chain.HaveBlock(params.GenesisHash); err != nil {
+			t.Errorf("HaveBlock: unexpected error: %v", err)
+		}
+	})
+
+	processed := make(chan error, 1)
+	go func() {
+		processed <- processTestBlocks(chain, blocks, 1, 168)
+	}()
+	select {
+	case err := <-processed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("timeout processing blocks -- deadlocked on the chain " +
+			"lock")
+	}
+	chain.WaitForNotifications()
+
+	// Ensure the accepted and connected notifications for every block
+	// along with the stake notifications for the blocks at or beyond stake
+	// enabled height were delivered.
+	wantNtfns := 2*168 + 2*(168-int(params.StakeEnabledHeight)+1)
+	if numNtfns != wantNtfns {
+		t.Fatalf("unexpected number of notifications - got %d, want %d",
+			numNtfns, wantNtfns)
+	}
+}
+
+// testStructuredLogger is a blockchain.StructuredLogger which records the
+// events it is sent.
+type testStructuredLogger struct {
+	msgs    []string
+	keyvals [][]interface{}
+}
+
+// Log records the passed event message and key/value pairs.
+func (l *testStructuredLogger) Log(msg string, keyvals ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+	l.keyvals = append(l.keyvals, keyvals)
+}
+
+// TestStructuredLogging ensures processed blocks are sent to the structured
+// logger as events with the expected discrete fields.
+func TestStructuredLogging(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"structlogunittests", params, 0)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Load the first block after the genesis block.
+	bl, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+
+	logger := new(testStructuredLogger)
+	blockchain.UseStructuredLogger(logger)
+	defer blockchain.UseStructuredLogger(nil)
+
+	_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+	if err != nil {
+		t.Fatalf("ProcessBlock error: %v", err)
+	}
+
+	// Ensure the processed block event was logged with the hash and height
+	// of the block along with the elapsed time.
+	if len(logger.msgs) != 1 || logger.msgs[0] != "block processed" {
+		t.Fatalf("unexpected structured log events: %v", logger.msgs)
+	}
+	keyvals := logger.keyvals[0]
+	if len(keyvals) != 6 {
+		t.Fatalf("unexpected number of key/value pairs: %v", keyvals)
+	}
+	fields := make(map[interface{}]interface{})
+	for i := 0; i < len(keyvals); i += 2 {
+		fields[keyvals[i]] = keyvals[i+1]
+	}
+	if fields["hash"] != bl.Hash().String() {
+		t.Errorf("unexpected hash field - got %v, want %v",
+			fields["hash"], bl.Hash())
+	}
+	if fields["height"] != int64(1) {
+		t.Errorf("unexpected height field - got %v, want 1",
+			fields["height"])
+	}
+	if _, ok := fields["elapsed"].(time.Duration); !ok {
+		t.Errorf("unexpected elapsed field - got %T, want time.Duration",
+			fields["elapsed"])
+	}
+}
+
+// TestQuietProcessBlockLogging ensures the per-block log lines written by
+// ProcessBlock are suppressed when requested while the block processed hook
+// is still invoked with the processing time.
+func TestQuietProcessBlockLogging(t *testing.T) {
+	// Load the first block after the genesis block.
+	params := legacyTestParams()
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	bl, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+
+	// Log everything to a buffer.
+	var logBuf bytes.Buffer
+	logger := btclog.NewBackend(&logBuf).Logger("CHAN")
+	logger.SetLevel(btclog.LevelTrace)
+	blockchain.UseLogger(logger)
+	defer blockchain.UseLogger(btclog.Disabled)
+
+	tests := []struct {
+		name  string
+		quiet bool
+	}{
+		{"logging", false},
+		{"quiet", true},
+	}
+	for i, test := range tests {
+		var hookCalls int
+		var hookHash chainhash.Hash
+		var hookHeight int64
+		config := blockchain.Config{
+			QuietProcessBlockLogging: test.quiet,
+			BlockProcessedHook: func(hash *chainhash.Hash, height int64, elapsed time.Duration) {
+				hookCalls++
+				hookHash, hookHeight = *hash, height
+			},
+		}
+		dbName := fmt.Sprintf("quietlogunittests%d", i)
+		chain, teardownFunc, err := chainSetupWithConfig(dbName, params,
+			config)
+		if err != nil {
+			t.Fatalf("Failed to setup chain instance: %v", err)
+		}
+
+		logBuf.Reset()
+		_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+		teardownFunc()
+		if err != nil {
+			t.Fatalf("Test #%d (%s) ProcessBlock error: %v", i,
+				test.name, err)
+		}
+
+		logged := strings.Contains(logBuf.String(), "Accepted block")
+		if logged == test.quiet {
+			t.Errorf("Test #%d (%s) unexpected per-block logging - "+
+				"got %q", i, test.name, logBuf.String())
+		}
+		if hookCalls != 1 || hookHash != *bl.Hash() || hookHeight != 1 {
+			t.Errorf("Test #%d (%s) unexpected hook invocation - got "+
+				"%d calls with %v (height %d)", i, test.name,
+				hookCalls, hookHash, hookHeight)
+		}
+	}
+}
+
+// TestLoadedNodeWorkSum ensures the cumulative work of block nodes which are
+// loaded on demand from the database by a new chain instance, which loads them
+// as the parents of nodes that are already in memory, matches the cumulative
+// work calculated when the blocks were originally connected.
+func TestLoadedNodeWorkSum(t *testing.T) {
+	// Create a new database which is shared by multiple chain instances.
+	params := legacyTestParams()
+	db, teardownFunc, err := dbSetup("loadednodeworksumunittest")
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	defer teardownFunc()
+	newChain := func() *blockchain.BlockChain {
+		chain, err := newTestChain(db, params, blockchain.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chain
+	}
+
+	// Process the test blocks with the first chain instance.
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	chain := newChain()
+	if err := processTestBlocks(chain, blocks, 1, 168); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test data must change the difficulty at least once, or the
+	// cumulative work of a parent would be the same regardless of whether
+	// the work of the parent or its child is removed from the cumulative
+	// work of the child.
+	firstBl, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	bitsChange := false
+	for height := int64(2); height <= 168 && !bitsChange; height++ {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		bitsChange = bl.MsgBlock().Header.Bits !=
+			firstBl.MsgBlock().Header.Bits
+	}
+	if !bitsChange {
+		t.Fatal("test data does not change the difficulty")
+	}
+
+	// Ensure a new chain instance for the same database, which only loads
+	// the best block and a few of its ancestors on creation and loads the
+	// remaining nodes via their children, calculates the same cumulative
+	// work for every block in the main chain.
+	loadedChain := newChain()
+	for height := int64(168); height >= 0; height-- {
+		want, err := chain.MinimumWorkCheckpoint(height)
+		if err != nil {
+			t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+		}
+		got, err := loadedChain.MinimumWorkCheckpoint(height)
+		if err != nil {
+			t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v", err)
+		}
+		if got.Hash != want.Hash ||
+			got.CumulativeWork.Cmp(want.CumulativeWork) != 0 {
+			t.Fatalf("MinimumWorkCheckpoint: mismatched checkpoint at "+
+				"height %d - got %v, want %v", height, got, want)
+		}
+	}
+}
+
+// TestVerifyRange ensures VerifyRange accepts stored main chain blocks which
+// are before the most recent checkpoint and reports the height and rule error
+// of the first stored block which fails verification.
+func TestVerifyRange(t *testing.T) {
+	// Create a new database which is shared by multiple chain instances.
+	params := legacyTestParams()
+	db, teardownFunc, err := dbSetup("verifyrangeunittest")
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	defer teardownFunc()
+	newChain := func(checkpoints []chaincfg.Checkpoint) *blockchain.BlockChain {
+		chainParams := cloneParams(params)
+		chainParams.Checkpoints = checkpoints
+		chain, err := newTestChain(db, chainParams, blockchain.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chain
+	}
+
+	// Process the test blocks with a chain instance without checkpoints.
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	chain := newChain(nil)
+	if err := processTestBlocks(chain, blocks, 1, 168); err != nil {
+		t.Fatal(err)
+	}
+	block150, err := chain.BlockByHeight(150)
+	if err != nil {
+		t.Fatalf("BlockByHeight: unexpected error: %v", err)
+	}
+
+	// Ensure stored main chain blocks before the most recent checkpoint are
+	// not treated as forking the main chain before it.
+	chain = newChain([]chaincfg.Checkpoint{
+		{Height: 150, Hash: block150.Hash()},
+	})
+	if err := chain.VerifyRange(0, 169, blockchain.BFNone); err != nil {
+		t.Fatalf("VerifyRange: unexpected error: %v", err)
+	}
+
+	// Ensure invalid ranges are rejected.
+	if err := chain.VerifyRange(100, 170, blockchain.BFNone); err == nil {
+		t.Fatal("VerifyRange: did not receive expected error for range " +
+			"past the best chain height")
+	}
+	if err := chain.VerifyRange(100, 99, blockchain.BFNone); err == nil {
+		t.Fatal("VerifyRange: did not receive expected error for end " +
+			"height before start height")
+	}
+
+	// Ensure the first stored block which fails verification is reported
+	// along with its height and rule error code.  A checkpoint which does
+	// not match the stored block causes it to fail verification.
+	chain = newChain([]chaincfg.Checkpoint{
+		{Height: 100, Hash: params.GenesisHash},
+		{Height: 150, Hash: block150.Hash()},
+	})
+	if err := chain.VerifyRange(0, 100, blockchain.BFNone); err != nil {
+		t.Fatalf("VerifyRange: unexpected error: %v", err)
+	}
+	err = chain.VerifyRange(0, 169, blockchain.BFNone)
+	rerr, ok := err.(blockchain.RuleError)
+	if !ok {
+		t.Fatalf("VerifyRange: did not receive expected rule error - "+
+			"got %v (%T)", err, err)
+	}
+	if rerr.ErrorCode != blockchain.ErrBadCheckpoint {
+		t.Fatalf("VerifyRange: unexpected error code - got %v, want %v",
+			rerr.ErrorCode, blockchain.ErrBadCheckpoint)
+	}
+	if !strings.Contains(rerr.Description, "at height 100 ") {
+		t.Fatalf("VerifyRange: error does not report the height of the "+
+			"failed block: %v", rerr)
+	}
+}
@@ -1702,6 +1702,199 @@ func (b *BlockChain) BlockByHash(hash *chainhash.Hash) (*dcrutil.Block, error) {
 	return b.fetchBlockFromHash(hash)
 }
 
+// BlocksByHashes returns the blocks with the passed hashes in the same order
+// as the hashes.  Unlike calling BlockByHash for each hash, the chain state lock
+// is only acquired once and all blocks which are not already cached in memory
+// are loaded from the database within a single database transaction, which
+// reduces the overhead for bulk readers.
+//
+// The entries for blocks which could not be found are nil and a
+// MissingBlocksError containing their hashes is returned along with the
+// remaining blocks in that case.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlocksByHashes(hashes []chainhash.Hash) ([]*dcrutil.Block, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	blocks := make([]*dcrutil.Block, len(hashes))
+	var missing MissingBlocksError
+	err := b.db.View(func(dbTx database.Tx) error {
+		for i := range hashes {
+			hash := &hashes[i]
+			if block := b.fetchCachedBlock(hash); block != nil {
+				blocks[i] = block
+				continue
+			}
+
+			block, err := dbFetchBlockByHash(dbTx, hash)
+			if err != nil {
+				if isNotInMainChainErr(err) {
+					missing = append(missing, *hash)
+					continue
+				}
+				return err
+			}
+			b.bodyCache.add(block)
+			blocks[i] = block
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return blocks, missing
+	}
+
+	return blocks, nil
+}
+
+// BlockSize returns the serialized size of the block with the given hash as
+// stored in the database.  The size is obtained from the block header, which
+// commits to it, so the block itself is not loaded or deserialized.
+//
+// A database error with the ErrBlockNotFound error code is returned when the
+// block is not stored in the database, such as when it is unknown or it is on
+// a side chain which has never been connected to the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockSize(hash *chainhash.Hash) (int, error) {
+	var size int
+	err := b.db.View(func(dbTx database.Tx) error {
+		header, err := dbFetchHeaderByHash(dbTx, hash)
+		if err != nil {
+			return err
+		}
+		size = int(header.Size)
+		return nil
+	})
+	return size, err
+}
+
+// BlockByTx returns the hash and height of the block in the main chain which
+// contains the transaction with the passed hash.  Transactions in both the
+// regular and stake transaction trees are found.
+//
+// This requires a transaction locator, which is typically the transaction
+// index, to be provided via the TxLocator field of the chain configuration
+// since there is otherwise no way to find transactions which are fully spent.
+// An error is returned when it was not provided.
+//
+// An error is also returned when the transaction is not in the main chain,
+// such as when it is unknown, only in the transaction memory pool, or only in
+// side chain blocks.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockByTx(txHash *chainhash.Hash) (chainhash.Hash, int64, error) {
+	if b.txLocator == nil {
+		return chainhash.Hash{}, 0, fmt.Errorf("looking up the block "+
+			"which contains transaction %v requires the transaction "+
+			"index", txHash)
+	}
+
+	region, err := b.txLocator.TxBlockRegion(*txHash)
+	if err != nil {
+		return chainhash.Hash{}, 0, err
+	}
+	if region == nil {
+		str := fmt.Sprintf("transaction %v is not in the main chain",
+			txHash)
+		return chainhash.Hash{}, 0, errNotInMainChain(str)
+	}
+
+	var height int64
+	err = b.db.View(func(dbTx database.Tx) error {
+		var err error
+		height, err = dbFetchHeightByHash(dbTx, region.Hash)
+		return err
+	})
+	if err != nil {
+		return chainhash.Hash{}, 0, err
+	}
+
+	return *region.Hash, height, nil
+}
+
+// SpentTxOut contains a spent transaction output along with the contextual
+// information about the transaction that contained it as recorded in the spend
+// journal.  The transaction version, type, coinbase and expiry flags are only
+// known when the output was the final unspent output of its containing
+// transaction as indicated by TxFullySpent.
+type SpentTxOut struct {
+	Amount        int64        // The amount of the output.
+	PkScript      []byte       // The public key script for the output.
+	ScriptVersion uint16       // The version of the scripting language.
+	Height        uint32       // Height of the the block containing the tx.
+	Index         uint32       // Index in the block of the transaction.
+	TxVersion     uint16       // The version of creating tx.
+	TxType        stake.TxType // The stake type of the transaction.
+	IsCoinBase    bool         // Whether creating tx is a coinbase.
+	HasExpiry     bool         // The expiry of the creating tx.
+	TxFullySpent  bool         // Whether or not the transaction is fully spent.
+}
+
+// FetchSpendJournal returns the spent transaction outputs recorded in the spend
+// journal for the passed block, which must be part of the main chain.
+//
+// There is one entry for every input that spends an output, and the entries
+// are in the same order the outputs were spent when the block was connected.
+// That is, when the block approves its parent, the inputs of the parent's
+// regular tree transactions, excluding the coinbase, come first in transaction
+// and then input order, followed by the inputs of the block's stake tree
+// transactions in the same manner.  The stakebase inputs of votes are skipped
+// since they do not spend an output.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FetchSpendJournal(block *dcrutil.Block) ([]SpentTxOut, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	parent, err := b.fetchBlockFromHash(&block.MsgBlock().Header.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	var stxos []spentTxOut
+	err = b.db.View(func(dbTx database.Tx) error {
+		if !dbMainChainHasBlock(dbTx, block.Hash()) {
+			return fmt.Errorf("block %v is not in the main chain",
+				block.Hash())
+		}
+
+		var err error
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block, parent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spentTxOuts := make([]SpentTxOut, 0, len(stxos))
+	for i := range stxos {
+		stxo := &stxos[i]
+		pkScript := stxo.pkScript
+		if stxo.compressed {
+			pkScript = decompressScript(pkScript,
+				currentCompressionVersion)
+		}
+		spentTxOuts = append(spentTxOuts, SpentTxOut{
+			Amount:        stxo.amount,
+			PkScript:      pkScript,
+			ScriptVersion: stxo.scriptVersion,
+			Height:        stxo.height,
+			Index:         stxo.index,
+			TxVersion:     stxo.txVersion,
+			TxType:        stxo.txType,
+			IsCoinBase:    stxo.isCoinBase,
+			HasExpiry:     stxo.hasExpiry,
+			TxFullySpent:  stxo.txFullySpent,
+		})
+	}
+
+	return spentTxOuts, nil
+}
+
 // HeightRange returns a range of block hashes for the given start and end
 // heights.  It is inclusive of the start height and exclusive of the end
 // height.  The end height will be limited to the current main chain height.
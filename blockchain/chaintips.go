@@ -0,0 +1,174 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// ChainTipStatus describes the status of a chain tip.
+type ChainTipStatus byte
+
+// These constants are used to identify the status of a chain tip.
+const (
+	// ChainTipActive is the tip of the main chain.
+	ChainTipActive ChainTipStatus = iota
+
+	// ChainTipValidFork is the tip of a side chain.  All of the blocks of
+	// the side chain are available and passed the checks performed when
+	// side chain blocks are accepted.  Keep in mind that side chain blocks
+	// are only fully validated when they are connected to the main chain
+	// during a reorganize.
+	ChainTipValidFork
+)
+
+// chainTipStatusStrings is a map of ChainTipStatus values back to their
+// names as used by the getchaintips RPC for pretty printing.
+var chainTipStatusStrings = map[ChainTipStatus]string{
+	ChainTipActive:    "active",
+	ChainTipValidFork: "valid-fork",
+}
+
+// String returns the ChainTipStatus as a human-readable name.
+func (s ChainTipStatus) String() string {
+	if str, ok := chainTipStatusStrings[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("Unknown ChainTipStatus (%d)", int(s))
+}
+
+// ChainTip describes the tip of a chain known to the block chain instance.
+type ChainTip struct {
+	// Height is the height of the block at the tip.
+	Height int64
+
+	// Hash is the hash of the block at the tip.
+	Hash chainhash.Hash
+
+	// BranchLen is the number of blocks between the tip and the block on
+	// the main chain it forks from.  It is zero for the tip of the main
+	// chain.
+	BranchLen int64
+
+	// Status is the status of the chain the tip belongs to.
+	Status ChainTipStatus
+}
+
+// chainTipSorter implements sort.Interface to allow a slice of chain tips to
+// be sorted by descending height and then by hash.
+type chainTipSorter []ChainTip
+
+// Len returns the number of chain tips in the slice.  It is part of the
+// sort.Interface implementation.
+func (s chainTipSorter) Len() int {
+	return len(s)
+}
+
+// Swap swaps the chain tips at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s chainTipSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less returns whether the chain tip with index i should sort before the chain
+// tip with index j.  It is part of the sort.Interface implementation.
+func (s chainTipSorter) Less(i, j int) bool {
+	if s[i].Height != s[j].Height {
+		return s[i].Height > s[j].Height
+	}
+	return HashToBig(&s[i].Hash).Cmp(HashToBig(&s[j].Hash)) < 0
+}
+
+// ChainTips returns the tips of all of the chains known to the block chain
+// instance, which consist of the tip of the main chain and the tips of any
+// side chains, sorted by descending height.
+//
+// Side chains are only tracked in memory, so only those which were processed
+// since the chain instance was created are returned.  Also, blocks which fail
+// validation and orphan blocks are not added to the block index, so there are
+// never any tips for them.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	tips := []ChainTip{{
+		Height:    b.bestNode.height,
+		Hash:      b.bestNode.hash,
+		BranchLen: 0,
+		Status:    ChainTipActive,
+	}}
+	for _, node := range b.index {
+		// Side chain tips are side chain nodes without any children.
+		if node.inMainChain || len(node.children) != 0 {
+			continue
+		}
+
+		// Find the block on the main chain the side chain forks from.
+		// The parents of side chain nodes are always linked since the
+		// nodes are only added to the index once their parent is known.
+		fork := node.parent
+		for fork != nil && !fork.inMainChain {
+			fork = fork.parent
+		}
+		if fork == nil {
+			log.Warnf("Unable to find the fork point of side chain "+
+				"block %v", node.hash)
+			continue
+		}
+
+		tips = append(tips, ChainTip{
+			Height:    node.height,
+			Hash:      node.hash,
+			BranchLen: node.height - fork.height,
+			Status:    ChainTipValidFork,
+		})
+	}
+	sort.Sort(chainTipSorter(tips))
+
+	return tips
+}
+
+// SideChainBlocks returns the hashes of the blocks of the side chain which ends
+// at the passed side chain tip, ordered from the first block after the block on
+// the main chain it forks from up to and including the tip.  These are the
+// blocks which would be connected when reorganizing to the tip.
+//
+// An error is returned when the passed hash is not the tip of a side chain
+// known to the block chain instance.  See ChainTips for details regarding which
+// side chains are tracked.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SideChainBlocks(tip *chainhash.Hash) ([]chainhash.Hash, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node, ok := b.index[*tip]
+	if !ok || node.inMainChain || len(node.children) != 0 {
+		return nil, fmt.Errorf("block %v is not a known side chain tip",
+			tip)
+	}
+
+	// Walk backwards from the tip to the block on the main chain the side
+	// chain forks from and then reverse the collected hashes so they are
+	// ordered from the fork point to the tip.
+	var hashes []chainhash.Hash
+	for ; node != nil && !node.inMainChain; node = node.parent {
+		hashes = append(hashes, node.hash)
+	}
+	if node == nil {
+		return nil, AssertError(fmt.Sprintf("unable to find the fork "+
+			"point of side chain block %v", tip))
+	}
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+
+	return hashes, nil
+}
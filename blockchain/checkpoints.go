@@ -72,6 +72,27 @@ func (b *BlockChain) LatestCheckpoint() *chaincfg.Checkpoint {
 	return checkpoint
 }
 
+// IsCheckpoint returns whether the passed block hash is one of the hard-coded
+// checkpoints for the active network.  It always returns false when
+// checkpoints are disabled or there are no checkpoints for the active network.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsCheckpoint(hash *chainhash.Hash) bool {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if b.noCheckpoints || len(b.chainParams.Checkpoints) == 0 {
+		return false
+	}
+
+	for i := range b.chainParams.Checkpoints {
+		if b.chainParams.Checkpoints[i].Hash.IsEqual(hash) {
+			return true
+		}
+	}
+	return false
+}
+
 // verifyCheckpoint returns whether the passed block height and hash combination
 // match the hard-coded checkpoint data.  It also returns true if there is no
 // checkpoint data for the passed block height.
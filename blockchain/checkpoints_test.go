@@ -0,0 +1,73 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain_test
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+)
+
+// TestCheckpointAccessors ensures the checkpoint accessors identify the
+// configured checkpoints and report none when checkpoints are disabled or the
+// network does not have any.
+func TestCheckpointAccessors(t *testing.T) {
+	// Create a chain instance for a network without any checkpoints.
+	params := cloneParams(&chaincfg.SimNetParams)
+	params.Checkpoints = nil
+	chain, teardownFunc, err := chainSetup("nocheckpointunittests", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	genesisHash := params.GenesisHash
+	if chain.IsCheckpoint(genesisHash) {
+		t.Fatal("IsCheckpoint: genesis block is a checkpoint without " +
+			"any configured checkpoints")
+	}
+	if checkpoint := chain.LatestCheckpoint(); checkpoint != nil {
+		t.Fatalf("LatestCheckpoint: unexpected checkpoint %v without "+
+			"any configured checkpoints", checkpoint.Hash)
+	}
+
+	// Create a chain instance for a network with checkpoints.
+	params = cloneParams(&chaincfg.SimNetParams)
+	params.Checkpoints = []chaincfg.Checkpoint{
+		{Height: 0, Hash: genesisHash},
+		{Height: 10, Hash: mustParseHash("000000000000000000000000000000000000000000000000000000000000000a")},
+	}
+	chain, teardownFunc2, err := chainSetup("checkpointunittests", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc2()
+
+	for _, checkpoint := range params.Checkpoints {
+		if !chain.IsCheckpoint(checkpoint.Hash) {
+			t.Fatalf("IsCheckpoint: checkpoint %v at height %d was not "+
+				"identified", checkpoint.Hash, checkpoint.Height)
+		}
+	}
+	notCheckpoint := mustParseHash("000000000000000000000000000000000000000000000000000000000000000b")
+	if chain.IsCheckpoint(notCheckpoint) {
+		t.Fatalf("IsCheckpoint: %v is not a checkpoint", notCheckpoint)
+	}
+	latest := chain.LatestCheckpoint()
+	if latest == nil || latest.Height != 10 {
+		t.Fatalf("LatestCheckpoint: unexpected checkpoint %v", latest)
+	}
+
+	// Ensure no checkpoints are reported once they are disabled.
+	chain.DisableCheckpoints(true)
+	if chain.IsCheckpoint(genesisHash) {
+		t.Fatal("IsCheckpoint: genesis block is a checkpoint with " +
+			"checkpoints disabled")
+	}
+	if checkpoint := chain.LatestCheckpoint(); checkpoint != nil {
+		t.Fatalf("LatestCheckpoint: unexpected checkpoint %v with "+
+			"checkpoints disabled", checkpoint.Hash)
+	}
+}
@@ -61,73 +61,96 @@ func isSupportedDbType(dbType string) bool {
 // block already inserted.  In addition to the new chain instance, it returns
 // a teardown function the caller should invoke when done testing to clean up.
 func chainSetup(dbName string, params *chaincfg.Params) (*blockchain.BlockChain, func(), error) {
+	return chainSetupWithConfig(dbName, params, blockchain.Config{})
+}
+
+// chainSetupWithConfig is the same as chainSetup except the chain instance is
+// created with the passed configuration.  The database, chain parameters, time
+// source, and signature cache of the configuration are always replaced.
+func chainSetupWithConfig(dbName string, params *chaincfg.Params, config blockchain.Config) (*blockchain.BlockChain, func(), error) {
+	db, teardown, err := dbSetup(dbName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create the main chain instance.
+	chain, err := newTestChain(db, params, config)
+	if err != nil {
+		teardown()
+		return nil, nil, err
+	}
+
+	return chain, teardown, nil
+}
+
+// dbSetup is used to create a new db which chain instances are able to be
+// created against via newTestChain.  This allows multiple chain instances to
+// share the same database.  In addition to the new database, it returns a
+// teardown function the caller should invoke when done testing to clean up.
+func dbSetup(dbName string) (database.DB, func(), error) {
 	if !isSupportedDbType(testDbType) {
 		return nil, nil, fmt.Errorf("unsupported db type %v", testDbType)
 	}
 
 	// Handle memory database specially since it doesn't need the disk
 	// specific handling.
-	var db database.DB
-	var teardown func()
 	if testDbType == "memdb" {
-		ndb, err := database.Create(testDbType)
+		db, err := database.Create(testDbType)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating db: %v", err)
 		}
-		db = ndb
 
 		// Setup a teardown function for cleaning up.  This function is
 		// returned to the caller to be invoked when it is done testing.
-		teardown = func() {
+		teardown := func() {
 			db.Close()
 		}
-	} else {
-		// Create the root directory for test databases.
-		if !fileExists(testDbRoot) {
-			if err := os.MkdirAll(testDbRoot, 0700); err != nil {
-				err := fmt.Errorf("unable to create test db "+
-					"root: %v", err)
-				return nil, nil, err
-			}
-		}
+		return db, teardown, nil
+	}
 
-		// Create a new database to store the accepted blocks into.
-		dbPath := filepath.Join(testDbRoot, dbName)
-		_ = os.RemoveAll(dbPath)
-		ndb, err := database.Create(testDbType, dbPath, blockDataNet)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error creating db: %v", err)
+	// Create the root directory for test databases.
+	if !fileExists(testDbRoot) {
+		if err := os.MkdirAll(testDbRoot, 0700); err != nil {
+			err := fmt.Errorf("unable to create test db root: %v", err)
+			return nil, nil, err
 		}
-		db = ndb
+	}
 
-		// Setup a teardown function for cleaning up.  This function is
-		// returned to the caller to be invoked when it is done testing.
-		teardown = func() {
-			db.Close()
-			os.RemoveAll(dbPath)
-			os.RemoveAll(testDbRoot)
-		}
+	// Create a new database to store the accepted blocks into.
+	dbPath := filepath.Join(testDbRoot, dbName)
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(testDbType, dbPath, blockDataNet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating db: %v", err)
 	}
 
+	// Setup a teardown function for cleaning up.  This function is
+	// returned to the caller to be invoked when it is done testing.
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+		os.RemoveAll(testDbRoot)
+	}
+	return db, teardown, nil
+}
+
+// newTestChain creates a new chain instance for the passed database with the
+// passed configuration.  The database, chain parameters, time source, and
+// signature cache of the configuration are always replaced.
+func newTestChain(db database.DB, params *chaincfg.Params, config blockchain.Config) (*blockchain.BlockChain, error) {
 	// Copy the chain params to ensure any modifications the tests do to
 	// the chain parameters do not affect the global instance.
 	paramsCopy := *params
 
-	// Create the main chain instance.
-	chain, err := blockchain.New(&blockchain.Config{
-		DB:          db,
-		ChainParams: &paramsCopy,
-		TimeSource:  blockchain.NewMedianTime(),
-		SigCache:    txscript.NewSigCache(1000),
-	})
-
+	config.DB = db
+	config.ChainParams = &paramsCopy
+	config.TimeSource = blockchain.NewMedianTime()
+	config.SigCache = txscript.NewSigCache(1000)
+	chain, err := blockchain.New(&config)
 	if err != nil {
-		teardown()
-		err := fmt.Errorf("failed to create chain instance: %v", err)
-		return nil, nil, err
+		return nil, fmt.Errorf("failed to create chain instance: %v", err)
 	}
-
-	return chain, teardown, nil
+	return chain, nil
 }
 
 // loadUtxoView returns a utxo view loaded from a file.
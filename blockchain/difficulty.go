@@ -12,6 +12,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
 	"github.com/decred/dcrd/wire"
 )
 
@@ -461,6 +462,52 @@ func (b *BlockChain) CalcNextRequiredDifficulty(timestamp time.Time) (uint32,
 	return difficulty, err
 }
 
+// DifficultyAtHeight returns the compact difficulty bits of the main chain
+// block at the passed height along with the difficulty they represent as a
+// multiple of the minimum difficulty allowed by the proof-of-work limit of the
+// active network.  Only the block header is read from the database, so the
+// block itself is not loaded.
+//
+// An error is returned when the height is negative or beyond the current tip
+// of the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DifficultyAtHeight(height int64) (uint32, float64, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if height < 0 || height > b.bestNode.height {
+		return 0, 0, fmt.Errorf("height %d is outside of the main chain "+
+			"range [0, %d]", height, b.bestNode.height)
+	}
+
+	var bits uint32
+	err := b.db.View(func(dbTx database.Tx) error {
+		header, err := dbFetchHeaderByHeight(dbTx, height)
+		if err != nil {
+			return err
+		}
+		bits = header.Bits
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The minimum difficulty is the proof-of-work limit bits converted back
+	// to a number rather than the proof-of-work limit directly, since the
+	// compact form the bits are encoded with loses precision.
+	max := CompactToBig(b.chainParams.PowLimitBits)
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0, 0, AssertError(fmt.Sprintf("block at height %d has "+
+			"invalid difficulty bits %08x", height, bits))
+	}
+	difficulty, _ := new(big.Rat).SetFrac(max, target).Float64()
+
+	return bits, difficulty, nil
+}
+
 // mergeDifficulty takes an original stake difficulty and two new, scaled
 // stake difficulties, merges the new difficulties, and outputs a new
 // merged stake difficulty.
@@ -1512,3 +1559,46 @@ func (b *BlockChain) EstimateNextStakeDifficulty(newTickets int64, useMaxTickets
 	b.chainLock.Unlock()
 	return estimate, err
 }
+
+// EstimateStakeDifficulty estimates the stake difficulty of the next retarget
+// interval under the assumption that tickets are purchased at the provided
+// constant rate, expressed as the number of tickets purchased over an entire
+// retarget interval, for the remainder of the current interval.
+//
+// The model works as follows:
+//   - Tickets which have already been purchased in the current interval are
+//     counted as is
+//   - The tickets purchased in each of the remaining blocks which are able to
+//     include purchases at the current stake difficulty are projected from the
+//     assumed rate, so the number of new tickets is the rate scaled by the
+//     fraction of the interval those blocks make up, rounded down
+//   - The resulting number of new tickets is then used to estimate the next
+//     stake difficulty with the same algorithm as EstimateNextStakeDifficulty
+//
+// An error is returned when the rate is negative or more than the maximum
+// number of tickets that can be purchased in an entire interval.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) EstimateStakeDifficulty(ticketsInWindow int64) (int64, error) {
+	intervalSize := b.chainParams.StakeDiffWindowSize
+	maxTicketsPerBlock := int64(b.chainParams.MaxFreshStakePerBlock)
+	maxTicketsInWindow := intervalSize * maxTicketsPerBlock
+	if ticketsInWindow < 0 || ticketsInWindow > maxTicketsInWindow {
+		return 0, fmt.Errorf("unable to create an estimated stake "+
+			"difficulty with a rate of %d tickets per interval since "+
+			"it is outside of the valid range [0, %d]",
+			ticketsInWindow, maxTicketsInWindow)
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// Project the number of tickets purchased in the remaining blocks of
+	// the current interval that are able to include purchases from the
+	// assumed rate.  The retarget block itself is excluded since tickets in
+	// it are purchased at the next stake difficulty.
+	curHeight := b.bestNode.height
+	blocksUntilRetarget := intervalSize - curHeight%intervalSize
+	newTickets := ticketsInWindow * (blocksUntilRetarget - 1) / intervalSize
+	return b.estimateNextStakeDifficulty(b.bestNode, newTickets, false)
+}
@@ -7,6 +7,8 @@ package blockchain
 
 import (
 	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
 )
 
 // VoteVersionError identifies an error that indicates a vote version was
@@ -39,6 +41,18 @@ func (e DeploymentError) Error() string {
 	return fmt.Sprintf("deployment ID %v does not exist", string(e))
 }
 
+// MissingBlocksError identifies an error that indicates one or more of the
+// blocks requested by BlocksByHashes could not be found.  It contains the
+// hashes of the missing blocks in the order they were requested.
+type MissingBlocksError []chainhash.Hash
+
+// Error returns the missing blocks error as a human-readable string and
+// satisfies the error interface.
+func (e MissingBlocksError) Error() string {
+	return fmt.Sprintf("unable to find %d requested block(s): %v", len(e),
+		[]chainhash.Hash(e))
+}
+
 // AssertError identifies an error that indicates an internal code consistency
 // issue and should be treated as a critical and unrecoverable error.
 type AssertError string
@@ -452,6 +466,15 @@ const (
 	// ErrInvalidEarlyVoteBits indicates that a block before stake validation
 	// height had an unallowed vote bits value.
 	ErrInvalidEarlyVoteBits
+
+	// ErrRejectedByPolicy indicates that a block was rejected by the
+	// pre-accept filter provided via the chain configuration.
+	ErrRejectedByPolicy
+
+	// ErrReorgTooDeep indicates that a block would cause a reorganize that
+	// disconnects more blocks from the main chain than the maximum reorg
+	// depth provided via the chain configuration allows.
+	ErrReorgTooDeep
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -552,6 +575,8 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrFraudBlockIndex:        "ErrFraudBlockIndex",
 	ErrZeroValueOutputSpend:   "ErrZeroValueOutputSpend",
 	ErrInvalidEarlyVoteBits:   "ErrInvalidEarlyVoteBits",
+	ErrRejectedByPolicy:       "ErrRejectedByPolicy",
+	ErrReorgTooDeep:           "ErrReorgTooDeep",
 }
 
 // String returns the ErrorCode as a human-readable name.
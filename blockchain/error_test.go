@@ -54,6 +54,7 @@ func TestErrorCodeStringer(t *testing.T) {
 		{blockchain.ErrBadCoinbaseValue, "ErrBadCoinbaseValue"},
 		{blockchain.ErrScriptMalformed, "ErrScriptMalformed"},
 		{blockchain.ErrScriptValidation, "ErrScriptValidation"},
+		{blockchain.ErrReorgTooDeep, "ErrReorgTooDeep"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
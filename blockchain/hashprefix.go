@@ -0,0 +1,115 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/internal/dbnamespace"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
+)
+
+// decodeHashPrefix returns the values of the hex digits in the passed prefix,
+// one per element.  An error is returned when the prefix contains characters
+// which are not hex digits or is longer than a hash string.
+func decodeHashPrefix(prefix string) ([]byte, error) {
+	if len(prefix) > chainhash.MaxHashStringSize {
+		return nil, fmt.Errorf("hash prefix %q is longer than the max "+
+			"hash string size of %d", prefix,
+			chainhash.MaxHashStringSize)
+	}
+
+	nibbles := make([]byte, len(prefix))
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		switch {
+		case c >= '0' && c <= '9':
+			nibbles[i] = c - '0'
+		case c >= 'a' && c <= 'f':
+			nibbles[i] = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			nibbles[i] = c - 'A' + 10
+		default:
+			return nil, fmt.Errorf("hash prefix %q contains the "+
+				"non-hex character %q", prefix, c)
+		}
+	}
+	return nibbles, nil
+}
+
+// hasHashPrefix returns whether or not the string representation of the passed
+// hash, which is byte reversed, begins with the hex digits represented by the
+// passed nibbles.
+func hasHashPrefix(hash []byte, nibbles []byte) bool {
+	for i, nibble := range nibbles {
+		b := hash[chainhash.HashSize-1-i/2]
+		if i%2 == 0 {
+			b >>= 4
+		}
+		if b&0x0f != nibble {
+			return false
+		}
+	}
+	return true
+}
+
+// BlockHashesByPrefix returns up to limit hashes of known blocks whose string
+// representation begins with the passed hex prefix.  The prefix is not case
+// sensitive and may consist of an odd number of hex digits.  An error is
+// returned when it contains any characters which are not hex digits.
+//
+// The hashes of blocks in the main chain, which are found by scanning the hash
+// index in the database, are returned first followed by the hashes of any side
+// chain blocks in the memory block index in no particular order.  Since every
+// block in the main chain is examined when there are not enough matches,
+// callers should provide a reasonable limit and prefix length.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockHashesByPrefix(prefix string, limit int) ([]chainhash.Hash, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive - got %d", limit)
+	}
+	nibbles, err := decodeHashPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var hashes []chainhash.Hash
+	err = b.db.View(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		hashIndex := meta.Bucket(dbnamespace.HashIndexBucketName)
+		cursor := hashIndex.Cursor()
+		for ok := cursor.First(); ok && len(hashes) < limit; ok = cursor.Next() {
+			key := cursor.Key()
+			if len(key) != chainhash.HashSize || !hasHashPrefix(key, nibbles) {
+				continue
+			}
+
+			var hash chainhash.Hash
+			copy(hash[:], key)
+			hashes = append(hashes, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, node := range b.index {
+		if len(hashes) >= limit {
+			break
+		}
+		if node.inMainChain || !hasHashPrefix(hash[:], nibbles) {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// headerIndexVersion is the version of the serialized header index format
+// written by ExportHeaderIndex.
+const headerIndexVersion = 1
+
+// -----------------------------------------------------------------------------
+// The serialized header index consists of the main chain block nodes from the
+// genesis block through the best block at the time of the export and is of the
+// following form:
+//
+//   <version><network><num nodes><node 0>...<node n-1><checksum>
+//
+//   Field      Type            Size
+//   version    uint32          4 bytes
+//   network    uint32          4 bytes
+//   num nodes  uint32          4 bytes
+//   nodes      []node          variable
+//   checksum   sha256 hash     32 bytes
+//
+// Each node is of the form:
+//
+//   <header><num spent><spent tickets><num revoked><revoked tickets>
+//   <num votes><votes>
+//
+//   Field            Type               Size
+//   header           wire.BlockHeader   180 bytes
+//   num spent        VarInt             variable
+//   spent tickets    []chainhash.Hash   32 bytes * num spent
+//   num revoked      VarInt             variable
+//   revoked tickets  []chainhash.Hash   32 bytes * num revoked
+//   num votes        VarInt             variable
+//   votes            []vote             6 bytes * num votes
+//
+// Each vote consists of its uint32 version followed by its uint16 vote bits.
+// All integers are encoded in little endian and the checksum is the sha256 hash
+// of all of the preceding bytes.
+// -----------------------------------------------------------------------------
+
+// headerIndexNode houses the information for a block node which is stored in
+// the serialized header index.
+type headerIndexNode struct {
+	header         wire.BlockHeader
+	ticketsSpent   []chainhash.Hash
+	ticketsRevoked []chainhash.Hash
+	votes          []VoteVersionTuple
+}
+
+// writeHeaderIndexNode serializes the passed header index node to the passed
+// writer.
+func writeHeaderIndexNode(w io.Writer, node *headerIndexNode) error {
+	if err := node.header.Serialize(w); err != nil {
+		return err
+	}
+	for _, hashes := range [][]chainhash.Hash{node.ticketsSpent,
+		node.ticketsRevoked} {
+
+		err := wire.WriteVarInt(w, 0, uint64(len(hashes)))
+		if err != nil {
+			return err
+		}
+		for i := range hashes {
+			if _, err := w.Write(hashes[i][:]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := wire.WriteVarInt(w, 0, uint64(len(node.votes))); err != nil {
+		return err
+	}
+	var vote [6]byte
+	for _, v := range node.votes {
+		binary.LittleEndian.PutUint32(vote[0:4], v.Version)
+		binary.LittleEndian.PutUint16(vote[4:6], v.Bits)
+		if _, err := w.Write(vote[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readHeaderIndexHashes reads the number of hashes followed by the hashes
+// themselves from the passed reader and ensures the number of hashes matches
+// the expected number, which is taken from the associated block header.
+func readHeaderIndexHashes(r io.Reader, expected int, desc string) ([]chainhash.Hash, error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count != uint64(expected) {
+		return nil, fmt.Errorf("header index node has %d %s while its "+
+			"header commits to %d", count, desc, expected)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]chainhash.Hash, count)
+	for i := range hashes {
+		if _, err := io.ReadFull(r, hashes[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// readHeaderIndexNode deserializes a header index node from the passed reader.
+// The number of tickets and votes the node contains must match the number the
+// header of the node commits to.
+func readHeaderIndexNode(r io.Reader) (*headerIndexNode, error) {
+	var node headerIndexNode
+	if err := node.header.Deserialize(r); err != nil {
+		return nil, err
+	}
+	numVotes := int(node.header.Voters)
+	var err error
+	node.ticketsSpent, err = readHeaderIndexHashes(r, numVotes,
+		"spent tickets")
+	if err != nil {
+		return nil, err
+	}
+	node.ticketsRevoked, err = readHeaderIndexHashes(r,
+		int(node.header.Revocations), "revoked tickets")
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count != uint64(numVotes) {
+		return nil, fmt.Errorf("header index node has %d votes while "+
+			"its header commits to %d", count, numVotes)
+	}
+	if count > 0 {
+		node.votes = make([]VoteVersionTuple, count)
+		var vote [6]byte
+		for i := range node.votes {
+			if _, err := io.ReadFull(r, vote[:]); err != nil {
+				return nil, err
+			}
+			node.votes[i] = VoteVersionTuple{
+				Version: binary.LittleEndian.Uint32(vote[0:4]),
+				Bits:    binary.LittleEndian.Uint16(vote[4:6]),
+			}
+		}
+	}
+	return &node, nil
+}
+
+// ExportHeaderIndex writes a snapshot of the block index for the main chain,
+// from the genesis block through the current best block, to the passed writer.
+// The snapshot contains the header of each block along with the stake
+// information the block index tracks for it and is versioned and protected by
+// a checksum.  It may later be loaded with ImportHeaderIndex in order to
+// populate the block index without loading each block from the database.
+//
+// The chain lock is only held while the hashes of the main chain blocks are
+// recorded, so blocks may be processed while the blocks themselves are loaded.
+// Stored blocks never change, so the snapshot reflects the main chain as of the
+// time the hashes were recorded.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ExportHeaderIndex(w io.Writer) error {
+	b.chainLock.RLock()
+	tipHeight := b.bestNode.height
+	hashes := make([]chainhash.Hash, 0, tipHeight+1)
+	err := b.db.View(func(dbTx database.Tx) error {
+		for height := int64(0); height <= tipHeight; height++ {
+			hash, err := dbFetchHashByHeight(dbTx, height)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, *hash)
+		}
+		return nil
+	})
+	b.chainLock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	checksum := sha256.New()
+	cw := io.MultiWriter(w, checksum)
+	var prefix [12]byte
+	binary.LittleEndian.PutUint32(prefix[0:4], headerIndexVersion)
+	binary.LittleEndian.PutUint32(prefix[4:8], uint32(b.chainParams.Net))
+	binary.LittleEndian.PutUint32(prefix[8:12], uint32(len(hashes)))
+	if _, err := cw.Write(prefix[:]); err != nil {
+		return err
+	}
+
+	// Load the blocks by their hash rather than their height since the
+	// main chain might have changed since the hashes were recorded.
+	err = b.db.View(func(dbTx database.Tx) error {
+		for i := range hashes {
+			blockBytes, err := dbTx.FetchBlock(&hashes[i])
+			if err != nil {
+				return err
+			}
+			block, err := dcrutil.NewBlockFromBytes(blockBytes)
+			if err != nil {
+				return err
+			}
+			err = writeHeaderIndexNode(cw, &headerIndexNode{
+				header:         block.MsgBlock().Header,
+				ticketsSpent:   ticketsSpentInBlock(block),
+				ticketsRevoked: ticketsRevokedInBlock(block),
+				votes:          voteBitsInBlock(block),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(checksum.Sum(nil))
+	return err
+}
+
+// ImportHeaderIndex loads a snapshot of the block index created by
+// ExportHeaderIndex from the passed reader and adds the block nodes it contains
+// to the block index so they do not need to be loaded from the database on
+// demand.
+//
+// The snapshot is not trusted until it has been fully validated.  In addition
+// to the version, network, and checksum of the snapshot, the headers must
+// connect to each other starting from the genesis block, the header at each
+// height must be the one the database records for that height in the main
+// chain, and the cumulative work of each node must agree with any node for the
+// same block which is already loaded.  Snapshots taken before additional blocks
+// were connected are therefore accepted, while those which include blocks that
+// are no longer part of the main chain are rejected.  The block index is not
+// modified when any of the validation fails.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ImportHeaderIndex(r io.Reader) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	checksum := sha256.New()
+	cr := io.TeeReader(r, checksum)
+	var prefix [12]byte
+	if _, err := io.ReadFull(cr, prefix[:]); err != nil {
+		return err
+	}
+	version := binary.LittleEndian.Uint32(prefix[0:4])
+	if version != headerIndexVersion {
+		return fmt.Errorf("unsupported header index version %d",
+			version)
+	}
+	network := wire.CurrencyNet(binary.LittleEndian.Uint32(prefix[4:8]))
+	if network != b.chainParams.Net {
+		return fmt.Errorf("header index is for network %v instead of "+
+			"%v", network, b.chainParams.Net)
+	}
+	numNodes := int64(binary.LittleEndian.Uint32(prefix[8:12]))
+	if numNodes == 0 || numNodes > b.bestNode.height+1 {
+		return fmt.Errorf("header index contains %d nodes which is "+
+			"outside of the main chain range [1, %d]", numNodes,
+			b.bestNode.height+1)
+	}
+
+	// Read and validate all of the nodes before modifying the block index.
+	nodes := make([]*blockNode, 0, numNodes)
+	err := b.db.View(func(dbTx database.Tx) error {
+		var prevNode *blockNode
+		for height := int64(0); height < numNodes; height++ {
+			indexNode, err := readHeaderIndexNode(cr)
+			if err != nil {
+				return err
+			}
+			header := &indexNode.header
+			hash := header.BlockHash()
+			if int64(header.Height) != height {
+				return fmt.Errorf("header index node %v has height "+
+					"%d instead of %d", hash, header.Height,
+					height)
+			}
+			if prevNode == nil {
+				if hash != *b.chainParams.GenesisHash {
+					return fmt.Errorf("header index starts with "+
+						"block %v instead of the genesis "+
+						"block", hash)
+				}
+			} else if header.PrevBlock != prevNode.hash {
+				return fmt.Errorf("header index node %v does "+
+					"not connect to the previous node %v",
+					hash, prevNode.hash)
+			}
+			mainChainHash, err := dbFetchHashByHeight(dbTx, height)
+			if err != nil {
+				return err
+			}
+			if hash != *mainChainHash {
+				return fmt.Errorf("header index node %v is not "+
+					"the main chain block %v at height %d",
+					hash, mainChainHash, height)
+			}
+
+			node := newBlockNode(header, indexNode.ticketsSpent,
+				indexNode.ticketsRevoked, indexNode.votes)
+			node.inMainChain = true
+			if prevNode != nil {
+				node.workSum.Add(prevNode.workSum, node.workSum)
+			}
+			existing, ok := b.index[hash]
+			if ok && existing.workSum.Cmp(node.workSum) != 0 {
+				return fmt.Errorf("header index node %v has "+
+					"cumulative work %v instead of %v", hash,
+					node.workSum, existing.workSum)
+			}
+			nodes = append(nodes, node)
+			prevNode = node
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var wantChecksum [sha256.Size]byte
+	if _, err := io.ReadFull(r, wantChecksum[:]); err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum.Sum(nil), wantChecksum[:]) {
+		return fmt.Errorf("header index checksum mismatch")
+	}
+
+	// Add the nodes which are not already loaded to the block index and
+	// link all of them to their parents.
+	for i, node := range nodes {
+		var parent *blockNode
+		if i > 0 {
+			parent = nodes[i-1]
+		}
+		if existing, ok := b.index[node.hash]; ok {
+			nodes[i] = existing
+			if parent != nil && existing.parent == nil {
+				existing.parent = parent
+				parent.children = append(parent.children, existing)
+			}
+			continue
+		}
+
+		if parent != nil {
+			node.parent = parent
+			parent.children = append(parent.children, node)
+		}
+		prevHash := node.header.PrevBlock
+		b.index[node.hash] = node
+		b.depNodes[prevHash] = append(b.depNodes[prevHash], node)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/blockchain"
+)
+
+// TestHeaderIndexExportImport ensures the header index exported by a chain
+// instance can be imported by a new chain instance for the same database and
+// that corrupted, mismatched, and stale header indexes are rejected.
+func TestHeaderIndexExportImport(t *testing.T) {
+	// Create a new database which is shared by multiple chain instances.
+	params := legacyTestParams()
+	db, teardownFunc, err := dbSetup("headerindexunittest")
+	if err != nil {
+		t.Fatalf("Failed to setup db: %v", err)
+	}
+	defer teardownFunc()
+	newChain := func() *blockchain.BlockChain {
+		chain, err := newTestChain(db, params, blockchain.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chain
+	}
+	chain := newChain()
+
+	// Export the header index of the chain before any blocks are connected
+	// so it can be used to test importing stale snapshots below.
+	var genesisIndex bytes.Buffer
+	if err := chain.ExportHeaderIndex(&genesisIndex); err != nil {
+		t.Fatalf("ExportHeaderIndex: unexpected error: %v", err)
+	}
+
+	// Load up the test blocks and process them.
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	if err := processTestBlocks(chain, blocks, 1, 168); err != nil {
+		t.Fatal(err)
+	}
+
+	var index bytes.Buffer
+	if err := chain.ExportHeaderIndex(&index); err != nil {
+		t.Fatalf("ExportHeaderIndex: unexpected error: %v", err)
+	}
+	exported := index.Bytes()
+
+	// withChecksum returns a copy of the passed header index with the
+	// provided modification applied and its checksum updated accordingly.
+	withChecksum := func(modify func(b []byte)) []byte {
+		b := make([]byte, len(exported))
+		copy(b, exported)
+		modify(b)
+		body := b[:len(b)-sha256.Size]
+		checksum := sha256.Sum256(body)
+		copy(b[len(body):], checksum[:])
+		return b
+	}
+	badChecksum := make([]byte, len(exported))
+	copy(badChecksum, exported)
+	badChecksum[len(badChecksum)-1] ^= 0xff
+
+	// The header of the genesis block starts right after the version,
+	// network, and number of nodes.  Offset 4 of a header is the start of
+	// its previous block hash.
+	const firstHeaderOffset = 12
+	tests := []struct {
+		name  string
+		index []byte
+		valid bool
+	}{
+		{"valid", exported, true},
+		{"stale", genesisIndex.Bytes(), true},
+		{"bad checksum", badChecksum, false},
+		{"truncated", exported[:len(exported)/2], false},
+		{"empty", nil, false},
+		{"bad version", withChecksum(func(b []byte) { b[0] = 0xff }), false},
+		{"bad network", withChecksum(func(b []byte) { b[4] ^= 0xff }), false},
+		{"too many nodes", withChecksum(func(b []byte) { b[8]++ }), false},
+		{"bad genesis", withChecksum(func(b []byte) {
+			b[firstHeaderOffset+4] ^= 0xff
+		}), false},
+	}
+	for i, test := range tests {
+		// Import the header index into a new chain instance for the
+		// database which only has the best block and a few of its
+		// ancestors loaded.
+		importChain := newChain()
+		err := importChain.ImportHeaderIndex(bytes.NewReader(test.index))
+		if !test.valid {
+			if err == nil {
+				t.Errorf("Test #%d (%s) ImportHeaderIndex: did not "+
+					"receive expected error", i, test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test #%d (%s) ImportHeaderIndex: unexpected "+
+				"error: %v", i, test.name, err)
+			continue
+		}
+
+		// Ensure the chain instance reports the same information for
+		// the main chain after the import.
+		for height := int64(0); height <= 168; height++ {
+			want, err := chain.MinimumWorkCheckpoint(height)
+			if err != nil {
+				t.Fatalf("MinimumWorkCheckpoint: unexpected error: %v",
+					err)
+			}
+			got, err := importChain.MinimumWorkCheckpoint(height)
+			if err != nil {
+				t.Fatalf("Test #%d (%s) MinimumWorkCheckpoint: "+
+					"unexpected error: %v", i, test.name, err)
+			}
+			if got.Hash != want.Hash ||
+				got.CumulativeWork.Cmp(want.CumulativeWork) != 0 {
+				t.Fatalf("Test #%d (%s) MinimumWorkCheckpoint: "+
+					"mismatched checkpoint at height %d - got "+
+					"%v, want %v", i, test.name, height, got, want)
+			}
+		}
+		wantSDiff, err := chain.CalcNextRequiredStakeDifficulty()
+		if err != nil {
+			t.Fatalf("CalcNextRequiredStakeDifficulty: unexpected "+
+				"error: %v", err)
+		}
+		gotSDiff, err := importChain.CalcNextRequiredStakeDifficulty()
+		if err != nil {
+			t.Fatalf("Test #%d (%s) CalcNextRequiredStakeDifficulty: "+
+				"unexpected error: %v", i, test.name, err)
+		}
+		if gotSDiff != wantSDiff {
+			t.Fatalf("Test #%d (%s) CalcNextRequiredStakeDifficulty: "+
+				"got %d, want %d", i, test.name, gotSDiff, wantSDiff)
+		}
+
+		// Ensure the chain instance exports the same header index.
+		var reexported bytes.Buffer
+		if err := importChain.ExportHeaderIndex(&reexported); err != nil {
+			t.Fatalf("Test #%d (%s) ExportHeaderIndex: unexpected "+
+				"error: %v", i, test.name, err)
+		}
+		if !bytes.Equal(reexported.Bytes(), exported) {
+			t.Fatalf("Test #%d (%s) ExportHeaderIndex: mismatched "+
+				"header index after import", i, test.name)
+		}
+	}
+}
+
+// writerFunc is an io.Writer which writes via the function it wraps.
+type writerFunc func(p []byte) (int, error)
+
+// Write writes the passed data via the wrapped function.  It is part of the
+// io.Writer interface implementation.
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// TestExportHeaderIndexProcessing ensures blocks may be processed while the
+// header index is being exported and that the exported header index reflects
+// the main chain as of the start of the export.
+func TestExportHeaderIndexProcessing(t *testing.T) {
+	params := legacyTestParams()
+	chain, blocks, teardownFunc, err := legacyChainSetup(
+		"headerindexprocessunittest", params, 167)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Process the final test block from the first write of the export,
+	// which happens before the blocks of the main chain are loaded.
+	var index bytes.Buffer
+	var processed bool
+	w := writerFunc(func(p []byte) (int, error) {
+		if !processed {
+			processed = true
+			done := make(chan error, 1)
+			go func() {
+				done <- processTestBlocks(chain, blocks, 168, 168)
+			}()
+			select {
+			case err := <-done:
+				if err != nil {
+					return 0, err
+				}
+			case <-time.After(time.Minute):
+				return 0, errors.New("timeout processing block " +
+					"during export")
+			}
+		}
+		return index.Write(p)
+	})
+	if err := chain.ExportHeaderIndex(w); err != nil {
+		t.Fatalf("ExportHeaderIndex: unexpected error: %v", err)
+	}
+	if height := chain.BestSnapshot().Height; height != 168 {
+		t.Fatalf("unexpected best height after export - got %d, want 168",
+			height)
+	}
+
+	// Ensure the header index only contains the blocks through the best
+	// block at the start of the export and is accepted as a stale index.
+	if numNodes := binary.LittleEndian.Uint32(index.Bytes()[8:12]); numNodes != 168 {
+		t.Fatalf("unexpected number of nodes - got %d, want 168",
+			numNodes)
+	}
+	if err := chain.ImportHeaderIndex(bytes.NewReader(index.Bytes())); err != nil {
+		t.Fatalf("ImportHeaderIndex: unexpected error: %v", err)
+	}
+}
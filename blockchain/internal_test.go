@@ -44,7 +44,7 @@ var TstDeserializeUtxoEntry = deserializeUtxoEntry
 // TstCheckBlockHeaderContext makes the internal checkBlockHeaderContext
 // function available to the test package.
 func (b *BlockChain) TstCheckBlockHeaderContext(header *wire.BlockHeader, prevNode *blockNode, flags BehaviorFlags) error {
-	return b.checkBlockHeaderContext(header, prevNode, flags)
+	return b.checkBlockHeaderContext(header, prevNode, flags, nil)
 }
 
 // TstNewBlockNode makes the internal newBlockNode function available to the
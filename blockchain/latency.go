@@ -0,0 +1,103 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultProcessingLatencyWindow is the default number of the most recent
+// block processing times which are kept to calculate the processing latency
+// percentiles.
+const defaultProcessingLatencyWindow = 1000
+
+// LatencyStats houses percentiles of the time it took to process recent
+// blocks.  The percentiles are all zero when no blocks have been processed.
+type LatencyStats struct {
+	// Samples is the number of processing times the percentiles are
+	// calculated from.  It never exceeds the configured window size.
+	Samples int
+
+	// P50, P90, and P99 are the 50th, 90th, and 99th percentiles of the
+	// processing times, respectively.
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+}
+
+// latencyWindow houses a fixed number of the most recent duration samples in a
+// ring buffer so the memory used is constant regardless of the number of
+// samples which are added.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// newLatencyWindow returns a latency window which keeps up to the passed number
+// of the most recent samples.
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, size)}
+}
+
+// add adds the passed sample to the window, replacing the oldest sample when
+// the window is full.
+func (w *latencyWindow) add(sample time.Duration) {
+	if len(w.samples) == 0 {
+		return
+	}
+	w.samples[w.next] = sample
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// percentile returns the passed percentile of the passed sorted samples using
+// the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// stats returns the percentiles of the samples currently in the window.
+func (w *latencyWindow) stats() LatencyStats {
+	numSamples := w.next
+	if w.full {
+		numSamples = len(w.samples)
+	}
+	if numSamples == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, numSamples)
+	copy(sorted, w.samples[:numSamples])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return LatencyStats{
+		Samples: numSamples,
+		P50:     percentile(sorted, 50),
+		P90:     percentile(sorted, 90),
+		P99:     percentile(sorted, 99),
+	}
+}
+
+// ProcessingLatencyStats returns the 50th, 90th, and 99th percentiles of the
+// time it took to process the most recent blocks which were accepted by
+// ProcessBlock, including the time to process any orphans which were accepted
+// as a result.  The number of blocks considered is limited to the processing
+// latency window provided when the chain instance was created.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ProcessingLatencyStats() LatencyStats {
+	b.chainLock.RLock()
+	stats := b.processLatencies.stats()
+	b.chainLock.RUnlock()
+	return stats
+}
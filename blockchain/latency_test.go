@@ -0,0 +1,67 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatencyWindow ensures the latency window only keeps the most recent
+// samples and calculates the expected percentiles.
+func TestLatencyWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		size    int
+		samples []time.Duration
+		want    LatencyStats
+	}{{
+		name: "no samples",
+		size: 10,
+		want: LatencyStats{},
+	}, {
+		name:    "single sample",
+		size:    10,
+		samples: []time.Duration{5},
+		want:    LatencyStats{Samples: 1, P50: 5, P90: 5, P99: 5},
+	}, {
+		name:    "partially full window, unsorted samples",
+		size:    100,
+		samples: []time.Duration{10, 1, 9, 2, 8, 3, 7, 4, 6, 5},
+		want:    LatencyStats{Samples: 10, P50: 5, P90: 9, P99: 10},
+	}, {
+		name:    "full window replaces oldest samples",
+		size:    4,
+		samples: []time.Duration{100, 200, 300, 1, 2, 3, 4},
+		want:    LatencyStats{Samples: 4, P50: 2, P90: 4, P99: 4},
+	}}
+
+	for i, test := range tests {
+		w := newLatencyWindow(test.size)
+		for _, sample := range test.samples {
+			w.add(sample)
+		}
+		if got := w.stats(); got != test.want {
+			t.Errorf("Test #%d (%s) unexpected stats - got %+v, want "+
+				"%+v", i, test.name, got, test.want)
+		}
+	}
+
+	// Ensure the stats are calculated over 100 samples as expected and
+	// that calculating them does not disturb the window.
+	w := newLatencyWindow(100)
+	for i := 100; i > 0; i-- {
+		w.add(time.Duration(i))
+	}
+	want := LatencyStats{Samples: 100, P50: 50, P90: 90, P99: 99}
+	for i := 0; i < 2; i++ {
+		if got := w.stats(); got != want {
+			t.Fatalf("unexpected stats - got %+v, want %+v", got,
+				want)
+		}
+	}
+}
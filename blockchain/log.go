@@ -14,6 +14,28 @@ import (
 // requests it.
 var log btclog.Logger
 
+// StructuredLogger is the interface for a logger which accepts an event
+// message along with discrete key/value pairs describing it.  The key/value
+// pairs are provided as alternating keys and values where each key is a
+// string.  This allows the events to be shipped to log pipelines in a machine
+// parseable form.
+type StructuredLogger interface {
+	Log(msg string, keyvals ...interface{})
+}
+
+// slog is the structured logger chain events are sent to in addition to the
+// human-readable lines written to log.  It is nil, and therefore disabled, by
+// default.
+var slog StructuredLogger
+
+// logEvent sends the passed event message and key/value pairs to the
+// structured logger when one is in use.
+func logEvent(msg string, keyvals ...interface{}) {
+	if slog != nil {
+		slog.Log(msg, keyvals...)
+	}
+}
+
 // The default amount of logging is none.
 func init() {
 	DisableLog()
@@ -23,9 +45,18 @@ func init() {
 // by default until UseLogger is called.
 func DisableLog() {
 	log = btclog.Disabled
+	slog = nil
 }
 
 // UseLogger uses a specified Logger to output package logging info.
 func UseLogger(logger btclog.Logger) {
 	log = logger
 }
+
+// UseStructuredLogger uses a specified StructuredLogger to output chain events
+// as discrete key/value fields.  This is in addition to, and does not change,
+// the human-readable output of the logger provided to UseLogger.  Passing nil
+// disables structured logging.
+func UseStructuredLogger(logger StructuredLogger) {
+	slog = logger
+}
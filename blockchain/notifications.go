@@ -7,6 +7,7 @@ package blockchain
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrutil"
@@ -107,16 +108,339 @@ type Notification struct {
 	Data interface{}
 }
 
-// sendNotification sends a notification with the passed type and data if the
-// caller requested notifications by providing a callback function in the call
-// to New.
+// notificationSubscription houses a callback which has been subscribed to
+// chain notifications along with whether or not it is still subscribed.  When
+// the filter is set, only the notifications it returns true for are delivered
+// to the callback.
+type notificationSubscription struct {
+	callback     NotificationCallback
+	filter       func(*Notification) bool
+	unsubscribed bool
+}
+
+// Subscribe registers the passed callback to receive notifications about
+// various chain events.  Callbacks are invoked in the order they were
+// subscribed.  The returned function unsubscribes the callback, after which
+// it will not receive any further notifications.  It may be called more than
+// once and is safe to call from within a notification callback.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) Subscribe(callback NotificationCallback) (unsubscribe func()) {
+	return b.subscribe(callback, nil)
+}
+
+// isMainChainNotification returns whether or not the passed notification is
+// relevant to subscribers which are only interested in the main chain.  That is
+// every notification other than those for blocks which were accepted to a side
+// chain without causing it to become the main chain.
+func isMainChainNotification(n *Notification) bool {
+	if n.Type != NTBlockAccepted {
+		return true
+	}
+	data, ok := n.Data.(*BlockAcceptedNtfnsData)
+	return !ok || data.OnMainChain
+}
+
+// SubscribeMainChain is identical to Subscribe except the callback does not
+// receive NTBlockAccepted notifications for blocks which were only added to a
+// side chain.  In other words, the block accepted notifications it receives are
+// exclusively for blocks which advanced the best chain, whether by extending it
+// or by causing a reorganize to the side chain they extend.  This is useful for
+// subscribers, such as indexers, which only care about the main chain.
+//
+// Notifications of other types are delivered as usual.  Note that the
+// NTBlockConnected and NTBlockDisconnected notifications are only ever sent for
+// the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SubscribeMainChain(callback NotificationCallback) (unsubscribe func()) {
+	return b.subscribe(callback, isMainChainNotification)
+}
+
+// subscribe registers the passed callback along with the optional filter.  See
+// Subscribe for details.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) subscribe(callback NotificationCallback, filter func(*Notification) bool) (unsubscribe func()) {
+	sub := &notificationSubscription{callback: callback, filter: filter}
+
+	b.notificationsLock.Lock()
+	b.notifications = append(b.notifications, sub)
+	b.notificationsLock.Unlock()
+
+	return func() {
+		b.notificationsLock.Lock()
+		defer b.notificationsLock.Unlock()
+
+		if sub.unsubscribed {
+			return
+		}
+		sub.unsubscribed = true
+
+		// Create a new slice rather than modifying the existing one in
+		// place so any notifications which are currently being sent
+		// continue to iterate over an unmodified slice.
+		subs := make([]*notificationSubscription, 0, len(b.notifications)-1)
+		for _, s := range b.notifications {
+			if s != sub {
+				subs = append(subs, s)
+			}
+		}
+		b.notifications = subs
+	}
+}
+
+// BlockConnectedEvent describes a block which was connected to the main chain
+// along with its parent.  It is sent to the channels returned by
+// ConnectedBlocks.
+type BlockConnectedEvent struct {
+	Block  *dcrutil.Block
+	Parent *dcrutil.Block
+}
+
+// ConnectedBlocks returns a channel which receives an event for every block
+// that is connected to the main chain, in the order they are connected, along
+// with a function which unsubscribes the channel and closes it.  The channel is
+// built on top of the NTBlockConnected notifications, so the events are
+// delivered in the same manner as the notifications sent to Subscribe
+// callbacks.
+//
+// The channel buffers up to the passed number of events, which is treated as
+// one when it is less than one, so the reader does not need to keep pace with
+// the chain.  The passed drop oldest flag selects the behavior when the buffer
+// is full.  When it is set, the oldest buffered event is discarded to make room
+// for the new one so a slow reader never stalls the chain, but it may miss
+// events.  Otherwise, delivery of the notification blocks until the reader
+// makes room, which in turn stalls the processing of the chain unless
+// asynchronous notification delivery is enabled, in which case it stalls the
+// delivery of all notifications instead.
+//
+// The returned function may be called more than once.  No further events are
+// sent once it returns, and the channel is closed.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ConnectedBlocks(bufferSize int, dropOldest bool) (<-chan *BlockConnectedEvent, func()) {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	var mtx sync.Mutex
+	events := make(chan *BlockConnectedEvent, bufferSize)
+	done := make(chan struct{})
+	var closed bool
+	unsubscribe := b.subscribe(func(n *Notification) {
+		blocks, ok := n.Data.([]*dcrutil.Block)
+		if !ok || len(blocks) != 2 {
+			return
+		}
+		event := &BlockConnectedEvent{Block: blocks[0], Parent: blocks[1]}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		if closed {
+			return
+		}
+		if !dropOldest {
+			select {
+			case events <- event:
+			case <-done:
+			}
+			return
+		}
+		for {
+			select {
+			case events <- event:
+				return
+			default:
+			}
+
+			// Discard the oldest event to make room.  The reader
+			// might have made room in the mean time, so nothing is
+			// discarded in that case.
+			select {
+			case <-events:
+			default:
+			}
+		}
+	}, func(n *Notification) bool {
+		return n.Type == NTBlockConnected
+	})
+
+	var closeOnce sync.Once
+	return events, func() {
+		closeOnce.Do(func() {
+			unsubscribe()
+
+			// Wake any delivery which is blocked waiting on the
+			// reader before closing the channel.
+			close(done)
+			mtx.Lock()
+			closed = true
+			close(events)
+			mtx.Unlock()
+		})
+	}
+}
+
+// notificationQueue houses notifications which are waiting to be delivered to
+// subscribers by a dedicated goroutine when asynchronous notification delivery
+// is enabled.  At most one goroutine delivers notifications at a time, so they
+// are delivered in the order they were queued.  The goroutine is started on
+// demand and exits once the queue is empty.
+type notificationQueue struct {
+	mtx          sync.Mutex
+	cond         *sync.Cond
+	maxSize      int
+	dropWhenFull bool
+	queue        []*Notification
+	dispatching  bool
+	dropped      uint64
+}
+
+// newNotificationQueue returns a new notification queue which holds at most
+// the passed number of notifications.  When the queue is full, new
+// notifications are either dropped or block until there is room depending on
+// the passed policy.
+func newNotificationQueue(maxSize int, dropWhenFull bool) *notificationQueue {
+	q := &notificationQueue{
+		maxSize:      maxSize,
+		dropWhenFull: dropWhenFull,
+	}
+	q.cond = sync.NewCond(&q.mtx)
+	return q
+}
+
+// enqueue adds the passed notification to the queue and starts a goroutine to
+// deliver it via the chain when one is not already running.
+func (q *notificationQueue) enqueue(b *BlockChain, n *Notification) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	for len(q.queue) >= q.maxSize {
+		if q.dropWhenFull {
+			q.dropped++
+			log.Warnf("Notification queue is full -- dropping %v "+
+				"notification", n.Type)
+			return
+		}
+		q.cond.Wait()
+	}
+	q.queue = append(q.queue, n)
+
+	if !q.dispatching {
+		q.dispatching = true
+		go q.dispatch(b)
+	}
+}
+
+// dispatch delivers queued notifications via the chain in order until the
+// queue is empty.
+//
+// This MUST be run as a goroutine.
+func (q *notificationQueue) dispatch(b *BlockChain) {
+	q.mtx.Lock()
+	for len(q.queue) > 0 {
+		n := q.queue[0]
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+		q.cond.Broadcast()
+		q.mtx.Unlock()
+
+		b.deliverNotification(n)
+
+		q.mtx.Lock()
+	}
+	q.queue = nil
+	q.dispatching = false
+	q.cond.Broadcast()
+	q.mtx.Unlock()
+}
+
+// wait blocks until all queued notifications have been delivered.
+func (q *notificationQueue) wait() {
+	q.mtx.Lock()
+	for q.dispatching {
+		q.cond.Wait()
+	}
+	q.mtx.Unlock()
+}
+
+// WaitForNotifications blocks until all notifications which have been queued
+// for asynchronous delivery have been delivered to subscribers.  It returns
+// immediately when asynchronous notification delivery is not enabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) WaitForNotifications() {
+	if b.notificationQueue != nil {
+		b.notificationQueue.wait()
+	}
+}
+
+// DroppedNotifications returns the number of notifications which were dropped
+// because the asynchronous notification queue was full.  It is always zero
+// when asynchronous notification delivery is not enabled or the queue is
+// configured to block when it is full.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DroppedNotifications() uint64 {
+	if b.notificationQueue == nil {
+		return 0
+	}
+
+	b.notificationQueue.mtx.Lock()
+	dropped := b.notificationQueue.dropped
+	b.notificationQueue.mtx.Unlock()
+	return dropped
+}
+
+// sendNotification sends a notification with the passed type and data to all
+// callbacks which are subscribed to notifications either by providing a
+// callback function in the call to New or via Subscribe.  When asynchronous
+// notification delivery is enabled, the notification is queued for delivery
+// by a dedicated goroutine instead.
+//
+// This function MUST NOT be called with the chain lock held since callbacks
+// are free to access the chain and queuing a notification blocks until there
+// is room when the queue is configured to block when it is full.
 func (b *BlockChain) sendNotification(typ NotificationType, data interface{}) {
-	// Ignore it if the caller didn't request notifications.
-	if b.notifications == nil {
+	// Ignore it if nobody is interested in notifications.
+	b.notificationsLock.RLock()
+	numSubs := len(b.notifications)
+	b.notificationsLock.RUnlock()
+	if numSubs == 0 {
 		return
 	}
 
-	// Generate and send the notification.
-	n := Notification{Type: typ, Data: data}
-	b.notifications(&n)
+	n := &Notification{Type: typ, Data: data}
+	if b.notificationQueue != nil {
+		b.notificationQueue.enqueue(b, n)
+		return
+	}
+	b.deliverNotification(n)
+}
+
+// deliverNotification invokes all callbacks which are subscribed to
+// notifications with the passed notification.
+func (b *BlockChain) deliverNotification(n *Notification) {
+	b.notificationsLock.RLock()
+	subs := b.notifications
+	b.notificationsLock.RUnlock()
+
+	// Send the notification to each subscriber.  The lock is not held
+	// while a callback is invoked so callbacks are free to subscribe and
+	// unsubscribe.  Callbacks that are unsubscribed while the notification
+	// is being sent, including by an earlier callback, are skipped.
+	for _, sub := range subs {
+		b.notificationsLock.RLock()
+		unsubscribed := sub.unsubscribed
+		b.notificationsLock.RUnlock()
+		if unsubscribed {
+			continue
+		}
+		if sub.filter != nil && !sub.filter(n) {
+			continue
+		}
+
+		sub.callback(n)
+	}
 }
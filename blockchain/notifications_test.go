@@ -0,0 +1,113 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// asyncNotificationHarness provides a chain instance with asynchronous
+// notification delivery enabled along with a subscriber which records the
+// data of the notifications it receives.  The subscriber signals when it is
+// invoked and then blocks until it is released.
+type asyncNotificationHarness struct {
+	chain    *BlockChain
+	started  chan struct{}
+	release  chan struct{}
+	mtx      sync.Mutex
+	received []int
+}
+
+// newAsyncNotificationHarness returns a new harness with an asynchronous
+// notification queue of the passed size and policy.
+func newAsyncNotificationHarness(queueSize int, dropWhenFull bool) *asyncNotificationHarness {
+	h := &asyncNotificationHarness{
+		chain: &BlockChain{
+			notificationQueue: newNotificationQueue(queueSize,
+				dropWhenFull),
+		},
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+	h.chain.Subscribe(func(n *Notification) {
+		h.started <- struct{}{}
+		<-h.release
+		h.mtx.Lock()
+		h.received = append(h.received, n.Data.(int))
+		h.mtx.Unlock()
+	})
+	return h
+}
+
+// TestAsyncNotifications ensures notifications are delivered in order by a
+// separate goroutine when asynchronous notification delivery is enabled and
+// that the queue full policies behave as expected.
+func TestAsyncNotifications(t *testing.T) {
+	t.Parallel()
+
+	// Ensure sending notifications does not wait for the subscriber and
+	// they are delivered in order.
+	h := newAsyncNotificationHarness(10, false)
+	for i := 0; i < 5; i++ {
+		h.chain.sendNotification(NTBlockConnected, i)
+	}
+	close(h.release)
+	h.chain.WaitForNotifications()
+	if want := []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(h.received, want) {
+		t.Fatalf("unexpected notifications - got %v, want %v",
+			h.received, want)
+	}
+
+	// Ensure notifications are dropped and counted once the queue is full
+	// when the drop policy is used.  The first notification is removed from
+	// the queue once the subscriber has been invoked with it.
+	h = newAsyncNotificationHarness(2, true)
+	h.chain.sendNotification(NTBlockConnected, 0)
+	<-h.started
+	for i := 1; i < 5; i++ {
+		h.chain.sendNotification(NTBlockConnected, i)
+	}
+	if dropped := h.chain.DroppedNotifications(); dropped != 2 {
+		t.Fatalf("unexpected dropped notifications - got %d, want 2",
+			dropped)
+	}
+	close(h.release)
+	h.chain.WaitForNotifications()
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(h.received, want) {
+		t.Fatalf("unexpected notifications - got %v, want %v",
+			h.received, want)
+	}
+
+	// Ensure sending a notification blocks until there is room in the
+	// queue when the queue is full and the block policy is used.
+	h = newAsyncNotificationHarness(1, false)
+	h.chain.sendNotification(NTBlockConnected, 0)
+	<-h.started
+	h.chain.sendNotification(NTBlockConnected, 1)
+	sent := make(chan struct{})
+	go func() {
+		h.chain.sendNotification(NTBlockConnected, 2)
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		t.Fatal("notification was sent while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(h.release)
+	<-sent
+	h.chain.WaitForNotifications()
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(h.received, want) {
+		t.Fatalf("unexpected notifications - got %v, want %v",
+			h.received, want)
+	}
+	if dropped := h.chain.DroppedNotifications(); dropped != 0 {
+		t.Fatalf("unexpected dropped notifications - got %d, want 0",
+			dropped)
+	}
+}
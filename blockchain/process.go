@@ -35,6 +35,20 @@ const (
 	// without modifying the current state.
 	BFDryRun
 
+	// BFQuiet may be set to indicate no notifications should be sent for
+	// any changes made to the chain while processing the block, including
+	// those for any orphans which are processed as a result.  The chain
+	// state is otherwise updated as usual.  This is useful to avoid
+	// flooding subscribers with notifications for historical blocks, such
+	// as when reindexing.
+	BFQuiet
+
+	// bfNoForkTooOldCheck may be set to indicate the check which rejects
+	// blocks that fork the main chain before the previous checkpoint will
+	// not be performed.  It is only used internally when verifying blocks
+	// which are already part of the main chain.
+	bfNoForkTooOldCheck
+
 	// BFNone is a convenience value to specifically indicate no flags.
 	BFNone BehaviorFlags = 0
 )
@@ -131,16 +145,50 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block, flags BehaviorFlags) (bo
 	b.chainLock.Lock()
 	defer b.chainLock.Unlock()
 
+	isMainChain, isOrphan, err := b.processBlock(block, flags)
+
+	// Don't retain any utxo view from a dry run since it is only used by
+	// ProcessBlockDryRun.
+	b.dryRunView, b.dryRunStxos = nil, nil
+
+	return isMainChain, isOrphan, err
+}
+
+// processBlock is the internal implementation of ProcessBlock.  See its
+// documentation for details.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) processBlock(block *dcrutil.Block, flags BehaviorFlags) (bool, bool, error) {
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	dryRun := flags&BFDryRun == BFDryRun
 
+	// Suppress all notifications for the duration of the call when
+	// requested.  Notifications are not sent with the chain lock released
+	// while they are suppressed, so no other callers can observe the flag.
+	if flags&BFQuiet == BFQuiet {
+		b.quietNotifications = true
+		defer func() {
+			b.quietNotifications = false
+		}()
+	}
+
 	blockHash := block.Hash()
-	log.Tracef("Processing block %v", blockHash)
+	if !b.quietProcessLog {
+		log.Tracef("Processing block %v", blockHash)
+	}
 	currentTime := time.Now()
 	defer func() {
 		elapsedTime := time.Since(currentTime)
-		log.Debugf("Block %v (height %v) finished processing in %s",
-			blockHash, block.Height(), elapsedTime)
+		if !b.quietProcessLog {
+			log.Debugf("Block %v (height %v) finished processing "+
+				"in %s", blockHash, block.Height(), elapsedTime)
+		}
+		logEvent("block processed", "hash", blockHash.String(),
+			"height", block.Height(), "elapsed", elapsedTime)
+		if b.blockProcessedHook != nil {
+			b.blockProcessedHook(blockHash, block.Height(),
+				elapsedTime)
+		}
 	}()
 
 	// The block must not already exist in the main chain or side chains.
@@ -160,11 +208,29 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block, flags BehaviorFlags) (bo
 	}
 
 	// Perform preliminary sanity checks on the block and its transactions.
-	err = checkBlockSanity(block, b.timeSource, flags, b.chainParams)
+	overrider := b.ruleOverrider(block)
+	err = checkBlockSanity(block, b.timeSource, flags, b.chainParams,
+		overrider)
+	if err != nil {
+		return false, false, err
+	}
+	err = overrider.check(b.checkBlockSizeLimit(block))
 	if err != nil {
 		return false, false, err
 	}
 
+	// Apply any additional local policy provided by the caller.
+	if b.preAcceptFilter != nil {
+		if err := b.preAcceptFilter(block); err != nil {
+			if _, ok := err.(RuleError); !ok {
+				str := fmt.Sprintf("block %v rejected by policy: %v",
+					blockHash, err)
+				err = ruleError(ErrRejectedByPolicy, str)
+			}
+			return false, false, err
+		}
+	}
+
 	// Find the previous checkpoint and perform some additional checks based
 	// on the checkpoint.  This provides a few nice properties such as
 	// preventing old side chain blocks before the last checkpoint,
@@ -240,7 +306,12 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block, flags BehaviorFlags) (bo
 			return false, false, err
 		}
 
-		log.Debugf("Accepted block %v", blockHash)
+		if !b.quietProcessLog {
+			log.Debugf("Accepted block %v", blockHash)
+		}
+		elapsed := time.Since(currentTime)
+		b.updateBlockProcessTime(elapsed)
+		b.processLatencies.add(elapsed)
 	}
 
 	return isMainChain, false, nil
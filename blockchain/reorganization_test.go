@@ -11,11 +11,13 @@ import (
 	"encoding/gob"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/decred/dcrd/blockchain"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
 )
 
@@ -60,11 +62,17 @@ func reorgTestLong(t *testing.T, params *chaincfg.Params) {
 
 	// Load up the short chain
 	finalIdx1 := 179
+	var sideTipHash *chainhash.Hash
+	var sideHashes []chainhash.Hash
+	var sideBlocks []*dcrutil.Block
 	for i := 1; i < finalIdx1+1; i++ {
 		bl, err := dcrutil.NewBlockFromBytes(blockChain[int64(i)])
 		if err != nil {
 			t.Fatalf("NewBlockFromBytes error: %v", err.Error())
 		}
+		sideTipHash = bl.Hash()
+		sideHashes = append(sideHashes, *sideTipHash)
+		sideBlocks = append(sideBlocks, bl)
 
 		_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
 		if err != nil {
@@ -96,6 +104,22 @@ func reorgTestLong(t *testing.T, params *chaincfg.Params) {
 		t.Errorf("error decoding test blockchain: %v", err.Error())
 	}
 
+	// Subscribe to notifications for all blocks as well as only those for
+	// the main chain in order to ensure the blocks which are only added to
+	// a side chain are filtered.
+	var allAccepted, mainAccepted []chainhash.Hash
+	recordAccepted := func(accepted *[]chainhash.Hash) blockchain.NotificationCallback {
+		return func(n *blockchain.Notification) {
+			if n.Type != blockchain.NTBlockAccepted {
+				return
+			}
+			data := n.Data.(*blockchain.BlockAcceptedNtfnsData)
+			*accepted = append(*accepted, *data.Block.Hash())
+		}
+	}
+	chain.Subscribe(recordAccepted(&allAccepted))
+	chain.SubscribeMainChain(recordAccepted(&mainAccepted))
+
 	forkPoint := 131
 	finalIdx2 := 180
 	for i := forkPoint; i < finalIdx2+1; i++ {
@@ -128,6 +152,111 @@ func reorgTestLong(t *testing.T, params *chaincfg.Params) {
 			"after reorg test: %v", err)
 	}
 
+	// Ensure every block of the long chain was reported to the unfiltered
+	// subscription while only the block which caused the reorganization
+	// was reported to the main chain subscription.
+	if len(allAccepted) != finalIdx2-forkPoint+1 {
+		t.Errorf("unexpected number of accepted blocks - got %d, want %d",
+			len(allAccepted), finalIdx2-forkPoint+1)
+	}
+	wantMainAccepted := []chainhash.Hash{*expected}
+	if !reflect.DeepEqual(mainAccepted, wantMainAccepted) {
+		t.Errorf("unexpected main chain accepted blocks - got %v, want %v",
+			mainAccepted, wantMainAccepted)
+	}
+
+	// Ensure the tips of both the new main chain and the old chain, which
+	// forks from the main chain after block 130, are reported.
+	wantTips := []blockchain.ChainTip{{
+		Height:    180,
+		Hash:      *expected,
+		BranchLen: 0,
+		Status:    blockchain.ChainTipActive,
+	}, {
+		Height:    179,
+		Hash:      *sideTipHash,
+		BranchLen: 49,
+		Status:    blockchain.ChainTipValidFork,
+	}}
+	tips := chain.ChainTips()
+	if !reflect.DeepEqual(tips, wantTips) {
+		t.Errorf("unexpected chain tips - got %v, want %v", tips,
+			wantTips)
+	}
+
+	// Ensure the blocks of the old chain after the fork point are returned
+	// in order for its tip and an error is returned for the main chain
+	// tip since it is not a side chain tip.
+	blocks, err := chain.SideChainBlocks(sideTipHash)
+	if err != nil {
+		t.Fatalf("SideChainBlocks: unexpected error: %v", err)
+	}
+	wantBlocks := sideHashes[forkPoint-1:]
+	if !reflect.DeepEqual(blocks, wantBlocks) {
+		t.Errorf("unexpected side chain blocks - got %v, want %v",
+			blocks, wantBlocks)
+	}
+	if _, err := chain.SideChainBlocks(expected); err == nil {
+		t.Errorf("SideChainBlocks: did not receive expected error for " +
+			"main chain tip")
+	}
+	if _, err := chain.SideChainBlocks(&sideHashes[forkPoint]); err == nil {
+		t.Errorf("SideChainBlocks: did not receive expected error for " +
+			"side chain block which is not a tip")
+	}
+
+	// Ensure a block of the old chain is valid when checked against its
+	// side chain parent and is rejected when checked against any other
+	// block.
+	sideBlock := sideBlocks[forkPoint]
+	sideParent := sideBlock.MsgBlock().Header.PrevBlock
+	err = chain.CheckConnectBlockToParent(sideBlock, &sideParent,
+		blockchain.BFNone)
+	if err != nil {
+		t.Errorf("CheckConnectBlockToParent: unexpected error: %v", err)
+	}
+	err = chain.CheckConnectBlockToParent(sideBlock, expected,
+		blockchain.BFNone)
+	if err == nil {
+		t.Errorf("CheckConnectBlockToParent: did not receive expected " +
+			"error for mismatched parent")
+	}
+
+	// Ensure the stake tree of the same side chain block is valid when
+	// checked against its parent as well as that of the main chain tip,
+	// and that a stake tree with an unexpected stake difficulty is
+	// rejected.
+	err = chain.CheckStakeTree(sideBlock, &sideParent)
+	if err != nil {
+		t.Errorf("CheckStakeTree: unexpected error: %v", err)
+	}
+	if err := chain.CheckStakeTree(sideBlock, expected); err == nil {
+		t.Errorf("CheckStakeTree: did not receive expected error for " +
+			"mismatched parent")
+	}
+	tipBlock, err := dcrutil.NewBlockFromBytes(blockChain[int64(finalIdx2)])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err.Error())
+	}
+	tipParent := tipBlock.MsgBlock().Header.PrevBlock
+	err = chain.CheckStakeTree(tipBlock, &tipParent)
+	if err != nil {
+		t.Errorf("CheckStakeTree: unexpected error for tip: %v", err)
+	}
+	badHeader := tipBlock.MsgBlock().Header
+	badHeader.SBits++
+	badMsgBlock := wire.NewMsgBlock(&badHeader)
+	badMsgBlock.Transactions = tipBlock.MsgBlock().Transactions
+	badMsgBlock.STransactions = tipBlock.MsgBlock().STransactions
+	err = chain.CheckStakeTree(dcrutil.NewBlock(badMsgBlock), &tipParent)
+	if rerr, ok := err.(blockchain.RuleError); !ok ||
+		rerr.ErrorCode != blockchain.ErrUnexpectedDifficulty {
+
+		t.Errorf("CheckStakeTree: unexpected error for bad stake "+
+			"difficulty - got %v, want %v", err,
+			blockchain.ErrUnexpectedDifficulty)
+	}
+
 	return
 }
 
@@ -247,6 +376,65 @@ func reorgTestShort(t *testing.T, params *chaincfg.Params) {
 			"after reorg test: %v", err)
 	}
 
+	// Ensure script validation was skipped for blocks that were reconnected
+	// after having already been validated.
+	if chain.ValidatedBlockCacheHits() == 0 {
+		t.Errorf("no validated block cache hits after reorganizations")
+	}
+
+	// Find the fork point of the two test chains, which is the last block
+	// that is identical in both of them.
+	blockHash := func(blocks map[int64][]byte, height int64) *chainhash.Hash {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err.Error())
+		}
+		return bl.Hash()
+	}
+	forkHeight := int64(0)
+	for i := int64(1); i <= int64(forkPoint); i++ {
+		if *blockHash(blockChain1, i) != *blockHash(blockChain2, i) {
+			break
+		}
+		forkHeight = i
+	}
+
+	// Ensure the common ancestor is found for blocks on the same and on
+	// different branches.
+	forkHash := blockHash(blockChain1, forkHeight)
+	sideHash := blockHash(blockChain1, 170)
+	mainHash := blockHash(blockChain2, 100)
+	tests := []struct {
+		name string
+		a, b *chainhash.Hash
+		want *chainhash.Hash
+	}{
+		{"same block", tipHash, tipHash, tipHash},
+		{"main chain ancestor", tipHash, mainHash, mainHash},
+		{"main chain ancestor reversed", mainHash, tipHash, mainHash},
+		{"side chain and main chain tip", sideHash, tipHash, forkHash},
+		{"main chain tip and side chain", tipHash, sideHash, forkHash},
+		{"side chain and fork point", sideHash, forkHash, forkHash},
+	}
+	for i, test := range tests {
+		got, err := chain.CommonAncestor(test.a, test.b)
+		if err != nil {
+			t.Errorf("CommonAncestor #%d (%s): unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if *got != *test.want {
+			t.Errorf("CommonAncestor #%d (%s): got %v, want %v", i,
+				test.name, got, test.want)
+		}
+	}
+
+	// Ensure an unknown block is rejected.
+	if _, err := chain.CommonAncestor(tipHash, &chainhash.Hash{}); err == nil {
+		t.Errorf("CommonAncestor: did not receive expected error for " +
+			"unknown block")
+	}
+
 	return
 }
 
@@ -364,6 +552,189 @@ func reorgTestForced(t *testing.T, params *chaincfg.Params) {
 	return
 }
 
+// loadTestBlocks returns the serialized blocks, keyed by height, from the
+// passed bzip2 compressed gob encoded test data file.
+func loadTestBlocks(filename string) (map[int64][]byte, error) {
+	fi, err := os.Open(filepath.Join("testdata/", filename))
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+
+	var blocks map[int64][]byte
+	bcDecoder := gob.NewDecoder(bzip2.NewReader(fi))
+	if err := bcDecoder.Decode(&blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// reorgTestTieBreak ensures the tip of the main chain is selected as expected
+// when two competing tips have the same cumulative work.
+func reorgTestTieBreak(t *testing.T, params *chaincfg.Params) {
+	chain1, err := loadTestBlocks("reorgto179.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	chain2, err := loadTestBlocks("reorgto180.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+
+	// The two test chains share their blocks prior to the fork point and
+	// the blocks at the fork point both have the same work.  The block
+	// from the first chain has the lower hash.
+	const forkPoint = 131
+	lowHash := mustParseHash("073267f453a13b81719650a5ac607d38f80bc804ea" +
+		"41052dc2090ce180fccd08")
+	highHash := mustParseHash("0df603f434be1dca22d706c7c47be16a8edcef2f15" +
+		"1bcf08b51138aa1cda26e2")
+
+	tests := []struct {
+		name         string
+		lowestHash   bool
+		lowHashFirst bool
+		wantTipIsLow bool
+	}{
+		{"first seen, low hash first", false, true, true},
+		{"first seen, high hash first", false, false, false},
+		{"lowest hash, low hash first", true, true, true},
+		{"lowest hash, high hash first", true, false, true},
+	}
+	for i, test := range tests {
+		chain, teardownFunc, err := chainSetupWithConfig("reorgunittesttie",
+			params, blockchain.Config{PreferLowestHashTip: test.lowestHash})
+		if err != nil {
+			t.Fatalf("Failed to setup chain instance: %v", err)
+		}
+
+		// Load the shared blocks followed by both competing tips in the
+		// order specified by the test.
+		processBlock := func(blocks map[int64][]byte, height int64) {
+			bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+			if err != nil {
+				t.Fatalf("NewBlockFromBytes error: %v", err)
+			}
+			_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+			if err != nil {
+				t.Fatalf("Test #%d (%s): ProcessBlock error at "+
+					"height %d: %v", i, test.name, height, err)
+			}
+		}
+		for height := int64(1); height < forkPoint; height++ {
+			processBlock(chain1, height)
+		}
+		if test.lowHashFirst {
+			processBlock(chain1, forkPoint)
+			processBlock(chain2, forkPoint)
+		} else {
+			processBlock(chain2, forkPoint)
+			processBlock(chain1, forkPoint)
+		}
+
+		want := highHash
+		if test.wantTipIsLow {
+			want = lowHash
+		}
+		tipHash := chain.BestSnapshot().Hash
+		teardownFunc()
+		if *tipHash != *want {
+			t.Errorf("Test #%d (%s): unexpected tip - got %v, want %v",
+				i, test.name, tipHash, want)
+		}
+	}
+}
+
+// reorgTestMaxDepth ensures blocks which would cause a reorganize deeper than
+// the configured maximum reorganize depth are rejected.
+func reorgTestMaxDepth(t *testing.T, params *chaincfg.Params) {
+	chain1, err := loadTestBlocks("reorgto179.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	chain2, err := loadTestBlocks("reorgto180.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+
+	// The second test chain forks from the first one after block 130, so
+	// the block at height 180 of the second chain causes a reorganize which
+	// disconnects 49 blocks.  The first seen tip is kept for the blocks of
+	// the second chain which have the same work as the main chain.
+	const forkPoint = 131
+	tests := []struct {
+		name     string
+		maxDepth int64
+		wantErr  bool
+	}{
+		{"depth exceeds maximum", 48, true},
+		{"depth equals maximum", 49, false},
+	}
+	for i, test := range tests {
+		chain, teardownFunc, err := chainSetupWithConfig(
+			"reorgunittestmaxdepth", params, blockchain.Config{
+				MaxReorgDepth: test.maxDepth,
+			})
+		if err != nil {
+			t.Fatalf("Failed to setup chain instance: %v", err)
+		}
+
+		processBlock := func(blocks map[int64][]byte, height int64) (*chainhash.Hash, error) {
+			bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+			if err != nil {
+				t.Fatalf("NewBlockFromBytes error: %v", err)
+			}
+			_, _, err = chain.ProcessBlock(bl, blockchain.BFNone)
+			return bl.Hash(), err
+		}
+		for height := int64(1); height <= 179; height++ {
+			if _, err := processBlock(chain1, height); err != nil {
+				t.Fatalf("Test #%d (%s): ProcessBlock error at "+
+					"height %d: %v", i, test.name, height, err)
+			}
+		}
+		oldTip := chain.BestSnapshot().Hash
+		for height := int64(forkPoint); height < 180; height++ {
+			if _, err := processBlock(chain2, height); err != nil {
+				t.Fatalf("Test #%d (%s): ProcessBlock error at "+
+					"height %d: %v", i, test.name, height, err)
+			}
+		}
+
+		// Ensure the block which causes the reorganize is rejected when
+		// the reorganize is too deep and the main chain is unchanged.
+		newTip, err := processBlock(chain2, 180)
+		tip := chain.BestSnapshot().Hash
+		haveNewTip, _ := chain.HaveBlock(newTip)
+		teardownFunc()
+		if !test.wantErr {
+			if err != nil {
+				t.Errorf("Test #%d (%s): unexpected error: %v", i,
+					test.name, err)
+			} else if *tip != *newTip {
+				t.Errorf("Test #%d (%s): unexpected tip - got "+
+					"%v, want %v", i, test.name, tip, newTip)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != blockchain.ErrReorgTooDeep {
+			t.Errorf("Test #%d (%s): unexpected error - got %v, "+
+				"want %v", i, test.name, err,
+				blockchain.ErrReorgTooDeep)
+			continue
+		}
+		if *tip != *oldTip {
+			t.Errorf("Test #%d (%s): unexpected tip - got %v, want "+
+				"%v", i, test.name, tip, oldTip)
+		}
+		if haveNewTip {
+			t.Errorf("Test #%d (%s): rejected block %v is still "+
+				"known", i, test.name, newTip)
+		}
+	}
+}
+
 // TestReorganization loads a set of test blocks which force a chain
 // reorganization to test the block chain handling code.
 func TestReorganization(t *testing.T) {
@@ -377,4 +748,6 @@ func TestReorganization(t *testing.T) {
 	reorgTestLong(t, params)
 	reorgTestShort(t, params)
 	reorgTestForced(t, params)
+	reorgTestTieBreak(t, params)
+	reorgTestMaxDepth(t, params)
 }
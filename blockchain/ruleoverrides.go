@@ -0,0 +1,64 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/decred/dcrutil"
+)
+
+// RuleOverrideFunc defines the signature of a function which overrides the
+// result of a failed consensus rule check.  It is invoked with the block which
+// failed the check and the resulting rule error.  Returning nil accepts the
+// block as if the check passed and validation continues with the remaining
+// checks, while returning a non-nil error, such as the passed rule error,
+// rejects the block with that error.
+//
+// It is invoked with the chain lock held, so it MUST NOT call back into the
+// chain instance.
+type RuleOverrideFunc func(block *dcrutil.Block, err RuleError) error
+
+// ruleOverrider houses the rule overrides which are consulted for the failed
+// consensus rule checks of a specific block.  A nil ruleOverrider consults no
+// overrides, so every failed check rejects the block.
+type ruleOverrider struct {
+	overrides map[ErrorCode]RuleOverrideFunc
+	block     *dcrutil.Block
+}
+
+// ruleOverrider returns a ruleOverrider which consults the rule overrides of
+// the chain instance for the passed block.  It returns nil when no overrides
+// are configured.
+func (b *BlockChain) ruleOverrider(block *dcrutil.Block) *ruleOverrider {
+	if len(b.ruleOverrides) == 0 {
+		return nil
+	}
+	return &ruleOverrider{overrides: b.ruleOverrides, block: block}
+}
+
+// check returns the result of the rule override registered for the error code
+// of the passed error when it is a RuleError and there is such an override.
+// Otherwise, the passed error is returned unmodified.  It is intended to be
+// invoked with the result of each individual check so that accepting a failed
+// check via an override does not skip any of the remaining checks.
+func (o *ruleOverrider) check(err error) error {
+	if o == nil || err == nil {
+		return err
+	}
+	rErr, ok := err.(RuleError)
+	if !ok {
+		return err
+	}
+	override, ok := o.overrides[rErr.ErrorCode]
+	if !ok {
+		return err
+	}
+
+	newErr := override(o.block, rErr)
+	if newErr == nil {
+		log.Warnf("Rule override accepted block %v which failed %v: %v",
+			o.block.Hash(), rErr.ErrorCode, rErr.Description)
+	}
+	return newErr
+}
@@ -22,13 +22,22 @@ type txValidateItem struct {
 	tx        *dcrutil.Tx
 }
 
+// txValidateResult houses the result of validating the item at a given index
+// of the items being validated.
+type txValidateResult struct {
+	index int
+	err   error
+}
+
 // txValidator provides a type which asynchronously validates transaction
 // inputs.  It provides several channels for communication and a processing
 // function that is intended to be in run multiple goroutines.
 type txValidator struct {
-	validateChan chan *txValidateItem
+	validateChan chan int
 	quitChan     chan struct{}
-	resultChan   chan error
+	resultChan   chan txValidateResult
+	items        []*txValidateItem
+	maxWorkers   int
 	utxoView     *UtxoViewpoint
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
@@ -38,9 +47,9 @@ type txValidator struct {
 // result channel while respecting the quit channel.  The allows orderly
 // shutdown when the validation process is aborted early due to a validation
 // error in one of the other goroutines.
-func (v *txValidator) sendResult(result error) {
+func (v *txValidator) sendResult(index int, err error) {
 	select {
-	case v.resultChan <- result:
+	case v.resultChan <- txValidateResult{index, err}:
 	case <-v.quitChan:
 	}
 }
@@ -52,7 +61,9 @@ func (v *txValidator) validateHandler() {
 out:
 	for {
 		select {
-		case txVI := <-v.validateChan:
+		case index := <-v.validateChan:
+			txVI := v.items[index]
+
 			// Ensure the referenced input transaction is available.
 			txIn := txVI.txIn
 			originTxHash := &txIn.PreviousOutPoint.Hash
@@ -64,7 +75,7 @@ out:
 					"transaction %v", originTxHash,
 					txVI.tx.Hash())
 				err := ruleError(ErrMissingTx, str)
-				v.sendResult(err)
+				v.sendResult(index, err)
 				break out
 			}
 
@@ -78,7 +89,7 @@ out:
 					txIn.PreviousOutPoint, txVI.tx.Hash(),
 					txVI.txInIndex)
 				err := ruleError(ErrBadTxInput, str)
-				v.sendResult(err)
+				v.sendResult(index, err)
 				break out
 			}
 
@@ -96,7 +107,7 @@ out:
 					txVI.txInIndex, originTxHash,
 					originTxIndex, err, sigScript, pkScript)
 				err := ruleError(ErrScriptMalformed, str)
-				v.sendResult(err)
+				v.sendResult(index, err)
 				break out
 			}
 
@@ -109,12 +120,12 @@ out:
 					txVI.txInIndex, originTxHash,
 					originTxIndex, err, sigScript, pkScript)
 				err := ruleError(ErrScriptValidation, str)
-				v.sendResult(err)
+				v.sendResult(index, err)
 				break out
 			}
 
 			// Validation succeeded.
-			v.sendResult(nil)
+			v.sendResult(index, nil)
 
 		case <-v.quitChan:
 			break out
@@ -124,15 +135,24 @@ out:
 
 // Validate validates the scripts for all of the passed transaction inputs using
 // multiple goroutines.
+//
+// When any of the inputs fail to validate, the error for the failing input
+// with the lowest index in the passed items is returned regardless of the
+// order the goroutines finish in, so the reported error is deterministic.
 func (v *txValidator) Validate(items []*txValidateItem) error {
 	if len(items) == 0 {
 		return nil
 	}
+	v.items = items
 
-	// Limit the number of goroutines to do script validation based on the
-	// number of processor cores.  This help ensure the system stays
+	// Limit the number of goroutines to do script validation to the
+	// configured maximum or, when not configured, based on the number of
+	// processors usable by the runtime.  This help ensure the system stays
 	// reasonably responsive under heavy load.
-	maxGoRoutines := runtime.NumCPU() * 3
+	maxGoRoutines := v.maxWorkers
+	if maxGoRoutines <= 0 {
+		maxGoRoutines = runtime.GOMAXPROCS(0) * 3
+	}
 	if maxGoRoutines <= 0 {
 		maxGoRoutines = 1
 	}
@@ -146,47 +166,57 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 		go v.validateHandler()
 	}
 
-	// Validate each of the inputs.  The quit channel is closed when any
-	// errors occur so all processing goroutines exit regardless of which
-	// input had the validation error.
+	// Validate each of the inputs.  Items are sent for processing in order
+	// and no further items are sent once any errors occur.  Since every
+	// item before a failing one has already been sent at that point, only
+	// the items that are still in flight need to be waited on in order to
+	// determine the failing input with the lowest index.  The quit channel
+	// is closed once done so all processing goroutines exit.
 	numInputs := len(items)
 	currentItem := 0
-	processedItems := 0
-	for processedItems < numInputs {
+	inFlight := 0
+	var firstErr *txValidateResult
+	for inFlight > 0 || (currentItem < numInputs && firstErr == nil) {
 		// Only send items while there are still items that need to
-		// be processed.  The select statement will never select a nil
-		// channel.
-		var validateChan chan *txValidateItem
-		var item *txValidateItem
-		if currentItem < numInputs {
+		// be processed and no errors have occurred.  The select
+		// statement will never select a nil channel.
+		var validateChan chan int
+		if currentItem < numInputs && firstErr == nil {
 			validateChan = v.validateChan
-			item = items[currentItem]
 		}
 
 		select {
-		case validateChan <- item:
+		case validateChan <- currentItem:
 			currentItem++
+			inFlight++
 
-		case err := <-v.resultChan:
-			processedItems++
-			if err != nil {
-				close(v.quitChan)
-				return err
+		case result := <-v.resultChan:
+			inFlight--
+			if result.err != nil && (firstErr == nil ||
+				result.index < firstErr.index) {
+
+				firstErr = &result
 			}
 		}
 	}
 
 	close(v.quitChan)
+	if firstErr != nil {
+		return firstErr.err
+	}
 	return nil
 }
 
 // newTxValidator returns a new instance of txValidator to be used for
-// validating transaction scripts asynchronously.
-func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache) *txValidator {
+// validating transaction scripts asynchronously.  The maximum number of
+// goroutines used is limited to the passed value when it is greater than zero
+// or a default based on the number of processors otherwise.
+func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache, maxWorkers int) *txValidator {
 	return &txValidator{
-		validateChan: make(chan *txValidateItem),
+		validateChan: make(chan int),
 		quitChan:     make(chan struct{}),
-		resultChan:   make(chan error),
+		resultChan:   make(chan txValidateResult),
+		maxWorkers:   maxWorkers,
 		utxoView:     utxoView,
 		sigCache:     sigCache,
 		flags:        flags,
@@ -215,15 +245,17 @@ func ValidateTransactionScripts(tx *dcrutil.Tx, utxoView *UtxoViewpoint, flags t
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, flags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, flags, sigCache, 0).Validate(txValItems)
 
 }
 
 // checkBlockScripts executes and validates the scripts for all transactions in
-// the passed block using multiple goroutines.
+// the passed block using up to the passed maximum number of goroutines, or a
+// default based on the number of processors when it is zero.
 // txTree = true is TxTreeRegular, txTree = false is TxTreeStake.
 func checkBlockScripts(block *dcrutil.Block, utxoView *UtxoViewpoint, txTree bool,
-	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache) error {
+	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
+	maxWorkers int) error {
 
 	// Collect all of the transaction inputs and required information for
 	// validation for all transactions in the block into a single slice.
@@ -258,5 +290,6 @@ func checkBlockScripts(block *dcrutil.Block, utxoView *UtxoViewpoint, txTree boo
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, scriptFlags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, scriptFlags, sigCache,
+		maxWorkers).Validate(txValItems)
 }
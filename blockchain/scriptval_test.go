@@ -8,10 +8,15 @@ package blockchain_test
 //	"fmt"
 //	"runtime"
 import (
+	"strings"
 	"testing"
+
+	"github.com/decred/dcrd/blockchain"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
 )
 
-//	"github.com/decred/dcrd/blockchain"
 //	"github.com/decred/dcrd/txscript"
 
 // TestCheckBlockScripts ensures that validating the all of the scripts in a
@@ -55,3 +60,36 @@ func TestCheckBlockScripts(t *testing.T) {
 		}
 	*/
 }
+
+// TestValidateScriptsDeterministicError ensures that when multiple inputs fail
+// to validate, the error for the input with the lowest index is the one that
+// is reported regardless of the order the validation goroutines finish in.
+func TestValidateScriptsDeterministicError(t *testing.T) {
+	// Create a transaction with many inputs that all reference outputs
+	// which are not in the utxo view and therefore all fail validation.
+	const numInputs = 50
+	tx := wire.NewMsgTx()
+	for i := 0; i < numInputs; i++ {
+		prevOut := wire.NewOutPoint(&chainhash.Hash{byte(i + 1)}, 0,
+			wire.TxTreeRegular)
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(0, nil))
+
+	// Ensure the error always refers to the first input.
+	wantHash := tx.TxIn[0].PreviousOutPoint.Hash.String()
+	view := blockchain.NewUtxoViewpoint()
+	for i := 0; i < 20; i++ {
+		err := blockchain.ValidateTransactionScripts(dcrutil.NewTx(tx),
+			view, 0, nil)
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != blockchain.ErrMissingTx {
+			t.Fatalf("ValidateTransactionScripts: unexpected error "+
+				"- got %v, want %v", err, blockchain.ErrMissingTx)
+		}
+		if !strings.Contains(rerr.Description, wantHash) {
+			t.Fatalf("ValidateTransactionScripts: error does not "+
+				"refer to the first input - got %q", rerr.Description)
+		}
+	}
+}
@@ -6,6 +6,8 @@
 package blockchain
 
 import (
+	"fmt"
+
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/database"
 	"github.com/decred/dcrd/txscript"
@@ -246,3 +248,59 @@ func (b *BlockChain) TicketPoolValue() (dcrutil.Amount, error) {
 	}
 	return dcrutil.Amount(amt), nil
 }
+
+// StakePoolSize houses the size of the live ticket pool as of a given block
+// along with the number of tickets which were purchased in the blocks leading
+// up to it that have not yet matured.
+type StakePoolSize struct {
+	Hash            chainhash.Hash
+	Height          int64
+	PoolSize        uint32
+	ImmatureTickets int64
+}
+
+// StakePoolSizes returns the ticket pool size and number of immature tickets
+// for the current best block and up to count-1 of its ancestors, ordered from
+// the best block backwards.  The sum of the two values for an entry is the
+// total number of tickets mined that have not yet been spent or revoked as of
+// that block, which is the pool size used by the stake difficulty algorithm.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) StakePoolSizes(count int32) ([]StakePoolSize, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("count must be greater than zero - "+
+			"got %d", count)
+	}
+
+	// The chain lock is held for writes since previous block nodes are
+	// dynamically loaded as needed.
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if int64(count) > b.bestNode.height+1 {
+		count = int32(b.bestNode.height + 1)
+	}
+
+	ticketMaturity := int64(b.chainParams.TicketMaturity)
+	result := make([]StakePoolSize, 0, count)
+	node := b.bestNode
+	for i := int32(0); node != nil && i < count; i++ {
+		immature, err := b.sumPurchasedTickets(node, ticketMaturity)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, StakePoolSize{
+			Hash:            node.hash,
+			Height:          node.height,
+			PoolSize:        node.header.PoolSize,
+			ImmatureTickets: immature,
+		})
+
+		node, err = b.getPrevNodeFromNode(node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -6,6 +6,7 @@ package blockchain
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -828,3 +829,176 @@ func TestLarge(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcNextBlockVersion ensures the minimum version required for the next
+// block is the newest version a majority of the recent blocks have upgraded to.
+func TestCalcNextBlockVersion(t *testing.T) {
+	simnet := &chaincfg.SimNetParams
+	mainnet := &chaincfg.MainNetParams
+
+	tests := []struct {
+		name          string
+		params        *chaincfg.Params
+		numUpgraded   uint64
+		upgradedVer   int32
+		expectVersion int32
+	}{{
+		name:          "no upgrades",
+		params:        simnet,
+		expectVersion: 1,
+	}, {
+		name:          "one short of majority",
+		params:        simnet,
+		numUpgraded:   simnet.BlockRejectNumRequired - 1,
+		upgradedVer:   4,
+		expectVersion: 1,
+	}, {
+		name:          "majority version 4",
+		params:        simnet,
+		numUpgraded:   simnet.BlockRejectNumRequired,
+		upgradedVer:   4,
+		expectVersion: 4,
+	}, {
+		name:          "majority version 6 simnet",
+		params:        simnet,
+		numUpgraded:   simnet.BlockRejectNumRequired,
+		upgradedVer:   6,
+		expectVersion: 6,
+	}, {
+		name:          "majority version 6 mainnet",
+		params:        mainnet,
+		numUpgraded:   mainnet.BlockRejectNumRequired,
+		upgradedVer:   6,
+		expectVersion: 5,
+	}}
+
+	for i, test := range tests {
+		// Create a chain with enough blocks to fill the upgrade window
+		// where the most recent blocks have the upgraded version.
+		bc := newFakeChain(test.params)
+		node := bc.bestNode
+		numNodes := test.params.BlockUpgradeNumToCheck
+		for j := uint64(0); j < numNodes; j++ {
+			version := int32(1)
+			if j >= numNodes-test.numUpgraded {
+				version = test.upgradedVer
+			}
+			node = newFakeNode(node, version, 0, 0, time.Now())
+			bc.bestNode = node
+		}
+
+		version := bc.CalcNextBlockVersion()
+		if version != test.expectVersion {
+			t.Errorf("Test #%d (%s) unexpected version - got %d, "+
+				"want %d", i, test.name, version,
+				test.expectVersion)
+		}
+	}
+}
+
+// TestIsBlockVersionTooOld ensures blocks are only rejected by the block
+// version upgrade rules when a majority of the recent blocks have upgraded to
+// a newer version than the block.
+func TestIsBlockVersionTooOld(t *testing.T) {
+	simnet := &chaincfg.SimNetParams
+	mainnet := &chaincfg.MainNetParams
+
+	tests := []struct {
+		name         string
+		params       *chaincfg.Params
+		numUpgraded  uint64
+		upgradedVer  int32
+		blockVersion int32
+		expected     bool
+	}{{
+		name:         "no upgrades",
+		params:       simnet,
+		blockVersion: 1,
+		expected:     false,
+	}, {
+		name:         "one short of majority",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired - 1,
+		upgradedVer:  4,
+		blockVersion: 3,
+		expected:     false,
+	}, {
+		name:         "older than majority version",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  4,
+		blockVersion: 3,
+		expected:     true,
+	}, {
+		name:         "zero version with majority version 4",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  4,
+		blockVersion: 0,
+		expected:     true,
+	}, {
+		name:         "same as majority version",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  4,
+		blockVersion: 4,
+		expected:     false,
+	}, {
+		name:         "newer than majority version",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  4,
+		blockVersion: 5,
+		expected:     false,
+	}, {
+		name:         "version 5 with majority version 6 simnet",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  6,
+		blockVersion: 5,
+		expected:     true,
+	}, {
+		name:         "version 5 with majority version 6 mainnet",
+		params:       mainnet,
+		numUpgraded:  mainnet.BlockRejectNumRequired,
+		upgradedVer:  6,
+		blockVersion: 5,
+		expected:     false,
+	}, {
+		name:         "version 4 with majority version 6 mainnet",
+		params:       mainnet,
+		numUpgraded:  mainnet.BlockRejectNumRequired,
+		upgradedVer:  6,
+		blockVersion: 4,
+		expected:     true,
+	}, {
+		name:         "max version",
+		params:       simnet,
+		numUpgraded:  simnet.BlockRejectNumRequired,
+		upgradedVer:  6,
+		blockVersion: math.MaxInt32,
+		expected:     false,
+	}}
+
+	for i, test := range tests {
+		// Create a chain with enough blocks to fill the upgrade window
+		// where the most recent blocks have the upgraded version.
+		bc := newFakeChain(test.params)
+		node := bc.bestNode
+		numNodes := test.params.BlockUpgradeNumToCheck
+		for j := uint64(0); j < numNodes; j++ {
+			version := int32(1)
+			if j >= numNodes-test.numUpgraded {
+				version = test.upgradedVer
+			}
+			node = newFakeNode(node, version, 0, 0, time.Now())
+			bc.bestNode = node
+		}
+
+		tooOld := bc.isBlockVersionTooOld(test.blockVersion, bc.bestNode)
+		if tooOld != test.expected {
+			t.Errorf("Test #%d (%s) unexpected result - got %v, "+
+				"want %v", i, test.name, tooOld, test.expected)
+		}
+	}
+}
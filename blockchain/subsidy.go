@@ -118,6 +118,122 @@ func (s *SubsidyCache) CalcBlockSubsidy(height int64) int64 {
 	return subsidy
 }
 
+// CalcWorkSubsidy returns the proof of work subsidy for a block at the
+// provided height with the given number of voters using the chain parameters
+// the cache was created with.  It is identical to CalcBlockWorkSubsidy.
+//
+// Safe for concurrent access.
+func (s *SubsidyCache) CalcWorkSubsidy(height int64, voters uint16) int64 {
+	return CalcBlockWorkSubsidy(s, height, voters, s.params)
+}
+
+// CalcStakeVoteSubsidy returns the subsidy for a single stake vote included in
+// a block at the provided height using the chain parameters the cache was
+// created with.  It is identical to the standalone CalcStakeVoteSubsidy.
+//
+// Safe for concurrent access.
+func (s *SubsidyCache) CalcStakeVoteSubsidy(height int64) int64 {
+	return CalcStakeVoteSubsidy(s, height, s.params)
+}
+
+// CalcTreasurySubsidy returns the subsidy paid to the organization (treasury)
+// address in the coinbase of a block at the provided height with the given
+// number of voters using the chain parameters the cache was created with.  It
+// is identical to CalcBlockTaxSubsidy.
+//
+// Safe for concurrent access.
+func (s *SubsidyCache) CalcTreasurySubsidy(height int64, voters uint16) int64 {
+	return CalcBlockTaxSubsidy(s, height, voters, s.params)
+}
+
+// SubsidySplit houses cumulative amounts of subsidy split by the recipients of
+// the subsidy.
+type SubsidySplit struct {
+	// Initial is the initial token distribution paid by block one.
+	Initial int64
+
+	// Work is the subsidy paid to proof of work miners.
+	Work int64
+
+	// Stake is the subsidy paid to the votes included in blocks.
+	Stake int64
+
+	// Treasury is the subsidy paid to the organization (treasury) address.
+	Treasury int64
+}
+
+// Total returns the sum of the subsidy paid to all recipients.
+func (s SubsidySplit) Total() int64 {
+	return s.Initial + s.Work + s.Stake + s.Treasury
+}
+
+// sumSubsidy returns the sum of the values returned by the passed per-block
+// subsidy function for every height in the passed inclusive range.  Since the
+// subsidy only changes at reduction intervals, the function is only evaluated
+// once per interval.  The range must not include the genesis block or block
+// one since their subsidy is special.
+func (s *SubsidyCache) sumSubsidy(start, end int64, calc func(height int64) int64) int64 {
+	interval := s.params.SubsidyReductionInterval
+	var total int64
+	for start <= end {
+		intervalEnd := (start/interval+1)*interval - 1
+		if intervalEnd > end {
+			intervalEnd = end
+		}
+		total += (intervalEnd - start + 1) * calc(start)
+		start = intervalEnd + 1
+	}
+	return total
+}
+
+// SubsidySplitToHeight returns the cumulative subsidy paid by all blocks from
+// the genesis block through the block at the provided height, inclusive, split
+// by recipient according to the same subsidy schedule used by validation.
+//
+// The amounts assume every block from the stake validation height onwards
+// includes the maximum number of votes, so they are the maximum possible
+// amounts.  The actual amounts are lower when blocks include fewer votes.
+//
+// Safe for concurrent access.
+func (s *SubsidyCache) SubsidySplitToHeight(height int64) SubsidySplit {
+	var split SubsidySplit
+	if height < 1 {
+		return split
+	}
+	params := s.params
+	voters := params.TicketsPerBlock
+	split.Initial = params.BlockOneSubsidy()
+
+	split.Work = s.sumSubsidy(2, height, func(height int64) int64 {
+		return CalcBlockWorkSubsidy(s, height, voters, params)
+	})
+	split.Treasury = s.sumSubsidy(2, height, func(height int64) int64 {
+		return CalcBlockTaxSubsidy(s, height, voters, params)
+	})
+
+	// Votes are only included in blocks from the stake validation height
+	// onwards and their subsidy is based on the height of the block they
+	// vote on, which is the previous block.
+	votedOnStart := params.StakeValidationHeight - 1
+	if votedOnStart < 2 {
+		votedOnStart = 2
+	}
+	split.Stake = s.sumSubsidy(votedOnStart, height-1, func(height int64) int64 {
+		return CalcStakeVoteSubsidy(s, height, params) * int64(voters)
+	})
+
+	return split
+}
+
+// TotalSubsidyToHeight returns the cumulative subsidy paid to all recipients by
+// all blocks from the genesis block through the block at the provided height,
+// inclusive.  See SubsidySplitToHeight for details.
+//
+// Safe for concurrent access.
+func (s *SubsidyCache) TotalSubsidyToHeight(height int64) int64 {
+	return s.SubsidySplitToHeight(height).Total()
+}
+
 // CalcBlockWorkSubsidy calculates the proof of work subsidy for a block as a
 // proportion of the total subsidy.
 func CalcBlockWorkSubsidy(subsidyCache *SubsidyCache, height int64,
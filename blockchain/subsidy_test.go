@@ -55,3 +55,110 @@ func TestBlockSubsidy(t *testing.T) {
 		t.Errorf("Bad total subsidy; want 2099999999800912, got %v", totalSubsidy)
 	}
 }
+
+// TestSubsidyCacheSplits ensures the subsidy split methods on the subsidy cache
+// produce the same values as the standalone functions used by validation.
+func TestSubsidyCacheSplits(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	subsidyCache := blockchain.NewSubsidyCache(0, params)
+
+	tests := []struct {
+		name   string
+		height int64
+		voters uint16
+	}{
+		{"block one", 1, 0},
+		{"before stake validation", params.StakeValidationHeight - 1, 0},
+		{"stake validation all voters", params.StakeValidationHeight,
+			params.TicketsPerBlock},
+		{"stake validation min voters", params.StakeValidationHeight,
+			params.TicketsPerBlock/2 + 1},
+		{"no voters", params.StakeValidationHeight, 0},
+		{"after reductions", params.SubsidyReductionInterval * 10,
+			params.TicketsPerBlock},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		work := subsidyCache.CalcWorkSubsidy(test.height, test.voters)
+		wantWork := blockchain.CalcBlockWorkSubsidy(subsidyCache,
+			test.height, test.voters, params)
+		if work != wantWork {
+			t.Errorf("Test #%d (%s) CalcWorkSubsidy: got %d, want %d",
+				i, test.name, work, wantWork)
+		}
+
+		vote := subsidyCache.CalcStakeVoteSubsidy(test.height)
+		wantVote := blockchain.CalcStakeVoteSubsidy(subsidyCache,
+			test.height, params)
+		if vote != wantVote {
+			t.Errorf("Test #%d (%s) CalcStakeVoteSubsidy: got %d, "+
+				"want %d", i, test.name, vote, wantVote)
+		}
+
+		treasury := subsidyCache.CalcTreasurySubsidy(test.height,
+			test.voters)
+		wantTreasury := blockchain.CalcBlockTaxSubsidy(subsidyCache,
+			test.height, test.voters, params)
+		if treasury != wantTreasury {
+			t.Errorf("Test #%d (%s) CalcTreasurySubsidy: got %d, "+
+				"want %d", i, test.name, treasury, wantTreasury)
+		}
+	}
+}
+
+// TestSubsidySplitToHeight ensures the cumulative subsidy calculated per
+// reduction interval matches the sum of the subsidy of every individual block.
+func TestSubsidySplitToHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *chaincfg.Params
+	}{
+		{"mainnet", &chaincfg.MainNetParams},
+		{"simnet", &chaincfg.SimNetParams},
+	}
+
+	for i, test := range tests {
+		params := test.params
+		subsidyCache := blockchain.NewSubsidyCache(0, params)
+		voters := params.TicketsPerBlock
+
+		// Ensure nothing is paid by the genesis block.
+		split := subsidyCache.SubsidySplitToHeight(0)
+		if split != (blockchain.SubsidySplit{}) {
+			t.Errorf("Test #%d (%s) unexpected split at genesis: %+v",
+				i, test.name, split)
+		}
+
+		// Calculate the cumulative subsidy block by block through several
+		// reduction intervals and ensure it matches at every height.
+		want := blockchain.SubsidySplit{Initial: params.BlockOneSubsidy()}
+		finalHeight := params.SubsidyReductionInterval*3 + 1
+		for height := int64(1); height <= finalHeight; height++ {
+			if height > 1 {
+				want.Work += blockchain.CalcBlockWorkSubsidy(
+					subsidyCache, height, voters, params)
+				want.Treasury += blockchain.CalcBlockTaxSubsidy(
+					subsidyCache, height, voters, params)
+			}
+			if height >= params.StakeValidationHeight {
+				want.Stake += blockchain.CalcStakeVoteSubsidy(
+					subsidyCache, height-1, params) *
+					int64(voters)
+			}
+
+			split := subsidyCache.SubsidySplitToHeight(height)
+			if split != want {
+				t.Errorf("Test #%d (%s) unexpected split at height "+
+					"%d - got %+v, want %+v", i, test.name, height,
+					split, want)
+			}
+			total := subsidyCache.TotalSubsidyToHeight(height)
+			if total != want.Total() {
+				t.Errorf("Test #%d (%s) unexpected total at height "+
+					"%d - got %d, want %d", i, test.name, height,
+					total, want.Total())
+			}
+		}
+	}
+}
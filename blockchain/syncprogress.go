@@ -0,0 +1,56 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"time"
+)
+
+// blockProcessTimeWeight is the weight given to the most recent sample when
+// updating the exponentially weighted moving average of the time it takes to
+// process a block.  Higher values adapt to changes in processing speed more
+// quickly at the expense of a less stable estimate.
+const blockProcessTimeWeight = 0.1
+
+// updateBlockProcessTime updates the exponentially weighted moving average of
+// the time it takes to process a block with the passed sample.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) updateBlockProcessTime(elapsed time.Duration) {
+	if b.avgBlockProcessTime == 0 {
+		b.avgBlockProcessTime = elapsed
+		return
+	}
+	avg := float64(b.avgBlockProcessTime)
+	avg += blockProcessTimeWeight * (float64(elapsed) - avg)
+	b.avgBlockProcessTime = time.Duration(avg)
+}
+
+// SyncProgress returns the fraction of the chain that has been synced along
+// with the estimated time remaining until the chain reaches the passed best
+// known height, such as the best height advertised by peers.  The estimate
+// is based on an exponentially weighted moving average of the time recent
+// blocks took to process.
+//
+// A fraction of one and no remaining time is returned when the current best
+// chain is at or beyond the best known height.  The estimated time remaining
+// is zero when no blocks have been processed yet since there is no data to
+// base the estimate on.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SyncProgress(bestKnownHeight int64) (float64, time.Duration) {
+	b.chainLock.RLock()
+	bestHeight := b.bestNode.height
+	avgProcessTime := b.avgBlockProcessTime
+	b.chainLock.RUnlock()
+
+	if bestKnownHeight <= bestHeight {
+		return 1, 0
+	}
+
+	fraction := float64(bestHeight) / float64(bestKnownHeight)
+	remaining := time.Duration(bestKnownHeight-bestHeight) * avgProcessTime
+	return fraction, remaining
+}
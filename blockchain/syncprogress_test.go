@@ -0,0 +1,58 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSyncProgress ensures the sync progress and estimated time remaining are
+// calculated as expected from the moving average of block processing times.
+func TestSyncProgress(t *testing.T) {
+	t.Parallel()
+
+	b := &BlockChain{bestNode: &blockNode{height: 100}}
+
+	// Ensure there is no estimate before any blocks are processed.
+	fraction, eta := b.SyncProgress(200)
+	if fraction != 0.5 || eta != 0 {
+		t.Fatalf("unexpected progress without samples - got %v, %v, "+
+			"want 0.5, 0", fraction, eta)
+	}
+
+	// Ensure the first sample initializes the average and later samples are
+	// weighted.
+	b.updateBlockProcessTime(100 * time.Millisecond)
+	if b.avgBlockProcessTime != 100*time.Millisecond {
+		t.Fatalf("unexpected initial average - got %v, want %v",
+			b.avgBlockProcessTime, 100*time.Millisecond)
+	}
+	b.updateBlockProcessTime(200 * time.Millisecond)
+	if b.avgBlockProcessTime != 110*time.Millisecond {
+		t.Fatalf("unexpected weighted average - got %v, want %v",
+			b.avgBlockProcessTime, 110*time.Millisecond)
+	}
+
+	tests := []struct {
+		name            string
+		bestKnownHeight int64
+		fraction        float64
+		eta             time.Duration
+	}{
+		{"behind", 400, 0.25, 300 * 110 * time.Millisecond},
+		{"one block behind", 101, 100.0 / 101.0, 110 * time.Millisecond},
+		{"synced", 100, 1, 0},
+		{"ahead of best known", 50, 1, 0},
+	}
+	for i, test := range tests {
+		fraction, eta := b.SyncProgress(test.bestKnownHeight)
+		if fraction != test.fraction || eta != test.eta {
+			t.Errorf("Test #%d (%s) unexpected progress - got %v, %v, "+
+				"want %v, %v", i, test.name, fraction, eta,
+				test.fraction, test.eta)
+		}
+	}
+}
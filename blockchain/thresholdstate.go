@@ -518,6 +518,95 @@ func (b *BlockChain) ThresholdState(hash *chainhash.Hash, version uint32, deploy
 	return state, err
 }
 
+// AgendaStateTransition describes a change to the threshold state of an agenda
+// along the main chain.
+type AgendaStateTransition struct {
+	// Height is the height of the first block the state applies to.  Since
+	// the state is determined from the point of view of the previous block,
+	// it may be one more than the height of the current best chain tip.
+	Height int64
+
+	// State is the threshold state, including the activated choice when
+	// applicable, starting at the block with the above height.
+	State ThresholdStateTuple
+}
+
+// AgendaStateHistory returns the changes to the threshold state of the agenda
+// with the passed deployment ID along the main chain in order of ascending
+// height.  All agendas start in the defined state, so the initial state is not
+// included, and no changes are returned when the agenda is still defined.
+//
+// The states are determined by the same threshold state logic that is used
+// during validation.  Since the state of an agenda only changes at the start
+// of a rule change activation interval, the state is only evaluated for the
+// first block of each interval.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) AgendaStateHistory(deploymentID string) ([]AgendaStateTransition, error) {
+	// Find the stake version of the agenda.
+	var version uint32
+	var found bool
+	for deployVer, deployments := range b.chainParams.Deployments {
+		for i := range deployments {
+			if deployments[i].Vote.Id == deploymentID {
+				version = deployVer
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, DeploymentError(deploymentID)
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// Collect the final block of each confirmation window, starting with
+	// the window that contains the current best chain tip, since the state
+	// for a window is determined from the point of view of the final block
+	// in the previous window.  The state is defined by definition for the
+	// window that contains the block at stake validation height.
+	svh := b.chainParams.StakeValidationHeight
+	interval := int64(b.chainParams.RuleChangeActivationInterval)
+	wantHeight := calcWantHeight(svh, interval, b.bestNode.height+1)
+	var windowEnds []*blockNode
+	node := b.bestNode
+	for wantHeight+1 >= svh+interval {
+		var err error
+		node, err = b.ancestorNode(node, wantHeight)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			break
+		}
+		windowEnds = append(windowEnds, node)
+		wantHeight -= interval
+	}
+
+	// Evaluate the state of each window starting from the oldest and note
+	// each change.
+	var transitions []AgendaStateTransition
+	prevState := newThresholdState(ThresholdDefined, invalidChoice)
+	for i := len(windowEnds) - 1; i >= 0; i-- {
+		node := windowEnds[i]
+		state, err := b.deploymentState(node, version, deploymentID)
+		if err != nil {
+			return nil, err
+		}
+		if state != prevState {
+			transitions = append(transitions, AgendaStateTransition{
+				Height: node.height + 1,
+				State:  state,
+			})
+			prevState = state
+		}
+	}
+
+	return transitions, nil
+}
+
 // isLNFeaturesAgendaActive returns whether or not the LN features agenda vote,
 // as defined in DCP0002 and DCP0003 has passed and is now active from the point
 // of view of the passed block node.
@@ -7,6 +7,7 @@ package blockchain_test
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -674,4 +675,73 @@ func TestThresholdState(t *testing.T) {
 	g.AssertStakeVersion(4)
 	testThresholdState(testDummy1ID, blockchain.ThresholdActive, testDummy1YesIndex)
 	testThresholdState(testDummy2ID, blockchain.ThresholdFailed, testDummy2NoIndex)
+
+	// ---------------------------------------------------------------------
+	// Ensure the history of the threshold states of the dummy deployments
+	// along the main chain reflects the transitions tested above.
+	// ---------------------------------------------------------------------
+
+	historyTests := []struct {
+		name        string
+		id          string
+		transitions []blockchain.AgendaStateTransition
+	}{{
+		name: "first test dummy agenda",
+		id:   testDummy1ID,
+		transitions: []blockchain.AgendaStateTransition{{
+			Height: stakeValidationHeight + ruleChangeInterval*3,
+			State: blockchain.ThresholdStateTuple{
+				State:  blockchain.ThresholdStarted,
+				Choice: invalidChoice,
+			},
+		}, {
+			Height: stakeValidationHeight + ruleChangeInterval*7,
+			State: blockchain.ThresholdStateTuple{
+				State:  blockchain.ThresholdLockedIn,
+				Choice: testDummy1YesIndex,
+			},
+		}, {
+			Height: stakeValidationHeight + ruleChangeInterval*8,
+			State: blockchain.ThresholdStateTuple{
+				State:  blockchain.ThresholdActive,
+				Choice: testDummy1YesIndex,
+			},
+		}},
+	}, {
+		name: "second test dummy agenda",
+		id:   testDummy2ID,
+		transitions: []blockchain.AgendaStateTransition{{
+			Height: stakeValidationHeight + ruleChangeInterval*3,
+			State: blockchain.ThresholdStateTuple{
+				State:  blockchain.ThresholdStarted,
+				Choice: invalidChoice,
+			},
+		}, {
+			Height: stakeValidationHeight + ruleChangeInterval*7,
+			State: blockchain.ThresholdStateTuple{
+				State:  blockchain.ThresholdFailed,
+				Choice: testDummy2NoIndex,
+			},
+		}},
+	}}
+	for i, test := range historyTests {
+		transitions, err := chain.AgendaStateHistory(test.id)
+		if err != nil {
+			t.Errorf("Test #%d (%s): unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(transitions, test.transitions) {
+			t.Errorf("Test #%d (%s): unexpected transitions - got %v, "+
+				"want %v", i, test.name, transitions,
+				test.transitions)
+		}
+	}
+
+	// Ensure requesting the history of an unknown agenda fails.
+	_, err = chain.AgendaStateHistory("unknown")
+	if _, ok := err.(blockchain.DeploymentError); !ok {
+		t.Fatalf("AgendaStateHistory: unexpected error for unknown "+
+			"agenda - got %v (%T), want DeploymentError", err, err)
+	}
 }
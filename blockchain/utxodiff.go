@@ -0,0 +1,156 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// UtxoDiffEntry describes a single transaction output that is either added to
+// or removed from the utxo set.
+type UtxoDiffEntry struct {
+	OutPoint      wire.OutPoint // The outpoint of the output.
+	Amount        int64         // The amount of the output.
+	PkScript      []byte        // The public key script for the output.
+	ScriptVersion uint16        // The version of the scripting language.
+}
+
+// UtxoDiff describes the changes connecting a block to the main chain makes to
+// the utxo set.
+//
+// Keep in mind that the regular transaction tree of a block is not applied to
+// the utxo set until a subsequent block approves it.  Thus, the changes consist
+// of the transactions in the regular tree of the parent of the block, when the
+// block approves it, followed by the transactions in the stake tree of the
+// block itself.
+type UtxoDiff struct {
+	// Created houses the outputs the block adds to the utxo set.  Outputs
+	// which are created and then spent by the same set of transactions are
+	// not included.
+	Created []UtxoDiffEntry
+
+	// Spent houses the outputs the block removes from the utxo set in the
+	// order they are spent.  This includes outputs which are created and
+	// then spent by the same set of transactions.
+	Spent []UtxoDiffEntry
+}
+
+// newUtxoDiff returns the utxo set changes described by the passed view and
+// spent txouts which are the result of connecting the passed block, which has
+// the passed parent, to the main chain.
+func newUtxoDiff(block, parent *dcrutil.Block, view *UtxoViewpoint, stxos []spentTxOut) (*UtxoDiff, error) {
+	var txns []*dcrutil.Tx
+	regularTxTreeValid := dcrutil.IsFlagSet16(block.MsgBlock().Header.VoteBits,
+		dcrutil.BlockValid)
+	if regularTxTreeValid {
+		txns = append(txns, parent.Transactions()...)
+	}
+	txns = append(txns, block.STransactions()...)
+
+	var diff UtxoDiff
+	for _, tx := range txns {
+		msgTx := tx.MsgTx()
+
+		// Add the outputs the transaction spends.  This mirrors the
+		// order the spent txouts are created when connecting the
+		// transaction.
+		if !IsCoinBaseTx(msgTx) {
+			isVote := stake.DetermineTxType(msgTx) == stake.TxTypeSSGen
+			for i, txIn := range msgTx.TxIn {
+				if i == 0 && isVote {
+					continue
+				}
+
+				idx := len(diff.Spent)
+				if idx >= len(stxos) {
+					return nil, AssertError(fmt.Sprintf("missing "+
+						"spent txout for input %v",
+						txIn.PreviousOutPoint))
+				}
+				stxo := &stxos[idx]
+				pkScript := stxo.pkScript
+				if stxo.compressed {
+					pkScript = decompressScript(pkScript,
+						currentCompressionVersion)
+				}
+				diff.Spent = append(diff.Spent, UtxoDiffEntry{
+					OutPoint:      txIn.PreviousOutPoint,
+					Amount:        stxo.amount,
+					PkScript:      pkScript,
+					ScriptVersion: stxo.scriptVersion,
+				})
+			}
+		}
+
+		// Add the outputs the transaction creates which remain unspent.
+		entry := view.LookupEntry(tx.Hash())
+		if entry == nil {
+			continue
+		}
+		for txOutIdx := range msgTx.TxOut {
+			outputIdx := uint32(txOutIdx)
+			if entry.IsOutputSpent(outputIdx) {
+				continue
+			}
+			diff.Created = append(diff.Created, UtxoDiffEntry{
+				OutPoint: wire.OutPoint{
+					Hash:  *tx.Hash(),
+					Index: outputIdx,
+					Tree:  tx.Tree(),
+				},
+				Amount:        entry.AmountByIndex(outputIdx),
+				PkScript:      entry.PkScriptByIndex(outputIdx),
+				ScriptVersion: entry.ScriptVersionByIndex(outputIdx),
+			})
+		}
+	}
+	if len(diff.Spent) != len(stxos) {
+		return nil, AssertError(fmt.Sprintf("found %d spent txouts for %d "+
+			"spent inputs", len(stxos), len(diff.Spent)))
+	}
+
+	return &diff, nil
+}
+
+// ProcessBlockDryRun performs the same checks as ProcessBlock with the
+// BFDryRun flag set, which means the block is fully validated without being
+// added to the block chain.  In addition, when the block would extend the main
+// chain, the changes connecting it would make to the utxo set are returned.
+// The changes are computed by the same logic that is used when the block is
+// actually connected.
+//
+// A nil diff is returned without an error when the block is an orphan or would
+// not directly extend the main chain, such as when it is on a side chain or
+// would cause a reorganize, since there is no meaningful set of changes
+// relative to the current utxo set in those cases.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ProcessBlockDryRun(block *dcrutil.Block, flags BehaviorFlags) (*UtxoDiff, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	b.dryRunView, b.dryRunStxos = nil, nil
+	defer func() {
+		b.dryRunView, b.dryRunStxos = nil, nil
+	}()
+
+	_, _, err := b.processBlock(block, flags|BFDryRun)
+	if err != nil {
+		return nil, err
+	}
+	if b.dryRunView == nil {
+		return nil, nil
+	}
+
+	parent, err := b.fetchBlockFromHash(&block.MsgBlock().Header.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+	return newUtxoDiff(block, parent, b.dryRunView, b.dryRunStxos)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/decred/dcrd/blockchain/internal/dbnamespace"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
+	"github.com/decred/dcrd/wire"
+)
+
+const (
+	// utxoSnapshotVersion is the current version of the serialized utxo set
+	// snapshot format produced by WriteUtxoSet.
+	utxoSnapshotVersion = 1
+
+	// utxoSnapshotHeaderSize is the size of a serialized utxo set snapshot
+	// header.  It consists of the version, the tip hash, the tip height, and
+	// the number of entries.
+	utxoSnapshotHeaderSize = 4 + chainhash.HashSize + 8 + 8
+)
+
+// utxoSnapshotMagic is the sequence of bytes that starts every utxo set
+// snapshot and identifies the data as such.
+var utxoSnapshotMagic = [4]byte{'u', 't', 'x', 'o'}
+
+// UtxoSnapshotHeader describes the utxo set contained in a snapshot produced
+// by WriteUtxoSet.
+type UtxoSnapshotHeader struct {
+	// Version is the version of the snapshot format.
+	Version uint32
+
+	// TipHash and TipHeight identify the block the utxo set was captured
+	// at.  All transactions in the main chain up to and including that
+	// block are reflected by the utxo set.
+	TipHash   chainhash.Hash
+	TipHeight int64
+
+	// NumEntries is the number of utxo entries in the snapshot.
+	NumEntries uint64
+}
+
+// -----------------------------------------------------------------------------
+// A utxo set snapshot is a compact and deterministic serialization of the full
+// utxo set as of a given main chain block.
+//
+// The serialized format is:
+//
+//   <magic><version><tip hash><tip height><num entries><entries>
+//
+//   Field          Type              Size
+//   magic          [4]byte           4
+//   version        uint32            4
+//   tip hash       chainhash.Hash    chainhash.HashSize
+//   tip height     int64             8
+//   num entries    uint64            8
+//   entries        []entry           variable
+//
+// Each entry is serialized as:
+//
+//   <tx hash><serialized utxo len><serialized utxo>
+//
+//   Field              Type              Size
+//   tx hash            chainhash.Hash    chainhash.HashSize
+//   serialized utxo    []byte            variable (varint length prefixed)
+//
+// The serialized utxo is identical to the format the entry is stored in the
+// utxo set bucket of the database (see serializeUtxoEntry) and all integers in
+// the header are encoded in little endian.  Entries are written in ascending
+// order of their transaction hash bytes, so the same utxo set always results in
+// the same snapshot.
+// -----------------------------------------------------------------------------
+
+// WriteUtxoSet serializes the entire utxo set as of the current best chain
+// tip to the passed writer using the utxo set snapshot format.  The tip hash in
+// the snapshot header allows it to be validated against a header chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) WriteUtxoSet(w io.Writer) error {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.db.View(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
+
+		// Count the entries so they can be included in the header.
+		var numEntries uint64
+		err := utxoBucket.ForEach(func(k, v []byte) error {
+			numEntries++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		header := UtxoSnapshotHeader{
+			Version:    utxoSnapshotVersion,
+			TipHash:    b.bestNode.hash,
+			TipHeight:  b.bestNode.height,
+			NumEntries: numEntries,
+		}
+		if err := writeUtxoSnapshotHeader(w, &header); err != nil {
+			return err
+		}
+
+		// The database iterates keys in ascending byte order which
+		// makes the serialized entries deterministic.
+		return utxoBucket.ForEach(func(k, v []byte) error {
+			if _, err := w.Write(k); err != nil {
+				return err
+			}
+			return wire.WriteVarBytes(w, 0, v)
+		})
+	})
+}
+
+// writeUtxoSnapshotHeader serializes the passed utxo set snapshot header,
+// including the leading magic bytes, to the passed writer.
+func writeUtxoSnapshotHeader(w io.Writer, header *UtxoSnapshotHeader) error {
+	var buf [len(utxoSnapshotMagic) + utxoSnapshotHeaderSize]byte
+	offset := copy(buf[:], utxoSnapshotMagic[:])
+	byteOrder.PutUint32(buf[offset:], header.Version)
+	offset += 4
+	copy(buf[offset:], header.TipHash[:])
+	offset += chainhash.HashSize
+	byteOrder.PutUint64(buf[offset:], uint64(header.TipHeight))
+	offset += 8
+	byteOrder.PutUint64(buf[offset:], header.NumEntries)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// ReadUtxoSnapshotHeader reads and returns the header of a utxo set snapshot
+// produced by WriteUtxoSet from the passed reader.  This allows the tip the
+// snapshot was captured at to be validated against a header chain before the
+// snapshot is used.
+func ReadUtxoSnapshotHeader(r io.Reader) (*UtxoSnapshotHeader, error) {
+	var buf [len(utxoSnapshotMagic) + utxoSnapshotHeaderSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(buf[:len(utxoSnapshotMagic)], utxoSnapshotMagic[:]) {
+		return nil, errDeserialize("data is not a utxo set snapshot")
+	}
+	offset := len(utxoSnapshotMagic)
+
+	var header UtxoSnapshotHeader
+	header.Version = byteOrder.Uint32(buf[offset:])
+	offset += 4
+	if header.Version != utxoSnapshotVersion {
+		str := fmt.Sprintf("unsupported utxo set snapshot version %d",
+			header.Version)
+		return nil, errDeserialize(str)
+	}
+	copy(header.TipHash[:], buf[offset:offset+chainhash.HashSize])
+	offset += chainhash.HashSize
+	header.TipHeight = int64(byteOrder.Uint64(buf[offset:]))
+	offset += 8
+	header.NumEntries = byteOrder.Uint64(buf[offset:])
+	return &header, nil
+}
+
+// UtxoSetAtHeight reconstructs the entire utxo set as it was immediately after
+// the main chain block at the passed height was connected.  The current utxo
+// set is loaded into a new view which is then unwound one block at a time from
+// the current best chain tip back to the requested height by restoring the
+// outputs recorded in the spend journal and removing the outputs the
+// disconnected blocks created.  The returned view only contains unspent
+// outputs and its best hash is the block at the requested height.
+//
+// The height must be between zero and the height of the current best chain
+// tip, inclusive.
+//
+// NOTE: This is an expensive operation intended for forensic analysis and
+// audits.  The entire utxo set is held in memory and every block between the
+// requested height and the current tip, along with its parent and its spend
+// journal entry, is loaded from the database, so both the memory usage and the
+// time taken grow with the size of the utxo set and the depth of the requested
+// height.  The chain state lock is held for reads during the entire process.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) UtxoSetAtHeight(height int64) (*UtxoViewpoint, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if height < 0 || height > b.bestNode.height {
+		return nil, fmt.Errorf("height %d is out of range [0, %d]",
+			height, b.bestNode.height)
+	}
+
+	// Load the entire current utxo set into the view.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&b.bestNode.hash)
+	err := b.db.View(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
+		return utxoBucket.ForEach(func(k, v []byte) error {
+			entry, err := deserializeUtxoEntry(v)
+			if err != nil {
+				return err
+			}
+			var txHash chainhash.Hash
+			copy(txHash[:], k)
+			view.entries[txHash] = entry
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Unwind each main chain block after the requested height, starting
+	// with the current tip, using the spend journal to restore the outputs
+	// the blocks spent.
+	block, err := b.fetchBlockFromHash(&b.bestNode.hash)
+	if err != nil {
+		return nil, err
+	}
+	for block.Height() > height {
+		parent, err := b.fetchBlockFromHash(&block.MsgBlock().Header.PrevBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		var stxos []spentTxOut
+		err = b.db.View(func(dbTx database.Tx) error {
+			var err error
+			stxos, err = dbFetchSpendJournalEntry(dbTx, block, parent)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = b.disconnectTransactions(view, block, parent, stxos)
+		if err != nil {
+			return nil, err
+		}
+		block = parent
+	}
+
+	// Remove the outputs that were fully spent as of the requested height.
+	view.commit()
+	return view, nil
+}
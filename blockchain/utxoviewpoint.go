@@ -12,6 +12,7 @@ import (
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/database"
 	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
 )
 
@@ -1170,3 +1171,135 @@ func (b *BlockChain) FetchUtxoEntry(txHash *chainhash.Hash) (*UtxoEntry, error)
 
 	return entry, nil
 }
+
+// OutputEntry describes a transaction output added to the utxo set when a block
+// is connected to the main chain.
+type OutputEntry struct {
+	OutPoint      wire.OutPoint // The outpoint of the output.
+	Amount        int64         // The amount of the output.
+	PkScript      []byte        // The public key script for the output.
+	ScriptVersion uint16        // The version of the scripting language.
+	Height        uint32        // Height of the block containing the tx.
+	Index         uint32        // Index in the block of the transaction.
+	TxType        stake.TxType  // The stake type of the transaction.
+	IsCoinBase    bool          // Whether creating tx is a coinbase.
+}
+
+// appendOutputEntries appends an output entry for every output of the passed
+// transaction which is not provably unspendable to the passed slice and returns
+// the result.  This mirrors the outputs AddTxOuts adds to a utxo view.
+func appendOutputEntries(entries []OutputEntry, tx *dcrutil.Tx, tree int8,
+	blockHeight int64, blockIndex uint32) []OutputEntry {
+
+	msgTx := tx.MsgTx()
+	txType := stake.DetermineTxType(msgTx)
+	isCoinBase := IsCoinBaseTx(msgTx)
+	for txOutIdx, txOut := range msgTx.TxOut {
+		if txscript.IsUnspendable(txOut.Value, txOut.PkScript) {
+			continue
+		}
+
+		entries = append(entries, OutputEntry{
+			OutPoint: wire.OutPoint{
+				Hash:  *tx.Hash(),
+				Index: uint32(txOutIdx),
+				Tree:  tree,
+			},
+			Amount:        txOut.Value,
+			PkScript:      txOut.PkScript,
+			ScriptVersion: txOut.Version,
+			Height:        uint32(blockHeight),
+			Index:         blockIndex,
+			TxType:        txType,
+			IsCoinBase:    isCoinBase,
+		})
+	}
+	return entries
+}
+
+// BlockNewOutputs returns all of the outputs which are added to the utxo set
+// when the passed block is connected to the main chain.  This mirrors the
+// outputs the utxo view is updated with when a block is connected, which means
+// they consist of the outputs of the regular tree transactions of the parent
+// block when the passed block approves it, followed by the outputs of the stake
+// tree transactions of the passed block.  The outputs are in transaction order
+// and then output order within each tree.  Provably unspendable outputs are
+// not included since they are never added to the utxo set.
+//
+// The parent of the passed block must be known, however the block itself does
+// not need to be part of the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockNewOutputs(block *dcrutil.Block) ([]OutputEntry, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var entries []OutputEntry
+	regularTxTreeValid := dcrutil.IsFlagSet16(
+		block.MsgBlock().Header.VoteBits, dcrutil.BlockValid)
+	if block.Height() != 0 && regularTxTreeValid {
+		parent, err := b.fetchBlockFromHash(
+			&block.MsgBlock().Header.PrevBlock)
+		if err != nil {
+			return nil, err
+		}
+		for i, tx := range parent.Transactions() {
+			entries = appendOutputEntries(entries, tx,
+				wire.TxTreeRegular, parent.Height(), uint32(i))
+		}
+	}
+	for i, stx := range block.STransactions() {
+		entries = appendOutputEntries(entries, stx, wire.TxTreeStake,
+			block.Height(), uint32(i))
+	}
+
+	return entries, nil
+}
+
+// TxConfirmations returns the number of confirmations the passed transaction
+// has relative to the end of the main chain.  A transaction contained in the
+// current tip has zero confirmations.
+//
+// The containing block is located via the transaction locator provided by the
+// TxLocator field of the chain configuration, which is typically the
+// transaction index, so any transaction in the main chain can be located.
+//
+// When no transaction locator was provided, the containing block is instead
+// located by way of the unspent transaction output set.  This means that only
+// transactions which still have unspent outputs can be located, and that
+// transactions in the regular tree of the current tip can't be located either
+// since they are not added to the set until the next block approves them.
+//
+// A value of -1 and an error are returned when the transaction can't be
+// located.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) TxConfirmations(txHash *chainhash.Hash) (int64, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if b.txLocator != nil {
+		_, height, err := b.BlockByTx(txHash)
+		if err != nil {
+			return -1, err
+		}
+		return b.bestNode.height - height, nil
+	}
+
+	var entry *UtxoEntry
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		entry, err = dbFetchUtxoEntry(dbTx, txHash)
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+	if entry == nil || entry.IsFullySpent() {
+		str := fmt.Sprintf("no unspent outputs for transaction %v in "+
+			"the main chain", txHash)
+		return -1, errNotInMainChain(str)
+	}
+
+	return b.bestNode.height - entry.BlockHeight(), nil
+}
@@ -31,9 +31,9 @@ const (
 	// so that is what this is based on.
 	MaxSigOpsPerBlock = 1000000 / 200
 
-	// MaxTimeOffsetSeconds is the maximum number of seconds a block time
-	// is allowed to be ahead of the current time.  This is currently 2
-	// hours.
+	// MaxTimeOffsetSeconds is the default maximum number of seconds a
+	// block time is allowed to be ahead of the current time when the chain
+	// parameters do not specify one.  This is currently 2 hours.
 	MaxTimeOffsetSeconds = 2 * 60 * 60
 
 	// MinCoinbaseScriptLen is the minimum length a coinbase script can be.
@@ -118,6 +118,14 @@ func IsCoinBase(tx *dcrutil.Tx) bool {
 	return IsCoinBaseTx(tx.MsgTx())
 }
 
+// BlockTxCounts returns the number of transactions in the regular and stake
+// transaction trees of the passed block.  The regular count includes the
+// coinbase and the stake count includes votes, tickets, and revocations.
+func BlockTxCounts(block *dcrutil.Block) (regular, stake int) {
+	msgBlock := block.MsgBlock()
+	return len(msgBlock.Transactions), len(msgBlock.STransactions)
+}
+
 // SequenceLockActive determines if all of the inputs to a given transaction
 // have achieved a relative age that surpasses the requirements specified by
 // their respective sequence locks as calculated by CalcSequenceLock.  A single
@@ -359,9 +367,17 @@ func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags Behavio
 
 // CheckProofOfWork ensures the block header bits which indicate the target
 // difficulty is in min/max range and that the block hash is less than the
-// target difficulty as claimed.
-func CheckProofOfWork(block *dcrutil.Block, powLimit *big.Int) error {
-	return checkProofOfWork(&block.MsgBlock().Header, powLimit, BFNone)
+// target difficulty as claimed.  The maximum allowed target is the proof of
+// work limit of the passed chain parameters.
+//
+// These are the same proof of work checks that are performed when processing a
+// block without the BFNoPoWCheck flag, however they only depend on the header,
+// so they may be performed independently, such as by light clients which only
+// have access to block headers.  A RuleError with the ErrUnexpectedDifficulty
+// error code is returned when the target is out of range and one with the
+// ErrHighHash error code is returned when the hash is higher than the target.
+func CheckProofOfWork(header *wire.BlockHeader, chainParams *chaincfg.Params) error {
+	return checkProofOfWork(header, chainParams.PowLimit, BFNone)
 }
 
 // checkBlockHeaderSanity performs some preliminary checks on a block header to
@@ -370,7 +386,10 @@ func CheckProofOfWork(block *dcrutil.Block, powLimit *big.Int) error {
 //
 // The flags do not modify the behavior of this function directly, however they
 // are needed to pass along to checkProofOfWork.
-func checkBlockHeaderSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params) error {
+//
+// The passed rule overrider, which may be nil, is consulted for each failed
+// check.
+func checkBlockHeaderSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params, overrider *ruleOverrider) error {
 	powLimit := chainParams.PowLimit
 	posLimit := chainParams.MinimumStakeDiff
 	header := &block.MsgBlock().Header
@@ -378,14 +397,14 @@ func checkBlockHeaderSanity(block *dcrutil.Block, timeSource MedianTimeSource, f
 	// Ensure the proof of work bits in the block header is in min/max
 	// range and the block hash is less than the target value described by
 	// the bits.
-	err := checkProofOfWork(header, powLimit, flags)
+	err := overrider.check(checkProofOfWork(header, powLimit, flags))
 	if err != nil {
 		return err
 	}
 
 	// Check to make sure that all newly purchased tickets meet the
 	// difficulty specified in the block.
-	err = checkProofOfStake(block, posLimit)
+	err = overrider.check(checkProofOfStake(block, posLimit))
 	if err != nil {
 		return err
 	}
@@ -398,16 +417,94 @@ func checkBlockHeaderSanity(block *dcrutil.Block, timeSource MedianTimeSource, f
 	if !header.Timestamp.Equal(time.Unix(header.Timestamp.Unix(), 0)) {
 		str := fmt.Sprintf("block timestamp of %v has a higher "+
 			"precision than one second", header.Timestamp)
-		return ruleError(ErrInvalidTime, str)
+		err := overrider.check(ruleError(ErrInvalidTime, str))
+		if err != nil {
+			return err
+		}
 	}
 
 	// Ensure the block time is not too far in the future.
-	maxTimestamp := timeSource.AdjustedTime().Add(time.Second *
-		MaxTimeOffsetSeconds)
+	maxTimestamp := timeSource.AdjustedTime().Add(
+		MaxFutureBlockTime(chainParams))
 	if header.Timestamp.After(maxTimestamp) {
 		str := fmt.Sprintf("block timestamp of %v is too far in the "+
 			"future", header.Timestamp)
-		return ruleError(ErrTimeTooNew, str)
+		err := overrider.check(ruleError(ErrTimeTooNew, str))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MaxFutureBlockTime returns the maximum amount of time a block timestamp is
+// allowed to be ahead of the current network adjusted time for the passed
+// network parameters.  This is the value of the MaxFutureBlockTime field of
+// the parameters, or MaxTimeOffsetSeconds when it is not set.
+func MaxFutureBlockTime(chainParams *chaincfg.Params) time.Duration {
+	if chainParams.MaxFutureBlockTime == 0 {
+		return time.Second * MaxTimeOffsetSeconds
+	}
+	return chainParams.MaxFutureBlockTime
+}
+
+// CheckDuplicateTransactions ensures the passed block does not contain any
+// transactions which hash to the same value across both its regular and stake
+// transaction trees.  A RuleError with the ErrDuplicateTx error code which
+// identifies the first duplicated hash is returned otherwise.
+//
+// This check is also performed as part of the block sanity checks.  It is
+// exported so callers, such as block template generators, may perform it on
+// its own.
+func CheckDuplicateTransactions(block *dcrutil.Block) error {
+	msgBlock := block.MsgBlock()
+	numTxns := len(msgBlock.Transactions) + len(msgBlock.STransactions)
+	existingTxHashes := make(map[chainhash.Hash]struct{}, numTxns)
+	for _, txns := range [][]*dcrutil.Tx{block.Transactions(),
+		block.STransactions()} {
+
+		for _, tx := range txns {
+			hash := tx.Hash()
+			if _, exists := existingTxHashes[*hash]; exists {
+				str := fmt.Sprintf("block contains duplicate "+
+					"transaction %v", hash)
+				return ruleError(ErrDuplicateTx, str)
+			}
+			existingTxHashes[*hash] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// VerifyMerkleRoots recalculates the merkle roots of both the regular and stake
+// transaction trees of the passed block and ensures they match the respective
+// roots committed to by its header.  A RuleError with the ErrBadMerkleRoot error
+// code which identifies the mismatched tree along with the committed and
+// calculated roots is returned otherwise.
+//
+// This check is also performed as part of the block sanity checks.  It is
+// exported so callers may perform it on its own, such as when diagnosing why a
+// block or block template is invalid.
+func VerifyMerkleRoots(block *dcrutil.Block) error {
+	header := &block.MsgBlock().Header
+	merkles := BuildMerkleTreeStore(block.Transactions())
+	calculatedMerkleRoot := merkles[len(merkles)-1]
+	if !header.MerkleRoot.IsEqual(calculatedMerkleRoot) {
+		str := fmt.Sprintf("block merkle root is invalid - block "+
+			"header indicates %v, but calculated value is %v",
+			header.MerkleRoot, calculatedMerkleRoot)
+		return ruleError(ErrBadMerkleRoot, str)
+	}
+
+	merkleStake := BuildMerkleTreeStore(block.STransactions())
+	calculatedStakeMerkleRoot := merkleStake[len(merkleStake)-1]
+	if !header.StakeRoot.IsEqual(calculatedStakeMerkleRoot) {
+		str := fmt.Sprintf("block stake merkle root is invalid - block"+
+			" header indicates %v, but calculated value is %v",
+			header.StakeRoot, calculatedStakeMerkleRoot)
+		return ruleError(ErrBadMerkleRoot, str)
 	}
 
 	return nil
@@ -419,11 +516,16 @@ func checkBlockHeaderSanity(block *dcrutil.Block, timeSource MedianTimeSource, f
 //
 // The flags do not modify the behavior of this function directly, however they
 // are needed to pass along to checkBlockHeaderSanity.
-func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params) error {
+//
+// The passed rule overrider, which may be nil, is consulted for each failed
+// check except the one which ensures the block has at least one regular
+// transaction since the remaining checks depend on it.
+func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params, overrider *ruleOverrider) error {
 
 	msgBlock := block.MsgBlock()
 	header := &msgBlock.Header
-	err := checkBlockHeaderSanity(block, timeSource, flags, chainParams)
+	err := checkBlockHeaderSanity(block, timeSource, flags, chainParams,
+		overrider)
 	if err != nil {
 		return err
 	}
@@ -450,20 +552,29 @@ func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags B
 	if serializedSize > wire.MaxBlockPayload {
 		str := fmt.Sprintf("serialized block is too big - got %d, "+
 			"max %d", serializedSize, wire.MaxBlockPayload)
-		return ruleError(ErrBlockTooBig, str)
+		err := overrider.check(ruleError(ErrBlockTooBig, str))
+		if err != nil {
+			return err
+		}
 	}
 	if msgBlock.Header.Size != uint32(serializedSize) {
 		str := fmt.Sprintf("serialized block is not size indicated in "+
 			"header - got %d, expected %d", msgBlock.Header.Size,
 			serializedSize)
-		return ruleError(ErrWrongBlockSize, str)
+		err := overrider.check(ruleError(ErrWrongBlockSize, str))
+		if err != nil {
+			return err
+		}
 	}
 
 	// The first transaction in a block's txtreeregular must be a coinbase.
 	transactions := block.Transactions()
 	if !IsCoinBaseTx(transactions[0].MsgTx()) {
-		return ruleError(ErrFirstTxNotCoinbase, "first transaction in "+
-			"block is not a coinbase")
+		err := overrider.check(ruleError(ErrFirstTxNotCoinbase,
+			"first transaction in block is not a coinbase"))
+		if err != nil {
+			return err
+		}
 	}
 
 	// A block must not have more than one coinbase.
@@ -471,7 +582,11 @@ func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags B
 		if IsCoinBaseTx(tx.MsgTx()) {
 			str := fmt.Sprintf("block contains second coinbase at "+
 				"index %d", i+1)
-			return ruleError(ErrMultipleCoinbases, str)
+			err := overrider.check(ruleError(ErrMultipleCoinbases,
+				str))
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -483,28 +598,172 @@ func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags B
 		if txType != stake.TxTypeRegular {
 			errStr := fmt.Sprintf("found stake tx in regular tx " +
 				"tree")
-			return ruleError(ErrStakeTxInRegularTree, errStr)
+			err := overrider.check(ruleError(ErrStakeTxInRegularTree,
+				errStr))
+			if err != nil {
+				return err
+			}
 		}
-		err := CheckTransactionSanity(msgTx, chainParams)
+		err := overrider.check(CheckTransactionSanity(msgTx, chainParams))
 		if err != nil {
 			return err
 		}
 	}
 
+	// Do the same for each stake transaction and ensure the number of
+	// tickets, votes, and revocations matches the header.
+	err = checkStakeTreeSanity(block, chainParams, overrider)
+	if err != nil {
+		return err
+	}
+
+	// Build the merkle trees and ensure the calculated merkle roots match
+	// the entries in the block header.  This also has the effect of caching
+	// all of the transaction hashes in the block to speed up future hash
+	// checks.  Bitcoind builds the tree here and checks the merkle root
+	// after the following checks, but there is no reason not to check the
+	// merkle root matches here.
+	if err := overrider.check(VerifyMerkleRoots(block)); err != nil {
+		return err
+	}
+
+	// Check for duplicate transactions.  This check will be fairly quick
+	// since the transaction hashes are already cached due to building the
+	// merkle tree above.
+	err = overrider.check(CheckDuplicateTransactions(block))
+	if err != nil {
+		return err
+	}
+	stakeTransactions := block.STransactions()
+	allTransactions := append(transactions, stakeTransactions...)
+
+	// The number of signature operations must be less than the maximum
+	// allowed per block.
+	totalSigOps := 0
+	for _, tx := range allTransactions {
+		msgTx := tx.MsgTx()
+		// We could potentially overflow the accumulator so check for
+		// overflow.
+		lastSigOps := totalSigOps
+
+		isSSGen, _ := stake.IsSSGen(msgTx)
+		isCoinBase := IsCoinBaseTx(msgTx)
+
+		totalSigOps += CountSigOps(tx, isCoinBase, isSSGen)
+		if totalSigOps < lastSigOps || totalSigOps > MaxSigOpsPerBlock {
+			str := fmt.Sprintf("block contains too many signature "+
+				"operations - got %v, max %v", totalSigOps,
+				MaxSigOpsPerBlock)
+			err := overrider.check(ruleError(ErrTooManySigOps, str))
+			if err != nil {
+				return err
+			}
+
+			// There is no need to keep counting once the limit
+			// has been exceeded and the failure was overridden.
+			break
+		}
+	}
+
+	// Blocks before stake validation height may only have 0x0001 as their
+	// VoteBits in the header.
+	if int64(header.Height) < chainParams.StakeValidationHeight {
+		if header.VoteBits != earlyVoteBitsValue {
+			str := fmt.Sprintf("pre stake validation height "+
+				"block %v contained an invalid votebits value"+
+				" (expected %v, got %v)", block.Hash(),
+				earlyVoteBitsValue, header.VoteBits)
+			err := overrider.check(ruleError(ErrInvalidEarlyVoteBits,
+				str))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RequiredVotesForBlock returns the minimum number of votes a block at or
+// after the stake validation height must include for it to be valid.  This is
+// a majority of the number of tickets selected to vote on each block.  Blocks
+// prior to the stake validation height are not required to contain any votes.
+func RequiredVotesForBlock(chainParams *chaincfg.Params) int {
+	return int(chainParams.TicketsPerBlock)/2 + 1
+}
+
+// checkVoteCountLimits ensures the passed number of votes contained in the
+// passed block is at least the number of required votes once the stake
+// validation height has been reached and does not exceed the number of tickets
+// selected to vote on each block.
+func checkVoteCountLimits(block *dcrutil.Block, totalVotes int, chainParams *chaincfg.Params) error {
+	// Not enough voters on this block.
+	requiredVotes := RequiredVotesForBlock(chainParams)
+	if block.Height() >= chainParams.StakeValidationHeight &&
+		totalVotes < requiredVotes {
+		errStr := fmt.Sprintf("block contained too few votes! %v "+
+			"votes but %v or more required", totalVotes,
+			requiredVotes)
+		return ruleError(ErrNotEnoughVotes, errStr)
+	}
+
+	if totalVotes > int(chainParams.TicketsPerBlock) {
+		errStr := fmt.Sprintf("the number of SSGen tx in block %v "+
+			"was %v, overflowing the maximum allowed (%v)",
+			block.Hash(), totalVotes,
+			int(chainParams.TicketsPerBlock))
+		return ruleError(ErrTooManyVotes, errStr)
+	}
+
+	return nil
+}
+
+// CheckVoteCount ensures the number of votes in the stake transaction tree of
+// the passed block is within the limits imposed by the consensus rules.  Blocks
+// at or after the stake validation height must contain at least the number of
+// votes returned by RequiredVotesForBlock and no block may contain more votes
+// than the number of tickets selected to vote on each block.
+//
+// The returned error is a RuleError with the code ErrNotEnoughVotes or
+// ErrTooManyVotes which reports both the actual and the expected number of
+// votes when the check fails.
+func CheckVoteCount(block *dcrutil.Block, chainParams *chaincfg.Params) error {
+	totalVotes := 0
+	for _, stx := range block.MsgBlock().STransactions {
+		if stake.DetermineTxType(stx) == stake.TxTypeSSGen {
+			totalVotes++
+		}
+	}
+
+	return checkVoteCountLimits(block, totalVotes, chainParams)
+}
+
+// checkStakeTreeSanity performs the context free checks on the stake
+// transaction tree of the passed block.  Every transaction in it must be a sane
+// stake transaction and the number of tickets, votes, and revocations must
+// match the header and be within the allowed limits.
+//
+// The passed rule overrider, which may be nil, is consulted for each failed
+// check.
+func checkStakeTreeSanity(block *dcrutil.Block, chainParams *chaincfg.Params, overrider *ruleOverrider) error {
 	totalTickets := 0
 	totalVotes := 0
 	totalRevocations := 0
 	for _, stx := range block.MsgBlock().STransactions {
-		err := CheckTransactionSanity(stx, chainParams)
+		err := overrider.check(CheckTransactionSanity(stx, chainParams))
 		if err != nil {
 			return err
 		}
 
 		txType := stake.DetermineTxType(stx)
 		if txType == stake.TxTypeRegular {
-			errStr := fmt.Sprintf("found regular tx in stake tx " +
-				"tree")
-			return ruleError(ErrRegTxInStakeTree, errStr)
+			errStr := fmt.Sprintf("found regular tx %v in stake tx "+
+				"tree", stx.TxHash())
+			err := overrider.check(ruleError(ErrRegTxInStakeTree,
+				errStr))
+			if err != nil {
+				return err
+			}
 		}
 
 		switch txType {
@@ -521,116 +780,60 @@ func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags B
 		errStr := fmt.Sprintf("%v tickets found in block, while "+
 			"header reports %v", totalTickets,
 			block.MsgBlock().Header.FreshStake)
-		return ruleError(ErrFreshStakeMismatch, errStr)
-	}
-
-	// Not enough voters on this block.
-	if block.Height() >= chainParams.StakeValidationHeight &&
-		totalVotes <= int(chainParams.TicketsPerBlock)/2 {
-		errStr := fmt.Sprintf("block contained too few votes! %v "+
-			"votes but %v or more required", totalVotes,
-			(int(chainParams.TicketsPerBlock)/2)+1)
-		return ruleError(ErrNotEnoughVotes, errStr)
+		err := overrider.check(ruleError(ErrFreshStakeMismatch, errStr))
+		if err != nil {
+			return err
+		}
 	}
 
-	if totalVotes > int(chainParams.TicketsPerBlock) {
-		errStr := fmt.Sprintf("the number of SSGen tx in block %v "+
-			"was %v, overflowing the maximum allowed (%v)",
-			block.Hash(), totalVotes,
-			int(chainParams.TicketsPerBlock))
-		return ruleError(ErrTooManyVotes, errStr)
+	err := overrider.check(checkVoteCountLimits(block, totalVotes,
+		chainParams))
+	if err != nil {
+		return err
 	}
 
 	if totalVotes != int(block.MsgBlock().Header.Voters) {
 		errStr := fmt.Sprintf("%v votes found in block, while header "+
 			"reports %v", totalVotes,
 			block.MsgBlock().Header.Voters)
-		return ruleError(ErrVotesMismatch, errStr)
+		err := overrider.check(ruleError(ErrVotesMismatch, errStr))
+		if err != nil {
+			return err
+		}
 	}
 
 	if totalRevocations != int(block.MsgBlock().Header.Revocations) {
 		errStr := fmt.Sprintf("%v revocations found in block, while "+
 			"header reports %v", totalRevocations,
 			block.MsgBlock().Header.Revocations)
-		return ruleError(ErrRevocationsMismatch, errStr)
-	}
-
-	// The number of votes must be the same as the number declared in the
-	// header.  The same is true for tickets and revocations.
-
-	// Build merkle tree and ensure the calculated merkle root matches the
-	// entry in the block header.  This also has the effect of caching all
-	// of the transaction hashes in the block to speed up future hash
-	// checks.  Bitcoind builds the tree here and checks the merkle root
-	// after the following checks, but there is no reason not to check the
-	// merkle root matches here.
-	merkles := BuildMerkleTreeStore(block.Transactions())
-	calculatedMerkleRoot := merkles[len(merkles)-1]
-	if !header.MerkleRoot.IsEqual(calculatedMerkleRoot) {
-		str := fmt.Sprintf("block merkle root is invalid - block "+
-			"header indicates %v, but calculated value is %v",
-			header.MerkleRoot, calculatedMerkleRoot)
-		return ruleError(ErrBadMerkleRoot, str)
-	}
-
-	// Build the stake tx tree merkle root too and check it.
-	merkleStake := BuildMerkleTreeStore(block.STransactions())
-	calculatedStakeMerkleRoot := merkleStake[len(merkleStake)-1]
-	if !header.StakeRoot.IsEqual(calculatedStakeMerkleRoot) {
-		str := fmt.Sprintf("block stake merkle root is invalid - block"+
-			" header indicates %v, but calculated value is %v",
-			header.StakeRoot, calculatedStakeMerkleRoot)
-		return ruleError(ErrBadMerkleRoot, str)
-	}
-
-	// Check for duplicate transactions.  This check will be fairly quick
-	// since the transaction hashes are already cached due to building the
-	// merkle tree above.
-	existingTxHashes := make(map[chainhash.Hash]struct{})
-	stakeTransactions := block.STransactions()
-	allTransactions := append(transactions, stakeTransactions...)
-
-	for _, tx := range allTransactions {
-		hash := tx.Hash()
-		if _, exists := existingTxHashes[*hash]; exists {
-			str := fmt.Sprintf("block contains duplicate "+
-				"transaction %v", hash)
-			return ruleError(ErrDuplicateTx, str)
+		err := overrider.check(ruleError(ErrRevocationsMismatch,
+			errStr))
+		if err != nil {
+			return err
 		}
-		existingTxHashes[*hash] = struct{}{}
 	}
 
-	// The number of signature operations must be less than the maximum
-	// allowed per block.
-	totalSigOps := 0
-	for _, tx := range allTransactions {
-		msgTx := tx.MsgTx()
-		// We could potentially overflow the accumulator so check for
-		// overflow.
-		lastSigOps := totalSigOps
-
-		isSSGen, _ := stake.IsSSGen(msgTx)
-		isCoinBase := IsCoinBaseTx(msgTx)
+	return nil
+}
 
-		totalSigOps += CountSigOps(tx, isCoinBase, isSSGen)
-		if totalSigOps < lastSigOps || totalSigOps > MaxSigOpsPerBlock {
-			str := fmt.Sprintf("block contains too many signature "+
-				"operations - got %v, max %v", totalSigOps,
-				MaxSigOpsPerBlock)
-			return ruleError(ErrTooManySigOps, str)
-		}
+// checkBlockSizeLimit ensures the serialized size of the passed block does not
+// exceed the maximum size configured via the MaxBlockSizeOverride chain
+// configuration option, if any.  This is a context-free check so that blocks
+// over the configured size, including orphans, are rejected before any further
+// processing.  The size permitted by the consensus rules, which also honors the
+// configured size, is enforced later once the position of the block within the
+// block chain is known.
+func (b *BlockChain) checkBlockSizeLimit(block *dcrutil.Block) error {
+	if b.blockSizeLimit == 0 {
+		return nil
 	}
 
-	// Blocks before stake validation height may only have 0x0001 as their
-	// VoteBits in the header.
-	if int64(header.Height) < chainParams.StakeValidationHeight {
-		if header.VoteBits != earlyVoteBitsValue {
-			str := fmt.Sprintf("pre stake validation height "+
-				"block %v contained an invalid votebits value"+
-				" (expected %v, got %v)", block.Hash(),
-				earlyVoteBitsValue, header.VoteBits)
-			return ruleError(ErrInvalidEarlyVoteBits, str)
-		}
+	serializedSize := int64(block.MsgBlock().SerializeSize())
+	if serializedSize > b.blockSizeLimit {
+		str := fmt.Sprintf("serialized block is larger than the "+
+			"configured maximum - got %d, max %d", serializedSize,
+			b.blockSizeLimit)
+		return ruleError(ErrBlockTooBig, str)
 	}
 
 	return nil
@@ -640,14 +843,15 @@ func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags B
 // sane before continuing with block processing.  These checks are context
 // free.
 func CheckBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, chainParams *chaincfg.Params) error {
-	return checkBlockSanity(block, timeSource, BFNone, chainParams)
+	return checkBlockSanity(block, timeSource, BFNone, chainParams, nil)
 }
 
 // CheckWorklessBlockSanity performs some preliminary checks on a block to
 // ensure it is sane before continuing with block processing.  These checks are
 // context free.
 func CheckWorklessBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, chainParams *chaincfg.Params) error {
-	return checkBlockSanity(block, timeSource, BFNoPoWCheck, chainParams)
+	return checkBlockSanity(block, timeSource, BFNoPoWCheck, chainParams,
+		nil)
 }
 
 // checkBlockHeaderContext peforms several validation checks on the block
@@ -657,8 +861,11 @@ func CheckWorklessBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource,
 //  - BFFastAdd: All checks except those involving comparing the header against
 //    the checkpoints are not performed.
 //
+// The passed rule overrider, which may be nil, is consulted for each failed
+// check.
+//
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode *blockNode, flags BehaviorFlags) error {
+func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode *blockNode, flags BehaviorFlags, overrider *ruleOverrider) error {
 	// The genesis block is valid by definition.
 	if prevNode == nil {
 		return nil
@@ -679,7 +886,11 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 			str := fmt.Sprintf("block difficulty of %d is not the"+
 				" expected "+"value of %d", blockDifficulty,
 				expDiff)
-			return ruleError(ErrUnexpectedDifficulty, str)
+			err := overrider.check(ruleError(ErrUnexpectedDifficulty,
+				str))
+			if err != nil {
+				return err
+			}
 		}
 
 		// Ensure the timestamp for the block header is after the
@@ -692,7 +903,10 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		if !header.Timestamp.After(medianTime) {
 			str := "block timestamp of %v is not after expected %v"
 			str = fmt.Sprintf(str, header.Timestamp, medianTime)
-			return ruleError(ErrTimeTooOld, str)
+			err := overrider.check(ruleError(ErrTimeTooOld, str))
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -705,7 +919,10 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 	if !b.verifyCheckpoint(blockHeight, &blockHash) {
 		str := fmt.Sprintf("block at height %d does not match "+
 			"checkpoint hash", blockHeight)
-		return ruleError(ErrBadCheckpoint, str)
+		err := overrider.check(ruleError(ErrBadCheckpoint, str))
+		if err != nil {
+			return err
+		}
 	}
 
 	// Find the previous checkpoint and prevent blocks which fork the main
@@ -713,67 +930,38 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 	// blocks which build off of old blocks that are likely at a much
 	// easier difficulty and therefore could be used to waste cache and
 	// disk space.
-	checkpointBlock, err := b.findPreviousCheckpoint()
-	if err != nil {
-		return err
-	}
-	if checkpointBlock != nil && blockHeight < checkpointBlock.Height() {
-		str := fmt.Sprintf("block at height %d forks the main chain "+
-			"before the previous checkpoint at height %d",
-			blockHeight, checkpointBlock.Height())
-		return ruleError(ErrForkTooOld, str)
-	}
-
-	if !fastAdd {
-		// Reject version 5 blocks for networks other than the main
-		// network once a majority of the network has upgraded.
-		if b.chainParams.Net != wire.MainNet && header.Version < 6 &&
-			b.isMajorityVersion(6, prevNode,
-				b.chainParams.BlockRejectNumRequired) {
-
-			str := "new blocks with version %d are no longer valid"
-			str = fmt.Sprintf(str, header.Version)
-			return ruleError(ErrBlockVersionTooOld, str)
-		}
-
-		// Reject version 4 blocks once a majority of the network has
-		// upgraded.
-		if header.Version < 5 && b.isMajorityVersion(5, prevNode,
-			b.chainParams.BlockRejectNumRequired) {
-
-			str := "new blocks with version %d are no longer valid"
-			str = fmt.Sprintf(str, header.Version)
-			return ruleError(ErrBlockVersionTooOld, str)
-		}
-
-		// Reject version 3 blocks once a majority of the network has
-		// upgraded.
-		if header.Version < 4 && b.isMajorityVersion(4, prevNode,
-			b.chainParams.BlockRejectNumRequired) {
-
-			str := "new blocks with version %d are no longer valid"
-			str = fmt.Sprintf(str, header.Version)
-			return ruleError(ErrBlockVersionTooOld, str)
+	//
+	// The check is skipped when the caller has already established the
+	// block is part of the main chain, such as when verifying blocks which
+	// are already stored.
+	if flags&bfNoForkTooOldCheck != bfNoForkTooOldCheck {
+		checkpointBlock, err := b.findPreviousCheckpoint()
+		if err != nil {
+			return err
 		}
-
-		// Reject version 2 blocks once a majority of the network has
-		// upgraded.
-		if header.Version < 3 && b.isMajorityVersion(3, prevNode,
-			b.chainParams.BlockRejectNumRequired) {
-
-			str := "new blocks with version %d are no longer valid"
-			str = fmt.Sprintf(str, header.Version)
-			return ruleError(ErrBlockVersionTooOld, str)
+		if checkpointBlock != nil && blockHeight < checkpointBlock.Height() {
+			str := fmt.Sprintf("block at height %d forks the main "+
+				"chain before the previous checkpoint at height %d",
+				blockHeight, checkpointBlock.Height())
+			err := overrider.check(ruleError(ErrForkTooOld, str))
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		// Reject version 1 blocks once a majority of the network has
-		// upgraded.
-		if header.Version < 2 && b.isMajorityVersion(2, prevNode,
-			b.chainParams.BlockRejectNumRequired) {
-
+	if !fastAdd {
+		// Reject blocks with versions older than a version a majority of
+		// the network has upgraded to.  No version is rejected until a
+		// majority has upgraded to version 2.
+		if b.isBlockVersionTooOld(header.Version, prevNode) {
 			str := "new blocks with version %d are no longer valid"
 			str = fmt.Sprintf(str, header.Version)
-			return ruleError(ErrBlockVersionTooOld, str)
+			err := overrider.check(ruleError(ErrBlockVersionTooOld,
+				str))
+			if err != nil {
+				return err
+			}
 		}
 
 		// Enforce the stake version in the header once a majority of
@@ -786,7 +974,11 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 				str := fmt.Sprintf("block stake version of %d "+
 					"is not the expected version of %d",
 					header.StakeVersion, expectedStakeVer)
-				return ruleError(ErrBadStakeVersion, str)
+				err := overrider.check(ruleError(
+					ErrBadStakeVersion, str))
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -1226,6 +1418,118 @@ func (b *BlockChain) CheckBlockStakeSanity(stakeValidationHeight int64, node *bl
 	return nil
 }
 
+// checkCoinbaseMaturity ensures the passed utxo entry, which is referenced by
+// an input of the transaction with the passed hash, is not from a coinbase or
+// a transaction that included an expiry which has not yet reached coinbase
+// maturity many blocks at the passed height.
+func checkCoinbaseMaturity(txHash, txInHash *chainhash.Hash, utxoEntry *UtxoEntry, txHeight int64, chainParams *chaincfg.Params) error {
+	coinbaseMaturity := int64(chainParams.CoinbaseMaturity)
+	originHeight := utxoEntry.BlockHeight()
+	blocksSincePrev := txHeight - originHeight
+	if utxoEntry.IsCoinBase() && blocksSincePrev < coinbaseMaturity {
+		str := fmt.Sprintf("tx %v tried to spend coinbase transaction "+
+			"%v from height %v at height %v before required "+
+			"maturity of %v blocks", txHash, txInHash, originHeight,
+			txHeight, coinbaseMaturity)
+		return ruleError(ErrImmatureSpend, str)
+	}
+	if utxoEntry.HasExpiry() && blocksSincePrev < coinbaseMaturity {
+		str := fmt.Sprintf("tx %v tried to spend transaction %v "+
+			"including an expiry from height %v at height %v "+
+			"before required maturity of %v blocks", txHash,
+			txInHash, originHeight, txHeight, coinbaseMaturity)
+		return ruleError(ErrExpiryTxSpentEarly, str)
+	}
+
+	return nil
+}
+
+// checkStakeOutputMaturity ensures the output at the passed index of the
+// passed utxo entry is not an OP_SSGEN, OP_SSRTX, or SStx change tagged output
+// which has not yet reached sstx change maturity many blocks at the passed
+// height.
+func checkStakeOutputMaturity(txInHash *chainhash.Hash, utxoEntry *UtxoEntry, originTxIndex uint32, txHeight int64, chainParams *chaincfg.Params) error {
+	stakeMaturity := int64(chainParams.SStxChangeMaturity)
+	originHeight := utxoEntry.BlockHeight()
+	blocksSincePrev := txHeight - originHeight
+	if blocksSincePrev >= stakeMaturity {
+		return nil
+	}
+
+	scriptClass := txscript.GetScriptClass(
+		utxoEntry.ScriptVersionByIndex(originTxIndex),
+		utxoEntry.PkScriptByIndex(originTxIndex))
+	switch scriptClass {
+	case txscript.StakeGenTy, txscript.StakeRevocationTy:
+		str := fmt.Sprintf("tried to spend OP_SSGEN or OP_SSRTX "+
+			"output from tx %v from height %v at height %v before "+
+			"required maturity of %v blocks", txInHash,
+			originHeight, txHeight, stakeMaturity)
+		return ruleError(ErrImmatureSpend, str)
+
+	case txscript.StakeSubChangeTy:
+		str := fmt.Sprintf("tried to spend SStx change output from tx "+
+			"%v from height %v at height %v before required "+
+			"maturity of %v blocks", txInHash, originHeight,
+			txHeight, stakeMaturity)
+		return ruleError(ErrImmatureSpend, str)
+	}
+
+	return nil
+}
+
+// CheckInputMaturity ensures none of the inputs of the passed transaction spend
+// outputs which have not yet reached their required maturity when the
+// transaction is included in a block at the passed height.  This consists of
+// outputs of coinbase transactions and transactions which include an expiry,
+// which require coinbase maturity many blocks, as well as OP_SSGEN, OP_SSRTX,
+// and SStx change tagged outputs, which require sstx change maturity many
+// blocks.  It allows callers such as wallets to avoid creating transactions a
+// node would reject due to spending immature outputs.
+//
+// The maturity of tickets spent by votes and revocations is not checked since
+// it is part of the stake validation performed by CheckTransactionInputs.
+//
+// A RuleError identifying the first input which spends an immature output is
+// returned.  A RuleError with the ErrMissingTx error code is returned when an
+// output referenced by an input is not available in the passed view.
+func CheckInputMaturity(tx *dcrutil.Tx, view *UtxoViewpoint, txHeight int64, params *chaincfg.Params) error {
+	msgTx := tx.MsgTx()
+	if IsCoinBaseTx(msgTx) {
+		return nil
+	}
+
+	txHash := tx.Hash()
+	isVote := stake.DetermineTxType(msgTx) == stake.TxTypeSSGen
+	for idx, txIn := range msgTx.TxIn {
+		// The stakebase input of a vote does not spend an output.
+		if isVote && idx == 0 {
+			continue
+		}
+
+		txInHash := &txIn.PreviousOutPoint.Hash
+		utxoEntry := view.LookupEntry(txInHash)
+		if utxoEntry == nil {
+			str := fmt.Sprintf("unable to find input transaction "+
+				"%v for transaction %v", txInHash, txHash)
+			return ruleError(ErrMissingTx, str)
+		}
+
+		err := checkCoinbaseMaturity(txHash, txInHash, utxoEntry,
+			txHeight, params)
+		if err != nil {
+			return err
+		}
+		err = checkStakeOutputMaturity(txInHash, utxoEntry,
+			txIn.PreviousOutPoint.Index, txHeight, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // CheckTransactionInputs performs a series of checks on the inputs to a
 // transaction to ensure they are valid.  An example of some of the checks
 // include verifying all inputs exist, ensuring the coinbase seasoning
@@ -1688,38 +1992,13 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *dcrutil.Tx, txHeight
 			}
 		}
 
-		// Ensure the transaction is not spending coins which have not
-		// yet reached the required coinbase maturity.
-		coinbaseMaturity := int64(chainParams.CoinbaseMaturity)
-		originHeight := utxoEntry.BlockHeight()
-		if utxoEntry.IsCoinBase() {
-			blocksSincePrev := txHeight - originHeight
-			if blocksSincePrev < coinbaseMaturity {
-				str := fmt.Sprintf("tx %v tried to spend "+
-					"coinbase transaction %v from height "+
-					"%v at height %v before required "+
-					"maturity of %v blocks", txHash,
-					txInHash, originHeight, txHeight,
-					coinbaseMaturity)
-				return 0, ruleError(ErrImmatureSpend, str)
-			}
-		}
-
-		// Ensure that the transaction is not spending coins from a
-		// transaction that included an expiry but which has not yet
-		// reached coinbase maturity many blocks.
-		if utxoEntry.HasExpiry() {
-			originHeight := utxoEntry.BlockHeight()
-			blocksSincePrev := txHeight - originHeight
-			if blocksSincePrev < coinbaseMaturity {
-				str := fmt.Sprintf("tx %v tried to spend "+
-					"transaction %v including an expiry "+
-					"from height %v at height %v before "+
-					"required maturity of %v blocks",
-					txHash, txInHash, originHeight,
-					txHeight, coinbaseMaturity)
-				return 0, ruleError(ErrExpiryTxSpentEarly, str)
-			}
+		// Ensure the transaction is not spending coins from a coinbase
+		// or a transaction that included an expiry which have not yet
+		// reached the required coinbase maturity.
+		err := checkCoinbaseMaturity(txHash, txInHash, utxoEntry,
+			txHeight, chainParams)
+		if err != nil {
+			return 0, err
 		}
 
 		// Ensure the transaction is not double spending coins.
@@ -1767,41 +2046,13 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *dcrutil.Tx, txHeight
 			}
 		}
 
-		// OP_SSGEN and OP_SSRTX tagged outputs can only be spent after
-		// coinbase maturity many blocks.
-		scriptClass := txscript.GetScriptClass(
-			utxoEntry.ScriptVersionByIndex(originTxIndex),
-			utxoEntry.PkScriptByIndex(originTxIndex))
-		if scriptClass == txscript.StakeGenTy ||
-			scriptClass == txscript.StakeRevocationTy {
-			originHeight := utxoEntry.BlockHeight()
-			blocksSincePrev := txHeight - originHeight
-			if blocksSincePrev <
-				int64(chainParams.SStxChangeMaturity) {
-				str := fmt.Sprintf("tried to spend OP_SSGEN or"+
-					" OP_SSRTX output from tx %v from "+
-					"height %v at height %v before "+
-					"required maturity of %v blocks",
-					txInHash, originHeight, txHeight,
-					coinbaseMaturity)
-				return 0, ruleError(ErrImmatureSpend, str)
-			}
-		}
-
-		// SStx change outputs may only be spent after sstx change
-		// maturity many blocks.
-		if scriptClass == txscript.StakeSubChangeTy {
-			originHeight := utxoEntry.BlockHeight()
-			blocksSincePrev := txHeight - originHeight
-			if blocksSincePrev <
-				int64(chainParams.SStxChangeMaturity) {
-				str := fmt.Sprintf("tried to spend SStx change"+
-					" output from tx %v from height %v at "+
-					"height %v before required maturity "+
-					"of %v blocks", txInHash, originHeight,
-					txHeight, chainParams.SStxChangeMaturity)
-				return 0, ruleError(ErrImmatureSpend, str)
-			}
+		// OP_SSGEN and OP_SSRTX tagged outputs as well as SStx change
+		// outputs may only be spent after sstx change maturity many
+		// blocks.
+		err = checkStakeOutputMaturity(txInHash, utxoEntry,
+			originTxIndex, txHeight, chainParams)
+		if err != nil {
+			return 0, err
 		}
 
 		// Ensure the transaction amounts are in range.  Each of the
@@ -1894,6 +2145,66 @@ func CheckTransactionInputs(subsidyCache *SubsidyCache, tx *dcrutil.Tx, txHeight
 	return txFeeInAtom, nil
 }
 
+// CalcTxFee returns the fee paid by the passed transaction, which is the total
+// value of the outputs the transaction spends, as provided by the passed view,
+// minus the total value of its outputs.  An error is returned when an output
+// the transaction spends is not available in the view or the transaction
+// spends more than its inputs.
+//
+// The input values are determined the same way CheckTransactionInputs
+// determines them.  In particular, coinbase transactions do not pay a fee and
+// the stakebase input of a vote contributes the vote subsidy it claims since
+// the claimed amount is required to be the vote subsidy by the consensus
+// rules.
+//
+// NOTE: No other validation is performed.  The transaction is expected to
+// otherwise be valid.
+func CalcTxFee(tx *dcrutil.Tx, view *UtxoViewpoint) (int64, error) {
+	msgTx := tx.MsgTx()
+
+	// Coinbase transactions have no inputs.
+	if IsCoinBaseTx(msgTx) {
+		return 0, nil
+	}
+
+	var totalAtomIn int64
+	isSSGen, _ := stake.IsSSGen(msgTx)
+	for idx, txIn := range msgTx.TxIn {
+		// The stakebase input of a vote does not spend an output, so
+		// add the claimed vote subsidy instead.
+		if isSSGen && idx == 0 {
+			totalAtomIn += txIn.ValueIn
+			continue
+		}
+
+		txInHash := &txIn.PreviousOutPoint.Hash
+		originTxIndex := txIn.PreviousOutPoint.Index
+		utxoEntry := view.LookupEntry(txInHash)
+		if utxoEntry == nil || utxoEntry.IsOutputSpent(originTxIndex) {
+			str := fmt.Sprintf("unable to find unspent output %v "+
+				"referenced from transaction %v",
+				txIn.PreviousOutPoint, tx.Hash())
+			return 0, ruleError(ErrMissingTx, str)
+		}
+		totalAtomIn += utxoEntry.AmountByIndex(originTxIndex)
+	}
+
+	var totalAtomOut int64
+	for _, txOut := range msgTx.TxOut {
+		totalAtomOut += txOut.Value
+	}
+
+	// Ensure the transaction does not spend more than its inputs.
+	if totalAtomIn < totalAtomOut {
+		str := fmt.Sprintf("total value of all transaction inputs for "+
+			"transaction %v is %v which is less than the amount "+
+			"spent of %v", tx.Hash(), totalAtomIn, totalAtomOut)
+		return 0, ruleError(ErrSpendTooHigh, str)
+	}
+
+	return totalAtomIn - totalAtomOut, nil
+}
+
 // CountSigOps returns the number of signature operations for all transaction
 // input and output scripts in the provided transaction.  This uses the
 // quicker, but imprecise, signature operation counting mechanism from
@@ -2406,6 +2717,17 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *dcrutil.Block, ut
 	if checkpoint != nil && node.height <= checkpoint.Height {
 		runScripts = false
 	}
+
+	// Don't run scripts for blocks that were already fully validated during
+	// this session, such as blocks that are being reconnected due to a
+	// reorganization.  All other checks are still performed since they
+	// depend on the context the block is being connected in.
+	if runScripts && b.validatedBlocks.Contains(&node.hash) {
+		log.Debugf("Skipping script validation for previously "+
+			"validated block %v", node.hash)
+		runScripts = false
+	}
+
 	var scriptFlags txscript.ScriptFlags
 	if runScripts {
 		var err error
@@ -2510,7 +2832,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *dcrutil.Block, ut
 
 	if runScripts {
 		err = checkBlockScripts(block, utxoView, false, scriptFlags,
-			b.sigCache)
+			b.sigCache, b.scriptValWorkers)
 		if err != nil {
 			log.Tracef("checkBlockScripts failed; error returned "+
 				"on txtreestake of cur block: %v", err)
@@ -2563,7 +2885,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *dcrutil.Block, ut
 
 	if runScripts {
 		err = checkBlockScripts(block, utxoView, true,
-			scriptFlags, b.sigCache)
+			scriptFlags, b.sigCache, b.scriptValWorkers)
 		if err != nil {
 			log.Tracef("checkBlockScripts failed; error returned "+
 				"on txtreeregular of cur block: %v", err)
@@ -2596,6 +2918,12 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *dcrutil.Block, ut
 	// transactions have been connected.
 	utxoView.SetBestHash(&node.hash)
 
+	// Remember the block passed script validation so it does not need to
+	// be run again if the block is connected again.
+	if runScripts {
+		b.validatedBlocks.Add(&node.hash)
+	}
+
 	return nil
 }
 
@@ -2618,6 +2946,118 @@ func (b *BlockChain) CheckConnectBlock(block *dcrutil.Block) error {
 		return ruleError(ErrMissingParent, err.Error())
 	}
 
+	return b.checkConnectBlockToNode(block, prevNode)
+}
+
+// CheckConnectBlockToParent performs the checks which depend on the position of
+// the passed block within the block chain followed by the same checks as
+// CheckConnectBlock against the utxo set as of the passed parent, which need
+// not be the current tip of the main chain or even part of it.  For example,
+// it may be used to determine whether a block which extends a competing side
+// chain would be valid were the chain reorganized to it.  Nothing is committed
+// regardless of the result.
+//
+// The passed parent must be the block the header of the passed block commits
+// to as its previous block, since the block could never be connected to any
+// other block.  It must also be known to the block chain instance.
+//
+// The flags are passed to the checks which depend on the position of the block
+// within the block chain.  See the documentation for checkBlockContext for how
+// the flags modify its behavior.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckConnectBlockToParent(block *dcrutil.Block, parent *chainhash.Hash, flags BehaviorFlags) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	prevNode, err := b.requestedParentNode(block, parent)
+	if err != nil {
+		return err
+	}
+
+	err = b.checkBlockContext(block, prevNode, flags, nil)
+	if err != nil {
+		return err
+	}
+
+	return b.checkConnectBlockToNode(block, prevNode)
+}
+
+// requestedParentNode returns the block node for the passed parent after
+// ensuring it is the block the header of the passed block commits to as its
+// previous block and that it is known to the block chain instance.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) requestedParentNode(block *dcrutil.Block, parent *chainhash.Hash) (*blockNode, error) {
+	if block.MsgBlock().Header.PrevBlock != *parent {
+		return nil, fmt.Errorf("block %v builds on block %v instead "+
+			"of the requested parent %v", block.Hash(),
+			block.MsgBlock().Header.PrevBlock, parent)
+	}
+	prevNode, err := b.getPrevNodeFromBlock(block)
+	if err != nil {
+		return nil, ruleError(ErrMissingParent, err.Error())
+	}
+	if prevNode == nil {
+		str := fmt.Sprintf("block %v does not have a parent",
+			block.Hash())
+		return nil, ruleError(ErrMissingParent, str)
+	}
+	return prevNode, nil
+}
+
+// CheckStakeTree performs only the checks related to the stake transaction
+// tree of the passed block which would be performed when accepting it on top
+// of the passed parent, which need not be the current tip of the main chain.
+// This includes the context free checks of the structure and number of the
+// tickets, votes, and revocations as well as the stake-specific contextual
+// rules, such as the stake difficulty, the ticket pool size and final state,
+// and that the votes are for the tickets selected by the lottery.  Notably,
+// the regular transaction tree is not validated and no scripts are executed,
+// which makes it much faster than CheckConnectBlockToParent for callers, such
+// as voting services, which are only interested in the stake tree.
+//
+// The passed parent must be the block the header of the passed block commits
+// to as its previous block and it must be known to the block chain instance.
+// A RuleError is returned when any of the checks fail.  Nothing is committed
+// regardless of the result.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckStakeTree(block *dcrutil.Block, parent *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	prevNode, err := b.requestedParentNode(block, parent)
+	if err != nil {
+		return err
+	}
+
+	err = checkStakeTreeSanity(block, b.chainParams, nil)
+	if err != nil {
+		return err
+	}
+
+	parentBlock, err := b.fetchBlockFromHash(parent)
+	if err != nil {
+		return err
+	}
+	newNode := newBlockNode(&block.MsgBlock().Header,
+		ticketsSpentInBlock(block),
+		ticketsRevokedInBlock(block),
+		voteBitsInBlock(block))
+	newNode.parent = prevNode
+	return b.CheckBlockStakeSanity(b.chainParams.StakeValidationHeight,
+		newNode, block, parentBlock, b.chainParams)
+}
+
+// checkConnectBlockToNode performs the checks described by CheckConnectBlock
+// against the utxo set as of the passed parent node, which may be on a side
+// chain or a main chain block prior to the current tip, in which case the utxo
+// set is temporarily reconstructed as of that node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) checkConnectBlockToNode(block *dcrutil.Block, prevNode *blockNode) error {
+	parentHash := prevNode.hash
 	newNode := newBlockNode(&block.MsgBlock().Header,
 		ticketsSpentInBlock(block),
 		ticketsRevokedInBlock(block),
@@ -2717,3 +3157,112 @@ func (b *BlockChain) CheckConnectBlock(block *dcrutil.Block) error {
 	view.SetBestHash(&parentHash)
 	return b.checkConnectBlock(newNode, block, view, &stxos)
 }
+
+// verifyStoredBlock performs the context free sanity checks and the checks
+// which depend on the position of the passed block, which must already be
+// stored in the main chain, within the block chain.  Since the block is part of
+// the main chain, it can't fork the main chain before the previous checkpoint,
+// so that check is skipped.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) verifyStoredBlock(block *dcrutil.Block, prevNode *blockNode, flags BehaviorFlags) error {
+	flags |= bfNoForkTooOldCheck
+	err := checkBlockSanity(block, b.timeSource, flags, b.chainParams, nil)
+	if err != nil {
+		return err
+	}
+
+	return b.checkBlockContext(block, prevNode, flags, nil)
+}
+
+// VerifyRange performs the context free sanity checks and the checks which
+// depend on the position of a block within the block chain, which are the same
+// checks ProcessBlock performs prior to accepting a block, against the blocks
+// already stored in the main chain in the given range of heights.  The range is
+// inclusive of the start height and exclusive of the end height.  The genesis
+// block is valid by definition, so it is skipped when it is part of the range.
+//
+// This provides a means to spot check the integrity of stored blocks without
+// a full reindex.  Since the transactions of the blocks have already been
+// applied to the utxo set, the checks which require the utxo set as of the
+// parent of a block are not performed.
+//
+// The first failure is returned.  When the failure is a RuleError, the error
+// code is retained and the description identifies the failed block and its
+// height.
+//
+// The flags are passed through to the checks and modify their behavior in the
+// same way they do for ProcessBlock.  The chain state is not modified.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) VerifyRange(startHeight, endHeight int64, flags BehaviorFlags) error {
+	// Ensure requested heights are sane.
+	if startHeight < 0 {
+		return fmt.Errorf("start height of verify range must not be "+
+			"less than zero - got %d", startHeight)
+	}
+	if endHeight < startHeight {
+		return fmt.Errorf("end height of verify range must not be less "+
+			"than the start height - got start %d, end %d",
+			startHeight, endHeight)
+	}
+
+	// The lock must be held for writes since the checks may load block
+	// nodes into the memory chain index.
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if endHeight > b.bestNode.height+1 {
+		return fmt.Errorf("end height of verify range must not be "+
+			"more than one past the best chain height %d - got %d",
+			b.bestNode.height, endHeight)
+	}
+	if startHeight == 0 {
+		startHeight = 1
+	}
+	if startHeight >= endHeight {
+		return nil
+	}
+
+	// Load the parent nodes of the blocks in the range.  Main chain nodes
+	// which are not in memory can only be loaded via their children, so
+	// they are loaded backwards starting from the best chain tip.
+	node, err := b.ancestorNode(b.bestNode, endHeight-1)
+	if err != nil {
+		return err
+	}
+	prevNodes := make([]*blockNode, endHeight-startHeight)
+	for i := len(prevNodes) - 1; i >= 0; i-- {
+		node, err = b.getPrevNodeFromNode(node)
+		if err != nil {
+			return err
+		}
+		prevNodes[i] = node
+	}
+
+	for i, prevNode := range prevNodes {
+		height := startHeight + int64(i)
+		var block *dcrutil.Block
+		err = b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByHeight(dbTx, height)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = b.verifyStoredBlock(block, prevNode, flags)
+		if err != nil {
+			if rerr, ok := err.(RuleError); ok {
+				str := fmt.Sprintf("block %v at height %d failed "+
+					"verification: %v", block.Hash(), height,
+					rerr.Description)
+				return ruleError(rerr.ErrorCode, str)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 	"compress/bzip2"
 	"encoding/gob"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/decred/dcrd/blockchain"
+	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/database"
@@ -2060,6 +2065,185 @@ func TestCheckBlockSanity(t *testing.T) {
 	}
 }
 
+// TestBlockTxCounts ensures the regular and stake transaction counts of a block
+// include every transaction in the respective tree.
+func TestBlockTxCounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		numRegular int
+		numStake   int
+	}{
+		{"empty block", 0, 0},
+		{"coinbase only", 1, 0},
+		{"regular and stake", 3, 7},
+	}
+
+	for i, test := range tests {
+		var msgBlock wire.MsgBlock
+		for j := 0; j < test.numRegular; j++ {
+			msgBlock.AddTransaction(wire.NewMsgTx())
+		}
+		for j := 0; j < test.numStake; j++ {
+			msgBlock.AddSTransaction(wire.NewMsgTx())
+		}
+
+		regular, stake := blockchain.BlockTxCounts(dcrutil.NewBlock(&msgBlock))
+		if regular != test.numRegular || stake != test.numStake {
+			t.Errorf("Test #%d (%s) unexpected counts - got %d "+
+				"regular and %d stake, want %d regular and %d "+
+				"stake", i, test.name, regular, stake,
+				test.numRegular, test.numStake)
+		}
+	}
+}
+
+// TestCheckDuplicateTransactions ensures blocks with duplicate transactions in
+// either transaction tree or across both of them are rejected.
+func TestCheckDuplicateTransactions(t *testing.T) {
+	// newTx returns a transaction which has a unique hash for each passed
+	// lock time.
+	newTx := func(lockTime uint32) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.LockTime = lockTime
+		return tx
+	}
+
+	tests := []struct {
+		name    string
+		regular []*wire.MsgTx
+		stake   []*wire.MsgTx
+		dupTx   *wire.MsgTx
+	}{{
+		name:    "unique transactions",
+		regular: []*wire.MsgTx{newTx(1), newTx(2)},
+		stake:   []*wire.MsgTx{newTx(3), newTx(4)},
+	}, {
+		name:    "duplicate regular transaction",
+		regular: []*wire.MsgTx{newTx(1), newTx(2), newTx(1)},
+		stake:   []*wire.MsgTx{newTx(3)},
+		dupTx:   newTx(1),
+	}, {
+		name:    "duplicate stake transaction",
+		regular: []*wire.MsgTx{newTx(1)},
+		stake:   []*wire.MsgTx{newTx(3), newTx(3)},
+		dupTx:   newTx(3),
+	}, {
+		name:    "duplicate across transaction trees",
+		regular: []*wire.MsgTx{newTx(1), newTx(2)},
+		stake:   []*wire.MsgTx{newTx(3), newTx(2)},
+		dupTx:   newTx(2),
+	}}
+
+	for i, test := range tests {
+		var msgBlock wire.MsgBlock
+		for _, tx := range test.regular {
+			msgBlock.AddTransaction(tx)
+		}
+		for _, tx := range test.stake {
+			msgBlock.AddSTransaction(tx)
+		}
+
+		err := blockchain.CheckDuplicateTransactions(dcrutil.NewBlock(&msgBlock))
+		if test.dupTx == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != blockchain.ErrDuplicateTx {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want "+
+				"%v", i, test.name, err, blockchain.ErrDuplicateTx)
+			continue
+		}
+		dupHash := test.dupTx.TxHash()
+		if !strings.Contains(rerr.Description, dupHash.String()) {
+			t.Errorf("Test #%d (%s) error does not identify duplicate "+
+				"hash %v: %v", i, test.name, dupHash, err)
+		}
+	}
+}
+
+// TestVerifyMerkleRoots ensures VerifyMerkleRoots accepts blocks whose header
+// commits to the merkle roots of both transaction trees and rejects those which
+// do not with an error identifying the mismatched tree and roots.
+func TestVerifyMerkleRoots(t *testing.T) {
+	// newBlock returns a block with valid merkle roots for transactions
+	// which have unique hashes.
+	newBlock := func() *wire.MsgBlock {
+		var msgBlock wire.MsgBlock
+		for i := uint32(0); i < 3; i++ {
+			tx := wire.NewMsgTx()
+			tx.LockTime = i
+			msgBlock.AddTransaction(tx)
+		}
+		stakeTx := wire.NewMsgTx()
+		stakeTx.LockTime = 3
+		msgBlock.AddSTransaction(stakeTx)
+		block := dcrutil.NewBlock(&msgBlock)
+		merkles := blockchain.BuildMerkleTreeStore(block.Transactions())
+		msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+		merkles = blockchain.BuildMerkleTreeStore(block.STransactions())
+		msgBlock.Header.StakeRoot = *merkles[len(merkles)-1]
+		return &msgBlock
+	}
+
+	badRoot := chainhash.Hash{0x01}
+	tests := []struct {
+		name    string
+		mutate  func(header *wire.BlockHeader)
+		errTree string
+	}{{
+		name:   "valid roots",
+		mutate: func(header *wire.BlockHeader) {},
+	}, {
+		name: "bad regular tree root",
+		mutate: func(header *wire.BlockHeader) {
+			header.MerkleRoot = badRoot
+		},
+		errTree: "block merkle root",
+	}, {
+		name: "bad stake tree root",
+		mutate: func(header *wire.BlockHeader) {
+			header.StakeRoot = badRoot
+		},
+		errTree: "block stake merkle root",
+	}}
+
+	for i, test := range tests {
+		msgBlock := newBlock()
+		wantRoot := msgBlock.Header.MerkleRoot
+		if test.errTree == "block stake merkle root" {
+			wantRoot = msgBlock.Header.StakeRoot
+		}
+		test.mutate(&msgBlock.Header)
+
+		err := blockchain.VerifyMerkleRoots(dcrutil.NewBlock(msgBlock))
+		if test.errTree == "" {
+			if err != nil {
+				t.Errorf("Test #%d (%s) unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != blockchain.ErrBadMerkleRoot {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want "+
+				"%v", i, test.name, err, blockchain.ErrBadMerkleRoot)
+			continue
+		}
+		for _, want := range []string{test.errTree, badRoot.String(),
+			wantRoot.String()} {
+
+			if !strings.Contains(rerr.Description, want) {
+				t.Errorf("Test #%d (%s) error %q does not contain "+
+					"%q", i, test.name, rerr.Description, want)
+			}
+		}
+	}
+}
+
 // TestCheckWorklessBlockSanity tests the context free workless block sanity
 // checks with blocks not on a chain.
 func TestCheckWorklessBlockSanity(t *testing.T) {
@@ -2072,6 +2256,80 @@ func TestCheckWorklessBlockSanity(t *testing.T) {
 	}
 }
 
+// TestMaxFutureBlockTime ensures blocks with a timestamp too far in the future
+// are rejected according to the tolerance specified by the chain parameters.
+func TestMaxFutureBlockTime(t *testing.T) {
+	// Use the parameters expected by the legacy data and create a copy
+	// with a custom tolerance.
+	params := legacyTestParams()
+	tolerance := 10 * time.Minute
+	customParams := cloneParams(params)
+	customParams.MaxFutureBlockTime = tolerance
+
+	// Load block 1 from the legacy data.
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	block1Bytes := blocks[1]
+
+	timeSource := blockchain.NewMedianTime()
+	tests := []struct {
+		name    string
+		params  *chaincfg.Params
+		offset  time.Duration
+		wantErr bool
+	}{{
+		name:    "custom tolerance, before boundary",
+		params:  customParams,
+		offset:  tolerance - time.Minute,
+		wantErr: false,
+	}, {
+		name:    "custom tolerance, after boundary",
+		params:  customParams,
+		offset:  tolerance + time.Minute,
+		wantErr: true,
+	}, {
+		name:    "default tolerance, after custom boundary",
+		params:  params,
+		offset:  tolerance + time.Minute,
+		wantErr: false,
+	}, {
+		name:   "default tolerance, after boundary",
+		params: params,
+		offset: time.Second*blockchain.MaxTimeOffsetSeconds +
+			time.Minute,
+		wantErr: true,
+	}}
+
+	for i, test := range tests {
+		msgBlock := new(wire.MsgBlock)
+		if err := msgBlock.FromBytes(block1Bytes); err != nil {
+			t.Fatalf("Test #%d (%s): unable to decode block: %v", i,
+				test.name, err)
+		}
+		timestamp := timeSource.AdjustedTime().Add(test.offset)
+		msgBlock.Header.Timestamp = time.Unix(timestamp.Unix(), 0)
+		block := dcrutil.NewBlock(msgBlock)
+
+		err := blockchain.CheckWorklessBlockSanity(block, timeSource,
+			test.params)
+		if !test.wantErr {
+			if err != nil {
+				t.Errorf("Test #%d (%s): unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != blockchain.ErrTimeTooNew {
+			t.Errorf("Test #%d (%s): unexpected error - got %v, "+
+				"want %v", i, test.name, err,
+				blockchain.ErrTimeTooNew)
+		}
+	}
+}
+
 // TestCheckBlockHeaderContext tests that genesis block passes context headers
 // because it's previousNode is nil.
 func TestCheckBlockHeaderContext(t *testing.T) {
@@ -2117,6 +2375,299 @@ func TestCheckBlockHeaderContext(t *testing.T) {
 	}
 }
 
+// TestPreAcceptFilter ensures that blocks are rejected when the pre-accept
+// filter provided via the chain configuration returns an error and are
+// processed normally otherwise.
+func TestPreAcceptFilter(t *testing.T) {
+	// Create a new database and chain instance with a filter that rejects
+	// blocks with the error stored in filterErr.
+	var filterErr error
+	var filterCalls int
+	chain, teardownFunc, err := chainSetupWithConfig(
+		"preacceptfilterunittest", &chaincfg.SimNetParams,
+		blockchain.Config{
+			PreAcceptFilter: func(block *dcrutil.Block) error {
+				filterCalls++
+				return filterErr
+			},
+		})
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Load the first block after the genesis block.
+	blocks, err := loadTestBlocks("reorgto179.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	block, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+
+	// Ensure an error that is not a rule error is converted to one with
+	// the appropriate error code.
+	filterErr = errors.New("rejected by local policy")
+	_, _, err = chain.ProcessBlock(block, blockchain.BFNone)
+	rerr, ok := err.(blockchain.RuleError)
+	if !ok || rerr.ErrorCode != blockchain.ErrRejectedByPolicy {
+		t.Fatalf("ProcessBlock: unexpected error - got %v, want %v",
+			err, blockchain.ErrRejectedByPolicy)
+	}
+
+	// Ensure rule errors returned by the filter are passed through as is.
+	filterErr = blockchain.RuleError{ErrorCode: blockchain.ErrBlockTooBig}
+	_, _, err = chain.ProcessBlock(block, blockchain.BFNone)
+	rerr, ok = err.(blockchain.RuleError)
+	if !ok || rerr.ErrorCode != blockchain.ErrBlockTooBig {
+		t.Fatalf("ProcessBlock: unexpected error - got %v, want %v",
+			err, blockchain.ErrBlockTooBig)
+	}
+
+	// Ensure the block is processed once the filter allows it.
+	filterErr = nil
+	_, _, err = chain.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	haveBlock, err := chain.HaveBlock(block.Hash())
+	if err != nil {
+		t.Fatalf("HaveBlock: unexpected error: %v", err)
+	}
+	if !haveBlock {
+		t.Fatal("HaveBlock: false for block allowed by the filter")
+	}
+	if filterCalls != 3 {
+		t.Fatalf("unexpected number of filter invocations - got %d, "+
+			"want 3", filterCalls)
+	}
+}
+
+// TestCheckProofOfWork ensures the standalone proof of work checks accept valid
+// headers and reject headers with targets out of range and hashes higher than
+// their target with the expected error codes.
+func TestCheckProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.SimNetParams
+	genesisHeader := params.GenesisBlock.Header
+
+	zeroTarget := genesisHeader
+	zeroTarget.Bits = 0
+
+	aboveLimit := genesisHeader
+	aboveLimit.Bits = blockchain.BigToCompact(new(big.Int).Lsh(
+		params.PowLimit, 1))
+
+	highHash := genesisHeader
+	highHash.Bits = 0x1d00ffff
+
+	tests := []struct {
+		name   string
+		header *wire.BlockHeader
+		err    error
+	}{
+		{"genesis", &genesisHeader, nil},
+		{"zero target", &zeroTarget,
+			blockchain.RuleError{ErrorCode: blockchain.ErrUnexpectedDifficulty}},
+		{"target above limit", &aboveLimit,
+			blockchain.RuleError{ErrorCode: blockchain.ErrUnexpectedDifficulty}},
+		{"hash above target", &highHash,
+			blockchain.RuleError{ErrorCode: blockchain.ErrHighHash}},
+	}
+	for i, test := range tests {
+		err := blockchain.CheckProofOfWork(test.header, params)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) CheckProofOfWork: unexpected "+
+					"error: %v", i, test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != test.err.(blockchain.RuleError).ErrorCode {
+			t.Errorf("Test #%d (%s) CheckProofOfWork: unexpected "+
+				"error - got %v, want %v", i, test.name, err,
+				test.err.(blockchain.RuleError).ErrorCode)
+		}
+	}
+}
+
+// TestCheckVoteCount ensures the vote count checks accept blocks with a valid
+// number of votes and reject blocks with too few or too many votes once the
+// stake validation height has been reached.
+func TestCheckVoteCount(t *testing.T) {
+	params := legacyTestParams()
+
+	if got, want := blockchain.RequiredVotesForBlock(params),
+		int(params.TicketsPerBlock)/2+1; got != want {
+		t.Fatalf("RequiredVotesForBlock: unexpected result - got %d, "+
+			"want %d", got, want)
+	}
+
+	// Load the test blocks.
+	blocks, err := loadTestBlocks("blocks0to168.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+
+	// withVotes returns a copy of the block at the passed height with its
+	// votes replaced by the given number of copies of its first vote.
+	withVotes := func(height int64, numVotes int) *dcrutil.Block {
+		bl, err := dcrutil.NewBlockFromBytes(blocks[height])
+		if err != nil {
+			t.Fatalf("NewBlockFromBytes error: %v", err)
+		}
+		msgBlock := bl.MsgBlock()
+		var vote *wire.MsgTx
+		var stxns []*wire.MsgTx
+		for _, stx := range msgBlock.STransactions {
+			if stake.DetermineTxType(stx) == stake.TxTypeSSGen {
+				if vote == nil {
+					vote = stx
+				}
+				continue
+			}
+			stxns = append(stxns, stx)
+		}
+		if numVotes > 0 && vote == nil {
+			t.Fatalf("block %d does not contain any votes", height)
+		}
+		for i := 0; i < numVotes; i++ {
+			stxns = append(stxns, vote)
+		}
+		msgBlock.STransactions = stxns
+		return dcrutil.NewBlock(msgBlock)
+	}
+
+	svh := params.StakeValidationHeight
+	required := blockchain.RequiredVotesForBlock(params)
+	maxVotes := int(params.TicketsPerBlock)
+	tests := []struct {
+		name  string
+		block *dcrutil.Block
+		err   error
+	}{
+		{"no votes before svh", withVotes(svh-1, 0), nil},
+		{"required votes at svh", withVotes(svh, required), nil},
+		{"max votes at svh", withVotes(svh, maxVotes), nil},
+		{"too few votes at svh", withVotes(svh, required-1),
+			blockchain.RuleError{ErrorCode: blockchain.ErrNotEnoughVotes}},
+		{"too many votes at svh", withVotes(svh, maxVotes+1),
+			blockchain.RuleError{ErrorCode: blockchain.ErrTooManyVotes}},
+	}
+	for i, test := range tests {
+		err := blockchain.CheckVoteCount(test.block, params)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) CheckVoteCount: unexpected "+
+					"error: %v", i, test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != test.err.(blockchain.RuleError).ErrorCode {
+			t.Errorf("Test #%d (%s) CheckVoteCount: unexpected "+
+				"error - got %v, want %v", i, test.name, err,
+				test.err.(blockchain.RuleError).ErrorCode)
+		}
+	}
+}
+
+// TestRuleOverrides ensures rule overrides provided via the chain
+// configuration are consulted for the error code they are registered for, that
+// accepting a failed check does not skip the remaining checks, and that they
+// are rejected for the main network.
+func TestRuleOverrides(t *testing.T) {
+	// Ensure overrides are not allowed on the main network.
+	var overrideErr error
+	var overrideCalls int
+	overrides := map[blockchain.ErrorCode]blockchain.RuleOverrideFunc{
+		blockchain.ErrTimeTooNew: func(block *dcrutil.Block, err blockchain.RuleError) error {
+			overrideCalls++
+			if overrideErr != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	_, _, err := chainSetupWithConfig("ruleoverridemainnet",
+		&chaincfg.MainNetParams, blockchain.Config{RuleOverrides: overrides})
+	if err == nil || !strings.Contains(err.Error(), "rule overrides") {
+		t.Fatalf("New: unexpected error for main network overrides - "+
+			"got %v", err)
+	}
+
+	chain, teardownFunc, err := chainSetupWithConfig("ruleoverrideunittest",
+		&chaincfg.SimNetParams, blockchain.Config{RuleOverrides: overrides})
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Load the first block after the genesis block and move its timestamp
+	// too far into the future.  Proof of work is not checked when
+	// processing it below since the modification changes its hash.
+	blocks, err := loadTestBlocks("reorgto179.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	block, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	msgBlock := block.MsgBlock()
+	msgBlock.Header.Timestamp = time.Unix(time.Now().Add(3*time.Hour).Unix(),
+		0)
+	block = dcrutil.NewBlock(msgBlock)
+
+	// Ensure the block is rejected when the override rejects it.
+	overrideErr = errors.New("reject")
+	_, _, err = chain.ProcessBlock(block, blockchain.BFNoPoWCheck)
+	rerr, ok := err.(blockchain.RuleError)
+	if !ok || rerr.ErrorCode != blockchain.ErrTimeTooNew {
+		t.Fatalf("ProcessBlock: unexpected error - got %v, want %v",
+			err, blockchain.ErrTimeTooNew)
+	}
+
+	// Ensure a block which fails a later check in the same stage of
+	// validation is still rejected by that check when the override accepts
+	// the earlier failed check.
+	overrideErr = nil
+	badSizeBlock, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	badSizeMsgBlock := badSizeBlock.MsgBlock()
+	badSizeMsgBlock.Header.Timestamp = msgBlock.Header.Timestamp
+	badSizeMsgBlock.Header.Size++
+	badSizeBlock = dcrutil.NewBlock(badSizeMsgBlock)
+	_, _, err = chain.ProcessBlock(badSizeBlock, blockchain.BFNoPoWCheck)
+	rerr, ok = err.(blockchain.RuleError)
+	if !ok || rerr.ErrorCode != blockchain.ErrWrongBlockSize {
+		t.Fatalf("ProcessBlock: unexpected error - got %v, want %v",
+			err, blockchain.ErrWrongBlockSize)
+	}
+
+	// Ensure the block is accepted once the override allows it.
+	_, _, err = chain.ProcessBlock(block, blockchain.BFNoPoWCheck)
+	if err != nil {
+		t.Fatalf("ProcessBlock: unexpected error: %v", err)
+	}
+	haveBlock, err := chain.HaveBlock(block.Hash())
+	if err != nil {
+		t.Fatalf("HaveBlock: unexpected error: %v", err)
+	}
+	if !haveBlock {
+		t.Fatal("HaveBlock: false for block allowed by the override")
+	}
+	if overrideCalls != 3 {
+		t.Fatalf("unexpected number of override invocations - got %d, "+
+			"want 3", overrideCalls)
+	}
+}
+
 // TestTxValidationErrors ensures certain malformed freestanding transactions
 // are rejected as as expected.
 func TestTxValidationErrors(t *testing.T) {
@@ -2148,6 +2699,132 @@ func TestTxValidationErrors(t *testing.T) {
 	}
 }
 
+// TestCheckInputMaturity ensures CheckInputMaturity rejects transactions which
+// spend outputs that have not reached their required maturity.
+func TestCheckInputMaturity(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	coinbaseMaturity := int64(params.CoinbaseMaturity)
+	stakeMaturity := int64(params.SStxChangeMaturity)
+
+	// newTx returns a transaction with a single input which spends the
+	// passed outpoint and a single output with the passed public key
+	// script.
+	p2pkhScript := append(append([]byte{txscript.OP_DUP, txscript.OP_HASH160,
+		txscript.OP_DATA_20}, bytes.Repeat([]byte{0x01}, 20)...),
+		txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+	newTx := func(prevOut *wire.OutPoint, expiry uint32, pkScript []byte) *dcrutil.Tx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil))
+		tx.AddTxOut(wire.NewTxOut(100, pkScript))
+		tx.Expiry = expiry
+		return dcrutil.NewTx(tx)
+	}
+	coinbasePrevOut := wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex, wire.TxTreeRegular)
+	regularPrevOut := wire.NewOutPoint(&chainhash.Hash{0x01}, 0,
+		wire.TxTreeRegular)
+	stakeGenScript := append([]byte{txscript.OP_SSGEN}, p2pkhScript...)
+	stakeChangeScript := append([]byte{txscript.OP_SSTXCHANGE},
+		p2pkhScript...)
+
+	const originHeight = 1000
+	tests := []struct {
+		name     string
+		origin   *dcrutil.Tx
+		height   int64
+		wantCode blockchain.ErrorCode
+		wantErr  bool
+	}{{
+		name:     "immature coinbase",
+		origin:   newTx(coinbasePrevOut, 0, p2pkhScript),
+		height:   originHeight + coinbaseMaturity - 1,
+		wantCode: blockchain.ErrImmatureSpend,
+		wantErr:  true,
+	}, {
+		name:   "mature coinbase",
+		origin: newTx(coinbasePrevOut, 0, p2pkhScript),
+		height: originHeight + coinbaseMaturity,
+	}, {
+		name:     "immature tx with expiry",
+		origin:   newTx(regularPrevOut, originHeight+10, p2pkhScript),
+		height:   originHeight + coinbaseMaturity - 1,
+		wantCode: blockchain.ErrExpiryTxSpentEarly,
+		wantErr:  true,
+	}, {
+		name:   "mature tx with expiry",
+		origin: newTx(regularPrevOut, originHeight+10, p2pkhScript),
+		height: originHeight + coinbaseMaturity,
+	}, {
+		name:   "regular tx in same block",
+		origin: newTx(regularPrevOut, 0, p2pkhScript),
+		height: originHeight,
+	}, {
+		name:     "immature OP_SSGEN output",
+		origin:   newTx(regularPrevOut, 0, stakeGenScript),
+		height:   originHeight + stakeMaturity - 1,
+		wantCode: blockchain.ErrImmatureSpend,
+		wantErr:  true,
+	}, {
+		name:   "mature OP_SSGEN output",
+		origin: newTx(regularPrevOut, 0, stakeGenScript),
+		height: originHeight + stakeMaturity,
+	}, {
+		name:     "immature SStx change output",
+		origin:   newTx(regularPrevOut, 0, stakeChangeScript),
+		height:   originHeight + stakeMaturity - 1,
+		wantCode: blockchain.ErrImmatureSpend,
+		wantErr:  true,
+	}, {
+		name:   "mature SStx change output",
+		origin: newTx(regularPrevOut, 0, stakeChangeScript),
+		height: originHeight + stakeMaturity,
+	}}
+
+	for i, test := range tests {
+		view := blockchain.NewUtxoViewpoint()
+		view.AddTxOuts(test.origin, originHeight, 0)
+		prevOut := wire.NewOutPoint(test.origin.Hash(), 0,
+			wire.TxTreeRegular)
+		if test.origin.MsgTx().TxOut[0].PkScript[0] != txscript.OP_DUP {
+			prevOut.Tree = wire.TxTreeStake
+		}
+		tx := newTx(prevOut, 0, p2pkhScript)
+
+		err := blockchain.CheckInputMaturity(tx, view, test.height,
+			params)
+		if !test.wantErr {
+			if err != nil {
+				t.Errorf("Test #%d (%s): unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok {
+			t.Errorf("Test #%d (%s): unexpected error type - got "+
+				"%T, want %T", i, test.name, err,
+				blockchain.RuleError{})
+			continue
+		}
+		if rerr.ErrorCode != test.wantCode {
+			t.Errorf("Test #%d (%s): unexpected error code - got "+
+				"%v, want %v", i, test.name, rerr.ErrorCode,
+				test.wantCode)
+		}
+	}
+
+	// Ensure a transaction which spends an output that is not in the view
+	// is rejected.
+	tx := newTx(regularPrevOut, 0, p2pkhScript)
+	err := blockchain.CheckInputMaturity(tx, blockchain.NewUtxoViewpoint(),
+		originHeight, params)
+	if rerr, ok := err.(blockchain.RuleError); !ok ||
+		rerr.ErrorCode != blockchain.ErrMissingTx {
+		t.Errorf("CheckInputMaturity: unexpected error for missing "+
+			"input - got %v, want %v", err, blockchain.ErrMissingTx)
+	}
+}
+
 // badBlock is an intentionally bad block that should fail the context-less
 // sanity checks.
 var badBlock = wire.MsgBlock{
@@ -2170,3 +2847,78 @@ var badBlock = wire.MsgBlock{
 	Transactions:  []*wire.MsgTx{},
 	STransactions: []*wire.MsgTx{},
 }
+
+// TestMaxBlockSizeOverride ensures blocks larger than the maximum size
+// configured via the chain configuration are rejected even though the
+// consensus rules allow them and that the override is rejected for the main
+// network.
+func TestMaxBlockSizeOverride(t *testing.T) {
+	// Ensure the override is not allowed on the main network.
+	_, _, err := chainSetupWithConfig("maxblocksizemainnet",
+		&chaincfg.MainNetParams, blockchain.Config{MaxBlockSizeOverride: 1})
+	if err == nil || !strings.Contains(err.Error(), "max block size") {
+		t.Fatalf("New: unexpected error for main network override - "+
+			"got %v", err)
+	}
+
+	// Load the first block after the genesis block.
+	blocks, err := loadTestBlocks("reorgto179.bz2")
+	if err != nil {
+		t.Fatalf("unable to load test blocks: %v", err)
+	}
+	block, err := dcrutil.NewBlockFromBytes(blocks[1])
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes error: %v", err)
+	}
+	blockSize := int64(block.MsgBlock().SerializeSize())
+
+	tests := []struct {
+		name     string
+		override int64
+		err      error
+	}{
+		{"one byte under block size", blockSize - 1,
+			blockchain.RuleError{ErrorCode: blockchain.ErrBlockTooBig}},
+		{"exactly block size", blockSize, nil},
+	}
+	for i, test := range tests {
+		dbName := fmt.Sprintf("maxblocksizeunittest%d", i)
+		chain, teardownFunc, err := chainSetupWithConfig(dbName,
+			&chaincfg.SimNetParams, blockchain.Config{
+				MaxBlockSizeOverride: test.override,
+			})
+		if err != nil {
+			t.Fatalf("Failed to setup chain instance: %v", err)
+		}
+
+		// Ensure the maximum block size reported by the chain honors
+		// the override.
+		maxSize, err := chain.MaxBlockSize()
+		if err != nil {
+			teardownFunc()
+			t.Fatalf("Test #%d (%s) MaxBlockSize: unexpected error: %v",
+				i, test.name, err)
+		}
+		if maxSize != test.override {
+			t.Errorf("Test #%d (%s) MaxBlockSize: unexpected size - "+
+				"got %d, want %d", i, test.name, maxSize,
+				test.override)
+		}
+
+		_, _, err = chain.ProcessBlock(block, blockchain.BFNone)
+		teardownFunc()
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) ProcessBlock: unexpected "+
+					"error: %v", i, test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(blockchain.RuleError)
+		if !ok || rerr.ErrorCode != test.err.(blockchain.RuleError).ErrorCode {
+			t.Errorf("Test #%d (%s) ProcessBlock: unexpected error - "+
+				"got %v, want %v", i, test.name, err,
+				test.err.(blockchain.RuleError).ErrorCode)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// defaultValidatedBlockCacheSize is the default maximum number of block hashes
+// the validated block cache holds when a size is not specified.
+const defaultValidatedBlockCacheSize = 100
+
+// validatedBlockCache houses a size-bounded set of hashes of blocks whose
+// transaction scripts are known to be valid.  It is used to avoid running the
+// scripts again when a block that was already validated is connected a
+// subsequent time, such as when it is disconnected and later reconnected
+// during reorganizations.
+//
+// Script validity only depends on the block itself and its ancestors, all of
+// which are committed to by the block hash, so it is safe to skip script
+// validation for a block in the cache.  However, the cache is intentionally
+// only kept in memory for the current session to remain conservative.
+//
+// Once the cache is full, the oldest entry is evicted to make room for a new
+// one.
+//
+// The cache is NOT safe for concurrent access.  It is protected by the chain
+// lock.
+type validatedBlockCache struct {
+	maxEntries int
+	hashes     map[chainhash.Hash]struct{}
+	order      []chainhash.Hash
+	next       int
+	hits       uint64
+}
+
+// newValidatedBlockCache returns a new validated block cache which holds at
+// most the passed number of block hashes.  The cache is disabled when the
+// passed size is zero.
+func newValidatedBlockCache(maxEntries int) *validatedBlockCache {
+	return &validatedBlockCache{
+		maxEntries: maxEntries,
+		hashes:     make(map[chainhash.Hash]struct{}, maxEntries),
+		order:      make([]chainhash.Hash, 0, maxEntries),
+	}
+}
+
+// Contains returns whether or not the passed block hash is in the cache.  The
+// hit counter is incremented when it is.
+func (c *validatedBlockCache) Contains(hash *chainhash.Hash) bool {
+	_, ok := c.hashes[*hash]
+	if ok {
+		c.hits++
+	}
+	return ok
+}
+
+// Add adds the passed block hash to the cache, evicting the oldest entry when
+// the cache is full.
+func (c *validatedBlockCache) Add(hash *chainhash.Hash) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	if _, ok := c.hashes[*hash]; ok {
+		return
+	}
+
+	// Add the entry while there is still room, otherwise replace the
+	// oldest entry.
+	if len(c.order) < c.maxEntries {
+		c.order = append(c.order, *hash)
+	} else {
+		delete(c.hashes, c.order[c.next])
+		c.order[c.next] = *hash
+		c.next = (c.next + 1) % c.maxEntries
+	}
+	c.hashes[*hash] = struct{}{}
+}
+
+// ValidatedBlockCacheHits returns the number of times script validation was
+// skipped for a block because it was already proven valid during the current
+// session.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ValidatedBlockCacheHits() uint64 {
+	b.chainLock.RLock()
+	hits := b.validatedBlocks.hits
+	b.chainLock.RUnlock()
+	return hits
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// TestValidatedBlockCache ensures the validated block cache tracks hits and
+// evicts the oldest entries once it is full.
+func TestValidatedBlockCache(t *testing.T) {
+	t.Parallel()
+
+	hashes := make([]chainhash.Hash, 4)
+	for i := range hashes {
+		hashes[i][0] = byte(i + 1)
+	}
+
+	// Ensure a disabled cache never contains anything.
+	disabled := newValidatedBlockCache(0)
+	disabled.Add(&hashes[0])
+	if disabled.Contains(&hashes[0]) {
+		t.Fatal("disabled cache contains added hash")
+	}
+
+	// Fill the cache and ensure all entries are present.  Adding an entry
+	// that already exists must not evict anything.
+	cache := newValidatedBlockCache(3)
+	for i := 0; i < 3; i++ {
+		cache.Add(&hashes[i])
+	}
+	cache.Add(&hashes[0])
+	for i := 0; i < 3; i++ {
+		if !cache.Contains(&hashes[i]) {
+			t.Fatalf("cache does not contain hash #%d", i)
+		}
+	}
+	if cache.hits != 3 {
+		t.Fatalf("unexpected number of hits - got %d, want 3", cache.hits)
+	}
+
+	// Ensure adding another entry evicts the oldest one.
+	cache.Add(&hashes[3])
+	if cache.Contains(&hashes[0]) {
+		t.Fatal("cache contains evicted hash")
+	}
+	for i := 1; i < 4; i++ {
+		if !cache.Contains(&hashes[i]) {
+			t.Fatalf("cache does not contain hash #%d", i)
+		}
+	}
+	if cache.hits != 6 {
+		t.Fatalf("unexpected number of hits - got %d, want 6", cache.hits)
+	}
+}
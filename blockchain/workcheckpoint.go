@@ -0,0 +1,142 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/database"
+)
+
+// WorkCheckpoint describes a block in the main chain along with the total
+// amount of work in the chain up to and including it.  It is intended to be
+// distributed to clients, such as light clients, which are then able to reject
+// any chain that contains the block yet does not commit to at least the same
+// amount of work.
+//
+// It is JSON serializable.  The hash is encoded as a string in the usual byte
+// reversed hex format and the cumulative work is encoded as a string of
+// hexadecimal digits so it is not subject to the precision limits of JSON
+// numbers.  The encoding is deterministic, so it is suitable for signing.
+type WorkCheckpoint struct {
+	Height         int64
+	Hash           chainhash.Hash
+	CumulativeWork *big.Int
+}
+
+// workCheckpointJSON is the JSON encoding of a WorkCheckpoint.
+type workCheckpointJSON struct {
+	Height         int64  `json:"height"`
+	Hash           string `json:"hash"`
+	CumulativeWork string `json:"cumulativework"`
+}
+
+// MarshalJSON marshals the work checkpoint to JSON.  This satisfies the
+// json.Marshaler interface.
+func (c *WorkCheckpoint) MarshalJSON() ([]byte, error) {
+	if c.CumulativeWork == nil || c.CumulativeWork.Sign() < 0 {
+		return nil, fmt.Errorf("work checkpoint at height %d does not "+
+			"have a valid cumulative work", c.Height)
+	}
+	return json.Marshal(&workCheckpointJSON{
+		Height:         c.Height,
+		Hash:           c.Hash.String(),
+		CumulativeWork: c.CumulativeWork.Text(16),
+	})
+}
+
+// UnmarshalJSON unmarshals a work checkpoint from JSON.  This satisfies the
+// json.Unmarshaler interface.
+func (c *WorkCheckpoint) UnmarshalJSON(b []byte) error {
+	var encoded workCheckpointJSON
+	if err := json.Unmarshal(b, &encoded); err != nil {
+		return err
+	}
+	hash, err := chainhash.NewHashFromStr(encoded.Hash)
+	if err != nil {
+		return err
+	}
+	work, ok := new(big.Int).SetString(encoded.CumulativeWork, 16)
+	if !ok || work.Sign() < 0 {
+		return fmt.Errorf("invalid cumulative work %q",
+			encoded.CumulativeWork)
+	}
+
+	c.Height = encoded.Height
+	c.Hash = *hash
+	c.CumulativeWork = work
+	return nil
+}
+
+// MinimumWorkCheckpoint returns a work checkpoint for the block at the passed
+// height in the main chain.  Callers typically choose a height some distance
+// behind the current tip to provide a rolling checkpoint which is unlikely to
+// be affected by reorganizations.
+//
+// Unlike the checkpoints defined by the chain parameters, the returned
+// checkpoint is not used by the chain instance itself.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) MinimumWorkCheckpoint(height int64) (*WorkCheckpoint, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if height < 0 || height > b.bestNode.height {
+		return nil, fmt.Errorf("height %d is outside of the main chain "+
+			"range [0, %d]", height, b.bestNode.height)
+	}
+	node, err := b.ancestorNode(b.bestNode, height)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, AssertError(fmt.Sprintf("unable to find main chain "+
+			"block at height %d", height))
+	}
+
+	return &WorkCheckpoint{
+		Height:         node.height,
+		Hash:           node.hash,
+		CumulativeWork: new(big.Int).Set(node.workSum),
+	}, nil
+}
+
+// WorkToReorg returns the cumulative work the current best chain contains
+// after the passed fork point, which must be a block in the main chain.  A
+// competing chain which forks from the main chain at the fork point must
+// contain more work than the returned amount after the fork point in order to
+// overtake the current best chain, so it quantifies the risk of blocks after
+// the fork point being reorganized out of the main chain.
+//
+// An error is returned when the fork point is not a block in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) WorkToReorg(forkPoint *chainhash.Hash) (*big.Int, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	var height int64
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		height, err = dbFetchHeightByHash(dbTx, forkPoint)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	node, err := b.ancestorNode(b.bestNode, height)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil || node.hash != *forkPoint {
+		return nil, AssertError(fmt.Sprintf("unable to find main chain "+
+			"block %v at height %d", forkPoint, height))
+	}
+
+	return new(big.Int).Sub(b.bestNode.workSum, node.workSum), nil
+}
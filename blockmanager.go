@@ -2568,14 +2568,23 @@ func newBlockManager(s *server, indexManager blockchain.IndexManager) (*blockMan
 	}
 
 	// Create a new block chain instance with the appropriate configuration.
+	// The transaction index is only provided as the transaction locator when
+	// it is enabled since a nil index would otherwise result in a non-nil
+	// interface.
+	var txLocator blockchain.TxLocator
+	if s.txIndex != nil {
+		txLocator = s.txIndex
+	}
 	var err error
 	bm.chain, err = blockchain.New(&blockchain.Config{
-		DB:            s.db,
-		ChainParams:   s.chainParams,
-		TimeSource:    s.timeSource,
-		Notifications: bm.handleNotifyMsg,
-		SigCache:      s.sigCache,
-		IndexManager:  indexManager,
+		DB:             s.db,
+		ChainParams:    s.chainParams,
+		TimeSource:     s.timeSource,
+		Notifications:  bm.handleNotifyMsg,
+		SigCache:       s.sigCache,
+		IndexManager:   indexManager,
+		TxLocator:      txLocator,
+		BlockCacheSize: cfg.BlockCacheSize,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,37 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chainhash
+
+import (
+	"bytes"
+	"sort"
+)
+
+// hashLess returns whether or not the first passed hash sorts before the second
+// one when comparing their bytes in order.  Note that this is the internal byte
+// order of the hashes as opposed to the byte-reversed order used when they are
+// displayed as strings.
+func hashLess(a, b *Hash) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
+
+// SortHashes sorts the passed slice of hashes in place by their bytes in
+// ascending order.  See HashesAreSorted for details on the ordering.
+func SortHashes(hashes []Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashLess(&hashes[i], &hashes[j])
+	})
+}
+
+// HashesAreSorted returns whether or not the passed slice of hashes is sorted
+// in ascending order by their bytes.  Note that this is the internal byte order
+// of the hashes as opposed to the byte-reversed order used when they are
+// displayed as strings, so hashes which are sorted will not necessarily appear
+// sorted when displayed.
+func HashesAreSorted(hashes []Hash) bool {
+	return sort.SliceIsSorted(hashes, func(i, j int) bool {
+		return hashLess(&hashes[i], &hashes[j])
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chainhash
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSortHashes ensures hashes are sorted in ascending order by their bytes
+// and that sorted slices are detected as such.
+func TestSortHashes(t *testing.T) {
+	// Hashes which differ only in their first or last bytes to ensure the
+	// sorting is based on the internal byte order as opposed to the
+	// byte-reversed order used when displaying them.
+	first := Hash{0x01, 0xff}
+	first[HashSize-1] = 0xff
+	second := Hash{0x02}
+	third := Hash{0x02}
+	third[HashSize-1] = 0x01
+
+	tests := []struct {
+		name     string
+		hashes   []Hash
+		isSorted bool
+		sorted   []Hash
+	}{
+		{
+			name:     "nil",
+			hashes:   nil,
+			isSorted: true,
+			sorted:   nil,
+		},
+		{
+			name:     "single",
+			hashes:   []Hash{second},
+			isSorted: true,
+			sorted:   []Hash{second},
+		},
+		{
+			name:     "already sorted",
+			hashes:   []Hash{first, second, third},
+			isSorted: true,
+			sorted:   []Hash{first, second, third},
+		},
+		{
+			name:     "reversed",
+			hashes:   []Hash{third, second, first},
+			isSorted: false,
+			sorted:   []Hash{first, second, third},
+		},
+		{
+			name:     "duplicates",
+			hashes:   []Hash{third, first, third, second},
+			isSorted: false,
+			sorted:   []Hash{first, second, third, third},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		hashes := make([]Hash, len(test.hashes))
+		copy(hashes, test.hashes)
+		if HashesAreSorted(hashes) != test.isSorted {
+			t.Errorf("Test #%d (%s) HashesAreSorted: unexpected "+
+				"result before sorting - got %v, want %v", i,
+				test.name, !test.isSorted, test.isSorted)
+			continue
+		}
+
+		SortHashes(hashes)
+		if len(hashes) != len(test.sorted) ||
+			(len(hashes) > 0 && !reflect.DeepEqual(hashes, test.sorted)) {
+			t.Errorf("Test #%d (%s) SortHashes: unexpected result - "+
+				"got %v, want %v", i, test.name, hashes,
+				test.sorted)
+			continue
+		}
+		if !HashesAreSorted(hashes) {
+			t.Errorf("Test #%d (%s) HashesAreSorted: false after "+
+				"sorting", i, test.name)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 )
 
 // TestGenesisBlock tests the genesis block of the main network for validity by
@@ -134,3 +135,59 @@ func TestSimNetGenesisBlock(t *testing.T) {
 			spew.Sdump(SimNetParams.GenesisHash))
 	}
 }
+
+// TestGenesisHeaderHash ensures the hash of the genesis block header of each
+// network, which is a single BLAKE-256 hash of the serialized header, matches
+// the well-known hash of the block.
+func TestGenesisHeaderHash(t *testing.T) {
+	tests := []struct {
+		params *Params
+		hash   string
+	}{
+		{&MainNetParams, "298e5cc3d985bfe7f81dc135f360abe089edd4396b86d2de66b0cef42b21d980"},
+		{&TestNet2Params, "4261602a9d07d80ad47621a64ba6a07754902e496777edc4ff581946bd7bc29c"},
+		{&SimNetParams, "5bec7567af40504e0994db3b573c186fffcc4edefe096ff2e58d00523bd7e8a6"},
+	}
+
+	for i, test := range tests {
+		want, err := chainhash.NewHashFromStr(test.hash)
+		if err != nil {
+			t.Errorf("Test #%d (%s): unexpected error parsing hash: %v",
+				i, test.params.Name, err)
+			continue
+		}
+
+		// Ensure the hash of the header alone matches the expected
+		// hash as well as the hash of the full block.
+		header := &test.params.GenesisBlock.Header
+		headerHash := header.BlockHash()
+		if headerHash != *want {
+			t.Errorf("Test #%d (%s): unexpected header hash - got "+
+				"%v, want %v", i, test.params.Name, headerHash, want)
+			continue
+		}
+		blockHash := test.params.GenesisBlock.BlockHash()
+		if blockHash != headerHash {
+			t.Errorf("Test #%d (%s): mismatched block hash - got "+
+				"%v, want %v", i, test.params.Name, blockHash,
+				headerHash)
+			continue
+		}
+
+		// Ensure the hash is the BLAKE-256 hash of the serialized
+		// header.
+		var buf bytes.Buffer
+		if err := header.Serialize(&buf); err != nil {
+			t.Errorf("Test #%d (%s): unexpected error serializing "+
+				"header: %v", i, test.params.Name, err)
+			continue
+		}
+		rawHash := chainhash.HashH(buf.Bytes())
+		if rawHash != headerHash {
+			t.Errorf("Test #%d (%s): mismatched serialized header "+
+				"hash - got %v, want %v", i, test.params.Name,
+				rawHash, headerHash)
+			continue
+		}
+	}
+}
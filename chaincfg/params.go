@@ -261,6 +261,11 @@ type Params struct {
 	// block.
 	TargetTimePerBlock time.Duration
 
+	// MaxFutureBlockTime is the maximum amount of time a block timestamp is
+	// allowed to be ahead of the current network adjusted time.  A value
+	// of zero results in the default of 2 hours.
+	MaxFutureBlockTime time.Duration
+
 	// WorkDiffAlpha is the stake difficulty EMA calculation alpha (smoothing)
 	// value. It is different from a normal EMA alpha. Closer to 1 --> smoother.
 	WorkDiffAlpha int64
@@ -480,6 +485,7 @@ var MainNetParams = Params{
 	MaximumBlockSizes:        []int{393216},
 	MaxTxSize:                393216,
 	TargetTimePerBlock:       time.Minute * 5,
+	MaxFutureBlockTime:       time.Hour * 2,
 	WorkDiffAlpha:            1,
 	WorkDiffWindowSize:       144,
 	WorkDiffWindows:          20,
@@ -687,6 +693,7 @@ var TestNet2Params = Params{
 	MaximumBlockSizes:        []int{1310720},
 	MaxTxSize:                1000000,
 	TargetTimePerBlock:       time.Minute * 2,
+	MaxFutureBlockTime:       time.Hour * 2,
 	WorkDiffAlpha:            1,
 	WorkDiffWindowSize:       144,
 	WorkDiffWindows:          20,
@@ -863,6 +870,7 @@ var SimNetParams = Params{
 	MaximumBlockSizes:        []int{1000000, 1310720},
 	MaxTxSize:                1000000,
 	TargetTimePerBlock:       time.Second,
+	MaxFutureBlockTime:       time.Hour * 2,
 	WorkDiffAlpha:            1,
 	WorkDiffWindowSize:       8,
 	WorkDiffWindows:          4,
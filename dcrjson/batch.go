@@ -0,0 +1,269 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// IDGenerator produces unique request ids suitable for use with JSON-RPC
+// requests.  The ids are monotonically increasing unsigned integers starting
+// from 1.  The zero value is ready to use and it is safe for concurrent access.
+type IDGenerator struct {
+	lastID uint64
+}
+
+// NextID returns the next unique request id.
+//
+// This function is safe for concurrent access.
+func (g *IDGenerator) NextID() uint64 {
+	return atomic.AddUint64(&g.lastID, 1)
+}
+
+// batchIDKey returns a key for the passed request id which is suitable for
+// use in a map.  Ids are keyed by their JSON encoding since ids which have
+// gone through a marshal and unmarshal round trip do not necessarily retain
+// their original Go type.  For example, a uint64 id will be unmarshalled as a
+// float64.
+func batchIDKey(id interface{}) (string, error) {
+	if !IsValidIDType(id) {
+		str := fmt.Sprintf("the id of type '%T' is invalid", id)
+		return "", makeError(ErrInvalidType, str)
+	}
+	key, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// isNotificationCmd returns whether or not the passed command is a registered
+// command which has been flagged as a notification.
+func isNotificationCmd(cmd interface{}) bool {
+	method, ok := defaultRegistry.lookupCmd(reflect.TypeOf(cmd))
+	if !ok {
+		return false
+	}
+	_, info, _ := defaultRegistry.lookupMethod(method)
+	return info.flags&UFNotification != 0
+}
+
+// BatchRequest houses a set of commands that are sent to an RPC server as a
+// single JSON-RPC batch request along with the bookkeeping necessary to map
+// the ids of the individual responses back to the originating command.
+type BatchRequest struct {
+	ids      *IDGenerator
+	requests []*Request
+	cmds     map[string]interface{}
+}
+
+// NewBatchRequest returns a new empty batch request.  Ids for commands added
+// to the batch are obtained from the provided generator which allows the ids
+// to remain unique across multiple batches.  A new generator is used when nil
+// is provided.
+func NewBatchRequest(ids *IDGenerator) *BatchRequest {
+	if ids == nil {
+		ids = new(IDGenerator)
+	}
+	return &BatchRequest{
+		ids:  ids,
+		cmds: make(map[string]interface{}),
+	}
+}
+
+// Add adds the passed command to the batch and returns the id assigned to it.
+// The provided command type must be a registered type.
+//
+// Commands which are registered with the UFNotification flag are notifications
+// per the JSON-RPC spec, so they are added with a nil id, will not have an
+// associated response, and are not tracked for correlation.
+func (b *BatchRequest) Add(cmd interface{}) (interface{}, error) {
+	var id interface{}
+	if !isNotificationCmd(cmd) {
+		id = b.ids.NextID()
+	}
+	request, err := newCmdRequest(id, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if id != nil {
+		key, err := batchIDKey(id)
+		if err != nil {
+			return nil, err
+		}
+		b.cmds[key] = cmd
+	}
+	b.requests = append(b.requests, request)
+	return id, nil
+}
+
+// Len returns the number of requests in the batch.
+func (b *BatchRequest) Len() int {
+	return len(b.requests)
+}
+
+// Requests returns the individual JSON-RPC requests that make up the batch in
+// the order they were added.
+func (b *BatchRequest) Requests() []*Request {
+	return b.requests
+}
+
+// Cmd returns the command that was added to the batch with the passed id.  The
+// id may either be the value returned from Add or the id of a response that
+// has been unmarshalled from JSON.  False is returned for unknown ids and for
+// the nil id of notifications.
+func (b *BatchRequest) Cmd(id interface{}) (interface{}, bool) {
+	if id == nil {
+		return nil, false
+	}
+	key, err := batchIDKey(id)
+	if err != nil {
+		return nil, false
+	}
+	cmd, ok := b.cmds[key]
+	return cmd, ok
+}
+
+// MarshalJSON marshals the batch to the JSON-RPC batch array form.  This
+// satisfies the json.Marshaler interface.
+func (b *BatchRequest) MarshalJSON() ([]byte, error) {
+	if len(b.requests) == 0 {
+		str := "a batch request must contain at least one request"
+		return nil, makeError(ErrInvalidType, str)
+	}
+	return json.Marshal(b.requests)
+}
+
+// BatchResponse houses the responses to a JSON-RPC batch request.  When it is
+// associated with the batch request the responses are for, the originating
+// command of each response may be looked up by its id.
+type BatchResponse struct {
+	Responses []*Response
+	batch     *BatchRequest
+}
+
+// NewBatchResponse returns a new empty batch response which is associated with
+// the passed batch request.  The batch response is typically populated by
+// unmarshalling a JSON-RPC batch response into it.
+func NewBatchResponse(batch *BatchRequest) *BatchResponse {
+	return &BatchResponse{batch: batch}
+}
+
+// Cmd returns the command that originated the passed response.  False is
+// returned when the batch response is not associated with a batch request,
+// or the response has a null id or an id which is not part of the associated
+// batch.  Notably, the JSON-RPC spec requires a null id when the server is
+// unable to determine the id of the request, such as for parse errors.
+func (r *BatchResponse) Cmd(response *Response) (interface{}, bool) {
+	if r.batch == nil || response.ID == nil {
+		return nil, false
+	}
+	return r.batch.Cmd(*response.ID)
+}
+
+// MarshalJSON marshals the batch response to the JSON-RPC batch array form.
+// This satisfies the json.Marshaler interface.
+func (r *BatchResponse) MarshalJSON() ([]byte, error) {
+	if r.Responses == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r.Responses)
+}
+
+// UnmarshalJSON unmarshals a JSON-RPC batch array form into the batch
+// response.  This satisfies the json.Unmarshaler interface.
+//
+// The JSON-RPC spec allows a server to respond to an invalid batch with a
+// single response object rather than an array, so that case is also accepted
+// and results in a batch response with a single response.
+func (r *BatchResponse) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		var response Response
+		if err := json.Unmarshal(b, &response); err != nil {
+			return err
+		}
+		r.Responses = []*Response{&response}
+		return nil
+	}
+
+	var responses []*Response
+	if err := json.Unmarshal(b, &responses); err != nil {
+		return err
+	}
+	r.Responses = responses
+	return nil
+}
+
+// MarshalBatchResponse marshals the passed responses to a JSON-RPC batch
+// response byte slice that is suitable for transmission to a JSON-RPC client.
+// Per the JSON-RPC spec, nothing is to be returned to the client when a batch
+// consists solely of notifications, so nil is returned when there are no
+// responses.
+func MarshalBatchResponse(responses []*Response) ([]byte, error) {
+	if len(responses) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(responses)
+}
+
+// SplitBatch splits the passed raw JSON-RPC batch request into multiple batch
+// requests that each contain at most maxPerChunk of the original requests.
+// The requests are not otherwise parsed and are copied to the resulting
+// batches unmodified and in their original order, so each resulting batch is
+// valid as long as the individual requests in the original batch are valid.
+// This allows a server or proxy to limit the size of batches it processes
+// without needing to understand the individual commands.
+//
+// An error is returned when the passed data is not a JSON array, the array is
+// empty since the JSON-RPC spec considers an empty batch invalid, or
+// maxPerChunk is not positive.
+func SplitBatch(raw []byte, maxPerChunk int) ([][]byte, error) {
+	if maxPerChunk <= 0 {
+		str := fmt.Sprintf("the maximum number of requests per chunk "+
+			"must be positive - got %d", maxPerChunk)
+		return nil, makeError(ErrInvalidType, str)
+	}
+
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		str := "a batch request must be a JSON array"
+		return nil, makeError(ErrInvalidType, str)
+	}
+	var requests []json.RawMessage
+	if err := json.Unmarshal(trimmed, &requests); err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		str := "a batch request must contain at least one request"
+		return nil, makeError(ErrInvalidType, str)
+	}
+
+	chunks := make([][]byte, 0, (len(requests)+maxPerChunk-1)/maxPerChunk)
+	for len(requests) > 0 {
+		n := maxPerChunk
+		if n > len(requests) {
+			n = len(requests)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, request := range requests[:n] {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(request)
+		}
+		buf.WriteByte(']')
+		chunks = append(chunks, buf.Bytes())
+		requests = requests[n:]
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/decred/dcrd/dcrjson"
+)
+
+// TestIDGenerator ensures the IDGenerator produces unique ids even when used
+// concurrently.
+func TestIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	const numGoroutines = 8
+	const numIDs = 100
+
+	var gen dcrjson.IDGenerator
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	seen := make(map[uint64]struct{}, numGoroutines*numIDs)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numIDs; j++ {
+				id := gen.NextID()
+				mtx.Lock()
+				seen[id] = struct{}{}
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != numGoroutines*numIDs {
+		t.Fatalf("unexpected number of unique ids - got %d, want %d",
+			len(seen), numGoroutines*numIDs)
+	}
+	if _, ok := seen[0]; ok {
+		t.Fatal("generated id of 0")
+	}
+}
+
+// TestBatchRequest ensures batch requests marshal to the expected JSON-RPC
+// batch array form and track the ids of the commands added to them.
+func TestBatchRequest(t *testing.T) {
+	t.Parallel()
+
+	batch := dcrjson.NewBatchRequest(nil)
+	countCmd := dcrjson.NewGetBlockCountCmd()
+	hashCmd := dcrjson.NewGetBlockHashCmd(123)
+	ntfn := dcrjson.NewBlockDisconnectedNtfn("header")
+
+	countID, err := batch.Add(countCmd)
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	ntfnID, err := batch.Add(ntfn)
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	hashID, err := batch.Add(hashCmd)
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if ntfnID != nil {
+		t.Fatalf("Add: notification assigned non-nil id %v", ntfnID)
+	}
+	if batch.Len() != 3 {
+		t.Fatalf("Len: unexpected length - got %d, want 3", batch.Len())
+	}
+
+	marshalled, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("MarshalJSON: unexpected error: %v", err)
+	}
+	expected := `[{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":1},` +
+		`{"jsonrpc":"1.0","method":"blockdisconnected","params":["header"],"id":null},` +
+		`{"jsonrpc":"1.0","method":"getblockhash","params":[123],"id":2}]`
+	if string(marshalled) != expected {
+		t.Fatalf("MarshalJSON: mismatched result - got %s, want %s",
+			marshalled, expected)
+	}
+
+	tests := []struct {
+		name  string
+		id    interface{}
+		cmd   interface{}
+		found bool
+	}{
+		{"getblockcount id", countID, countCmd, true},
+		{"getblockhash id", hashID, hashCmd, true},
+		{"round tripped float64 id", float64(2), hashCmd, true},
+		{"string id", "1", nil, false},
+		{"unknown id", 3, nil, false},
+		{"notification id", nil, nil, false},
+		{"invalid id type", true, nil, false},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		cmd, found := batch.Cmd(test.id)
+		if found != test.found {
+			t.Errorf("Test #%d (%s) mismatched found - got %v, "+
+				"want %v", i, test.name, found, test.found)
+			continue
+		}
+		if cmd != test.cmd {
+			t.Errorf("Test #%d (%s) mismatched cmd - got %v, "+
+				"want %v", i, test.name, cmd, test.cmd)
+		}
+	}
+
+	// Ensure an empty batch is not allowed to be marshalled since the spec
+	// considers it an invalid request.
+	_, err = json.Marshal(dcrjson.NewBatchRequest(nil))
+	if err == nil {
+		t.Fatal("MarshalJSON: did not receive error for empty batch")
+	}
+
+	// Ensure adding an unregistered command fails.
+	_, err = batch.Add(&struct{}{})
+	wantErr := dcrjson.Error{Code: dcrjson.ErrUnregisteredMethod}
+	if jerr, ok := err.(dcrjson.Error); !ok || jerr.Code != wantErr.Code {
+		t.Fatalf("Add: did not receive expected error - got %v "+
+			"(%[1]T), want %v (%[2]T)", err, wantErr)
+	}
+}
+
+// TestBatchIDsUnique ensures batches which share an id generator assign ids
+// which are unique across all of the batches.
+func TestBatchIDsUnique(t *testing.T) {
+	t.Parallel()
+
+	var gen dcrjson.IDGenerator
+	batch1 := dcrjson.NewBatchRequest(&gen)
+	batch2 := dcrjson.NewBatchRequest(&gen)
+	id1, err := batch1.Add(dcrjson.NewGetBlockCountCmd())
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	id2, err := batch2.Add(dcrjson.NewGetBlockCountCmd())
+	if err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if id1 == id2 {
+		t.Fatalf("batches sharing a generator assigned the same id %v",
+			id1)
+	}
+}
+
+// TestBatchResponse ensures batch responses unmarshal from and marshal to the
+// JSON-RPC batch array form and correlate responses with their originating
+// command.
+func TestBatchResponse(t *testing.T) {
+	t.Parallel()
+
+	batch := dcrjson.NewBatchRequest(nil)
+	countCmd := dcrjson.NewGetBlockCountCmd()
+	hashCmd := dcrjson.NewGetBlockHashCmd(123)
+	if _, err := batch.Add(countCmd); err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if _, err := batch.Add(hashCmd); err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+
+	// Responses are intentionally out of order and include a response with
+	// a null id as the spec allows.
+	rawResponses := `[{"result":"abcd","error":null,"id":2},` +
+		`{"result":null,"error":{"code":-32700,"message":"Parse error"},"id":null},` +
+		`{"result":1000,"error":null,"id":1}]`
+	resp := dcrjson.NewBatchResponse(batch)
+	if err := json.Unmarshal([]byte(rawResponses), resp); err != nil {
+		t.Fatalf("UnmarshalJSON: unexpected error: %v", err)
+	}
+	if len(resp.Responses) != 3 {
+		t.Fatalf("UnmarshalJSON: unexpected number of responses - got "+
+			"%d, want 3", len(resp.Responses))
+	}
+
+	wantCmds := []interface{}{hashCmd, nil, countCmd}
+	for i, response := range resp.Responses {
+		cmd, found := resp.Cmd(response)
+		if found != (wantCmds[i] != nil) || cmd != wantCmds[i] {
+			t.Errorf("Cmd #%d: mismatched cmd - got %v (found %v), "+
+				"want %v", i, cmd, found, wantCmds[i])
+		}
+	}
+	if resp.Responses[1].Error == nil {
+		t.Fatal("UnmarshalJSON: error for null id response not decoded")
+	}
+
+	marshalled, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("MarshalJSON: unexpected error: %v", err)
+	}
+	if string(marshalled) != rawResponses {
+		t.Fatalf("MarshalJSON: mismatched result - got %s, want %s",
+			marshalled, rawResponses)
+	}
+
+	// Ensure a single response object, which the spec allows servers to
+	// return for an invalid batch, is accepted.
+	single := `{"result":null,"error":{"code":-32600,"message":"Invalid request"},"id":null}`
+	resp = dcrjson.NewBatchResponse(batch)
+	if err := json.Unmarshal([]byte(single), resp); err != nil {
+		t.Fatalf("UnmarshalJSON: unexpected error: %v", err)
+	}
+	wantErr := &dcrjson.RPCError{
+		Code:    dcrjson.ErrRPCInvalidRequest.Code,
+		Message: dcrjson.ErrRPCInvalidRequest.Message,
+	}
+	if len(resp.Responses) != 1 ||
+		!reflect.DeepEqual(resp.Responses[0].Error, wantErr) {
+		t.Fatalf("UnmarshalJSON: unexpected single response %v",
+			resp.Responses)
+	}
+
+	// Ensure a batch response that is not associated with a batch request
+	// does not match any commands.
+	unassociated := new(dcrjson.BatchResponse)
+	if err := json.Unmarshal([]byte(rawResponses), unassociated); err != nil {
+		t.Fatalf("UnmarshalJSON: unexpected error: %v", err)
+	}
+	if _, found := unassociated.Cmd(unassociated.Responses[0]); found {
+		t.Fatal("Cmd: unassociated batch response matched a command")
+	}
+}
+
+// TestMarshalBatchResponse ensures MarshalBatchResponse produces the batch
+// array form and nothing at all when there are no responses.
+func TestMarshalBatchResponse(t *testing.T) {
+	t.Parallel()
+
+	marshalled, err := dcrjson.MarshalBatchResponse(nil)
+	if err != nil {
+		t.Fatalf("MarshalBatchResponse: unexpected error: %v", err)
+	}
+	if marshalled != nil {
+		t.Fatalf("MarshalBatchResponse: unexpected result for no "+
+			"responses - got %s, want nil", marshalled)
+	}
+
+	response, err := dcrjson.NewResponse(1, []byte("true"), nil)
+	if err != nil {
+		t.Fatalf("NewResponse: unexpected error: %v", err)
+	}
+	marshalled, err = dcrjson.MarshalBatchResponse([]*dcrjson.Response{response})
+	if err != nil {
+		t.Fatalf("MarshalBatchResponse: unexpected error: %v", err)
+	}
+	expected := `[{"result":true,"error":null,"id":1}]`
+	if string(marshalled) != expected {
+		t.Fatalf("MarshalBatchResponse: mismatched result - got %s, "+
+			"want %s", marshalled, expected)
+	}
+}
+
+// TestSplitBatch ensures raw batch requests are split into chunks of the
+// expected sizes while preserving the original requests and their order.
+func TestSplitBatch(t *testing.T) {
+	t.Parallel()
+
+	req1 := `{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":1}`
+	req2 := `{"jsonrpc":"1.0","method":"getblockhash","params":[123],"id":2}`
+	req3 := `{"jsonrpc":"1.0","method":"blockdisconnected","params":["header"],"id":null}`
+	batch := "[" + req1 + ", " + req2 + ",\n" + req3 + "]"
+
+	tests := []struct {
+		name        string
+		raw         string
+		maxPerChunk int
+		chunks      []string
+		err         bool
+	}{
+		{
+			name:        "one per chunk",
+			raw:         batch,
+			maxPerChunk: 1,
+			chunks:      []string{"[" + req1 + "]", "[" + req2 + "]", "[" + req3 + "]"},
+		},
+		{
+			name:        "uneven chunks",
+			raw:         batch,
+			maxPerChunk: 2,
+			chunks:      []string{"[" + req1 + "," + req2 + "]", "[" + req3 + "]"},
+		},
+		{
+			name:        "single chunk",
+			raw:         batch,
+			maxPerChunk: 3,
+			chunks:      []string{"[" + req1 + "," + req2 + "," + req3 + "]"},
+		},
+		{
+			name:        "max exceeds batch size",
+			raw:         " " + batch + " ",
+			maxPerChunk: 10,
+			chunks:      []string{"[" + req1 + "," + req2 + "," + req3 + "]"},
+		},
+		{
+			name:        "single request object",
+			raw:         req1,
+			maxPerChunk: 1,
+			err:         true,
+		},
+		{
+			name:        "empty batch",
+			raw:         "[]",
+			maxPerChunk: 1,
+			err:         true,
+		},
+		{
+			name:        "malformed batch",
+			raw:         "[" + req1,
+			maxPerChunk: 1,
+			err:         true,
+		},
+		{
+			name:        "zero max per chunk",
+			raw:         batch,
+			maxPerChunk: 0,
+			err:         true,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		chunks, err := dcrjson.SplitBatch([]byte(test.raw), test.maxPerChunk)
+		if (err != nil) != test.err {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want "+
+				"error %v", i, test.name, err, test.err)
+			continue
+		}
+		if len(chunks) != len(test.chunks) {
+			t.Errorf("Test #%d (%s) unexpected number of chunks - "+
+				"got %d, want %d", i, test.name, len(chunks),
+				len(test.chunks))
+			continue
+		}
+		for j, chunk := range chunks {
+			if string(chunk) != test.chunks[j] {
+				t.Errorf("Test #%d (%s) mismatched chunk %d - got "+
+					"%s, want %s", i, test.name, j, chunk,
+					test.chunks[j])
+			}
+		}
+	}
+}
@@ -87,6 +87,32 @@ func NewDecodeRawTransactionCmd(hexTx string) *DecodeRawTransactionCmd {
 	}
 }
 
+// RawTxPrevOut models the data needed to describe a previous output spent by
+// an input of a transaction.  The script is hex encoded and the amount is in
+// DCR.
+type RawTxPrevOut struct {
+	ScriptPubKey string  `json:"scriptpubkey"`
+	Amount       float64 `json:"amount"`
+}
+
+// DecodeRawTransactionWithPrevoutCmd defines the
+// decoderawtransactionwithprevout JSON-RPC command.  The previous outputs
+// describe the outputs spent by the inputs of the transaction in the same
+// order as the inputs.
+type DecodeRawTransactionWithPrevoutCmd struct {
+	HexTx    string
+	PrevOuts []RawTxPrevOut
+}
+
+// NewDecodeRawTransactionWithPrevoutCmd returns a new instance which can be
+// used to issue a decoderawtransactionwithprevout JSON-RPC command.
+func NewDecodeRawTransactionWithPrevoutCmd(hexTx string, prevOuts []RawTxPrevOut) *DecodeRawTransactionWithPrevoutCmd {
+	return &DecodeRawTransactionWithPrevoutCmd{
+		HexTx:    hexTx,
+		PrevOuts: prevOuts,
+	}
+}
+
 // DecodeScriptCmd defines the decodescript JSON-RPC command.
 type DecodeScriptCmd struct {
 	HexScript string
@@ -113,6 +139,40 @@ func NewEstimateFeeCmd(numBlocks int64) *EstimateFeeCmd {
 	}
 }
 
+// EstimateSmartFeeMode defines the type used in the estimatesmartfee JSON-RPC
+// command for the estimate mode field.
+type EstimateSmartFeeMode string
+
+const (
+	// EstimateModeEconomical indicates the estimate should be more
+	// responsive to short-term drops in the prevailing fee market and thus
+	// potentially return a lower fee rate.
+	EstimateModeEconomical EstimateSmartFeeMode = "economical"
+
+	// EstimateModeConservative indicates the estimate should consider a
+	// longer history of blocks and thus potentially return a higher fee
+	// rate that is more likely to be sufficient for the desired target.
+	EstimateModeConservative EstimateSmartFeeMode = "conservative"
+)
+
+// EstimateSmartFeeCmd defines the estimatesmartfee JSON-RPC command.
+type EstimateSmartFeeCmd struct {
+	ConfTarget   int64
+	EstimateMode *EstimateSmartFeeMode `jsonrpcdefault:"\"conservative\""`
+}
+
+// NewEstimateSmartFeeCmd returns a new instance which can be used to issue an
+// estimatesmartfee JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewEstimateSmartFeeCmd(confTarget int64, mode *EstimateSmartFeeMode) *EstimateSmartFeeCmd {
+	return &EstimateSmartFeeCmd{
+		ConfTarget:   confTarget,
+		EstimateMode: mode,
+	}
+}
+
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -378,6 +438,23 @@ func NewGetHeadersCmd(blockLocators string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetHeadersInRangeCmd defines the getheadersinrange JSON-RPC command.  It
+// requests the headers of the main chain blocks from the start height up to,
+// but not including, the end height.
+type GetHeadersInRangeCmd struct {
+	StartHeight int64 `json:"startheight"`
+	EndHeight   int64 `json:"endheight"`
+}
+
+// NewGetHeadersInRangeCmd returns a new instance which can be used to issue a
+// getheadersinrange JSON-RPC command.
+func NewGetHeadersInRangeCmd(startHeight, endHeight int64) *GetHeadersInRangeCmd {
+	return &GetHeadersInRangeCmd{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
 // GetMempoolInfoCmd defines the getmempoolinfo JSON-RPC command.
 type GetMempoolInfoCmd struct{}
 
@@ -562,8 +639,28 @@ func NewHelpCmd(command *string) *HelpCmd {
 	}
 }
 
-// PingCmd defines the ping JSON-RPC command.
-type PingCmd struct{}
+// InvalidateBlockCmd defines the invalidateblock JSON-RPC command.
+//
+// It is an operator tool intended for recovering from forks by marking the
+// block with the provided hash, along with all of its descendants, as invalid
+// so the chain reorganizes away from it.  The command returns a null result.
+type InvalidateBlockCmd struct {
+	BlockHash string
+}
+
+// NewInvalidateBlockCmd returns a new instance which can be used to issue an
+// invalidateblock JSON-RPC command.
+func NewInvalidateBlockCmd(blockHash string) *InvalidateBlockCmd {
+	return &InvalidateBlockCmd{
+		BlockHash: blockHash,
+	}
+}
+
+// PingCmd defines the ping JSON-RPC command.  The optional timestamp is echoed
+// back in the result so callers may measure the round trip time of the command.
+type PingCmd struct {
+	Timestamp *int64
+}
 
 // NewPingCmd returns a new instance which can be used to issue a ping JSON-RPC
 // command.
@@ -571,6 +668,32 @@ func NewPingCmd() *PingCmd {
 	return &PingCmd{}
 }
 
+// NewPingCmdWithTimestamp returns a new instance which can be used to issue a
+// ping JSON-RPC command with a timestamp to echo back in the result.
+func NewPingCmdWithTimestamp(timestamp int64) *PingCmd {
+	return &PingCmd{
+		Timestamp: &timestamp,
+	}
+}
+
+// ReconsiderBlockCmd defines the reconsiderblock JSON-RPC command.
+//
+// It is an operator tool which undoes the effects of a previous
+// invalidateblock command for the block with the provided hash, along with all
+// of its ancestors and descendants, so they are considered for the best chain
+// again.  The command returns a null result.
+type ReconsiderBlockCmd struct {
+	BlockHash string
+}
+
+// NewReconsiderBlockCmd returns a new instance which can be used to issue a
+// reconsiderblock JSON-RPC command.
+func NewReconsiderBlockCmd(blockHash string) *ReconsiderBlockCmd {
+	return &ReconsiderBlockCmd{
+		BlockHash: blockHash,
+	}
+}
+
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -724,8 +847,10 @@ func init() {
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("decoderawtransactionwithprevout", (*DecodeRawTransactionWithPrevoutCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
 	MustRegisterCmd("estimatefee", (*EstimateFeeCmd)(nil), flags)
+	MustRegisterCmd("estimatesmartfee", (*EstimateSmartFeeCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
@@ -741,6 +866,7 @@ func init() {
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("getheadersinrange", (*GetHeadersInRangeCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
@@ -754,7 +880,9 @@ func init() {
 	MustRegisterCmd("gettxoutsetinfo", (*GetTxOutSetInfoCmd)(nil), flags)
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
+	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
+	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
@@ -23,6 +23,8 @@ func TestChainSvrCmds(t *testing.T) {
 	t.Parallel()
 
 	testID := int(1)
+	economical := dcrjson.EstimateModeEconomical
+	conservative := dcrjson.EstimateModeConservative
 	tests := []struct {
 		name         string
 		newCmd       func() (interface{}, error)
@@ -91,6 +93,24 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decoderawtransaction","params":["123"],"id":1}`,
 			unmarshalled: &dcrjson.DecodeRawTransactionCmd{HexTx: "123"},
 		},
+		{
+			name: "decoderawtransactionwithprevout",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("decoderawtransactionwithprevout", "123",
+					`[{"scriptpubkey":"76a914","amount":1.5}]`)
+			},
+			staticCmd: func() interface{} {
+				prevOuts := []dcrjson.RawTxPrevOut{
+					{ScriptPubKey: "76a914", Amount: 1.5},
+				}
+				return dcrjson.NewDecodeRawTransactionWithPrevoutCmd("123", prevOuts)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"decoderawtransactionwithprevout","params":["123",[{"scriptpubkey":"76a914","amount":1.5}]],"id":1}`,
+			unmarshalled: &dcrjson.DecodeRawTransactionWithPrevoutCmd{
+				HexTx:    "123",
+				PrevOuts: []dcrjson.RawTxPrevOut{{ScriptPubKey: "76a914", Amount: 1.5}},
+			},
+		},
 		{
 			name: "decodescript",
 			newCmd: func() (interface{}, error) {
@@ -102,6 +122,34 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &dcrjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "estimatesmartfee",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("estimatesmartfee", 6)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewEstimateSmartFeeCmd(6, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
+			unmarshalled: &dcrjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: &conservative,
+			},
+		},
+		{
+			name: "estimatesmartfee optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("estimatesmartfee", 6, "economical")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewEstimateSmartFeeCmd(6, &economical)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6,"economical"],"id":1}`,
+			unmarshalled: &dcrjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: &economical,
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -234,6 +282,21 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: dcrjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockheader optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getblockheader", "123", false)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetBlockHeaderCmd("123",
+					dcrjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheader","params":["123",false],"id":1}`,
+			unmarshalled: &dcrjson.GetBlockHeaderCmd{
+				Hash:    "123",
+				Verbose: dcrjson.Bool(false),
+			},
+		},
 		{
 			name: "getblocksubsidy",
 			newCmd: func() (interface{}, error) {
@@ -397,6 +460,20 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
 			unmarshalled: &dcrjson.GetInfoCmd{},
 		},
+		{
+			name: "getheadersinrange",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getheadersinrange", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetHeadersInRangeCmd(100, 200)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getheadersinrange","params":[100,200],"id":1}`,
+			unmarshalled: &dcrjson.GetHeadersInRangeCmd{
+				StartHeight: 100,
+				EndHeight:   200,
+			},
+		},
 		{
 			name: "getmempoolinfo",
 			newCmd: func() (interface{}, error) {
@@ -655,6 +732,19 @@ func TestChainSvrCmds(t *testing.T) {
 				Command: dcrjson.String("getblock"),
 			},
 		},
+		{
+			name: "invalidateblock",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("invalidateblock", "123")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewInvalidateBlockCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"invalidateblock","params":["123"],"id":1}`,
+			unmarshalled: &dcrjson.InvalidateBlockCmd{
+				BlockHash: "123",
+			},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -666,6 +756,32 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"ping","params":[],"id":1}`,
 			unmarshalled: &dcrjson.PingCmd{},
 		},
+		{
+			name: "ping optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("ping", 1500000000000)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewPingCmdWithTimestamp(1500000000000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"ping","params":[1500000000000],"id":1}`,
+			unmarshalled: &dcrjson.PingCmd{
+				Timestamp: dcrjson.Int64(1500000000000),
+			},
+		},
+		{
+			name: "reconsiderblock",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("reconsiderblock", "123")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewReconsiderBlockCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"reconsiderblock","params":["123"],"id":1}`,
+			unmarshalled: &dcrjson.ReconsiderBlockCmd{
+				BlockHash: "123",
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
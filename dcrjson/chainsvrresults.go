@@ -5,7 +5,11 @@
 
 package dcrjson
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/decred/dcrd/wire"
+)
 
 // GetBlockHeaderVerboseResult models the data from the getblockheader command when
 // the verbose flag is set.  When the verbose flag is not set, getblockheader
@@ -29,6 +33,7 @@ type GetBlockHeaderVerboseResult struct {
 	Size          uint32  `json:"size"`
 	Time          int64   `json:"time"`
 	Nonce         uint32  `json:"nonce"`
+	ExtraData     string  `json:"extradata"`
 	StakeVersion  uint32  `json:"stakeversion"`
 	Difficulty    float64 `json:"difficulty"`
 	NextHash      string  `json:"nextblockhash,omitempty"`
@@ -82,6 +87,18 @@ type DecodeScriptResult struct {
 	P2sh      string   `json:"p2sh"`
 }
 
+// EstimateSmartFeeResult models the data returned from the estimatesmartfee
+// command.  The fee rate is in DCR/kB and is omitted along with the blocks
+// when an estimate could not be made, in which case the errors field explains
+// why.  Blocks is the number of blocks the estimate is valid for, which may be
+// higher than the requested confirmation target when there is not enough data
+// to produce an estimate for the target itself.
+type EstimateSmartFeeResult struct {
+	FeeRate *float64 `json:"feerate,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Blocks  int64    `json:"blocks"`
+}
+
 // GetAddedNodeInfoResultAddr models the data of the addresses portion of the
 // getaddednodeinfo command.
 type GetAddedNodeInfoResultAddr struct {
@@ -141,8 +158,14 @@ type GetBlockTemplateResult struct {
 	// CoinbaseTxn or CoinbaseValue must be specified, but not both.
 	// GBT has been modified from the Bitcoin semantics to include
 	// the header rather than various components which are all part
-	// of the header anyway.
+	// of the header anyway.  The most commonly needed of those
+	// components are also provided individually for convenience.
 	Header        string                     `json:"header"`
+	Version       int32                      `json:"version"`
+	Height        int64                      `json:"height"`
+	PreviousHash  string                     `json:"previousblockhash"`
+	Bits          string                     `json:"bits"`
+	CurTime       int64                      `json:"curtime"`
 	SigOpLimit    int64                      `json:"sigoplimit,omitempty"`
 	SizeLimit     int64                      `json:"sizelimit,omitempty"`
 	Transactions  []GetBlockTemplateResultTx `json:"transactions"`
@@ -248,11 +271,33 @@ type GetTxOutResult struct {
 	Coinbase      bool               `json:"coinbase"`
 }
 
+// PingResult models the data returned from the ping command.  The timestamp is
+// the one provided with the command, if any, and the time is the time of the
+// node when it handled the command in milliseconds since the epoch.
+type PingResult struct {
+	Timestamp  *int64 `json:"timestamp,omitempty"`
+	TimeMillis int64  `json:"timemillis"`
+}
+
+// GetNetTotalsUploadTarget models the upload target data returned as part of
+// the getnettotals command by nodes which limit the number of bytes they upload
+// over a period of time.
+type GetNetTotalsUploadTarget struct {
+	TimeFrame             int64  `json:"timeframe"`
+	Target                uint64 `json:"target"`
+	TargetReached         bool   `json:"target_reached"`
+	ServeHistoricalBlocks bool   `json:"serve_historical_blocks"`
+	BytesLeftInCycle      uint64 `json:"bytes_left_in_cycle"`
+	TimeLeftInCycle       int64  `json:"time_left_in_cycle"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
+// The upload target is only set by nodes which report it.
 type GetNetTotalsResult struct {
-	TotalBytesRecv uint64 `json:"totalbytesrecv"`
-	TotalBytesSent uint64 `json:"totalbytessent"`
-	TimeMillis     int64  `json:"timemillis"`
+	TotalBytesRecv uint64                    `json:"totalbytesrecv"`
+	TotalBytesSent uint64                    `json:"totalbytessent"`
+	TimeMillis     int64                     `json:"timemillis"`
+	UploadTarget   *GetNetTotalsUploadTarget `json:"uploadtarget,omitempty"`
 }
 
 // ScriptSig models a signature script.  It is defined separately since it only
@@ -453,6 +498,7 @@ type GetMiningInfoResult struct {
 	HashesPerSec     int64   `json:"hashespersec"`
 	NetworkHashPS    int64   `json:"networkhashps"`
 	PooledTx         uint64  `json:"pooledtx"`
+	TicketPoolSize   int64   `json:"ticketpoolsize"`
 	TestNet          bool    `json:"testnet"`
 }
 
@@ -534,6 +580,19 @@ type TxRawDecodeResult struct {
 	Vout     []Vout `json:"vout"`
 }
 
+// TxRawDecodeWithPrevOutResult models the data from the
+// decoderawtransactionwithprevout command.  It is the same as the result of the
+// decoderawtransaction command except the inputs include the addresses and
+// value of the previous outputs they spend.
+type TxRawDecodeWithPrevOutResult struct {
+	Txid     string       `json:"txid"`
+	Version  int32        `json:"version"`
+	Locktime uint32       `json:"locktime"`
+	Expiry   uint32       `json:"expiry"`
+	Vin      []VinPrevOut `json:"vin"`
+	Vout     []Vout       `json:"vout"`
+}
+
 // ValidateAddressChainResult models the data returned by the chain server
 // validateaddress command.
 type ValidateAddressChainResult struct {
@@ -546,3 +605,16 @@ type ValidateAddressChainResult struct {
 type GetHeadersResult struct {
 	Headers []string `json:"headers"`
 }
+
+// GetHeadersInRangeResult models the data returned by the chain server
+// getheadersinrange command.  The headers are concatenated serialized block
+// headers in hex as produced by EncodeConcatenatedHeaders.
+type GetHeadersInRangeResult struct {
+	Headers string `json:"headers"`
+}
+
+// DecodeHeaders returns the block headers of the result decoded with
+// DecodeConcatenatedHeaders.
+func (r *GetHeadersInRangeResult) DecodeHeaders() ([]wire.BlockHeader, error) {
+	return DecodeConcatenatedHeaders(r.Headers)
+}
@@ -7,6 +7,7 @@ package dcrjson_test
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/decred/dcrd/dcrjson"
@@ -88,3 +89,515 @@ func TestChainSvrCustomResults(t *testing.T) {
 		}
 	}
 }
+
+// TestGetBlockTemplateResult ensures a getblocktemplate result, including the
+// stake transactions, unmarshals into the typed result as expected.
+func TestGetBlockTemplateResult(t *testing.T) {
+	t.Parallel()
+
+	coinbaseValue := int64(1000)
+	marshalled := `{"header":"00","version":4,"height":100,` +
+		`"previousblockhash":"000000000000000000000000000000000000000000000000000000000000000a",` +
+		`"bits":"207fffff","curtime":1500000000,"sigoplimit":5000,` +
+		`"sizelimit":393216,"transactions":[{"data":"01","hash":"ab",` +
+		`"depends":[],"fee":10,"sigops":1,"txtype":"regular"}],` +
+		`"stransactions":[{"data":"02","hash":"cd","depends":[1],` +
+		`"fee":0,"sigops":2,"txtype":"vote"}],"coinbasevalue":1000,` +
+		`"target":"7fffff0000000000000000000000000000000000000000000000000000000000"}`
+	expected := dcrjson.GetBlockTemplateResult{
+		Header:       "00",
+		Version:      4,
+		Height:       100,
+		PreviousHash: "000000000000000000000000000000000000000000000000000000000000000a",
+		Bits:         "207fffff",
+		CurTime:      1500000000,
+		SigOpLimit:   5000,
+		SizeLimit:    393216,
+		Transactions: []dcrjson.GetBlockTemplateResultTx{{
+			Data:    "01",
+			Hash:    "ab",
+			Depends: []int64{},
+			Fee:     10,
+			SigOps:  1,
+			TxType:  "regular",
+		}},
+		STransactions: []dcrjson.GetBlockTemplateResultTx{{
+			Data:    "02",
+			Hash:    "cd",
+			Depends: []int64{1},
+			Fee:     0,
+			SigOps:  2,
+			TxType:  "vote",
+		}},
+		CoinbaseValue: &coinbaseValue,
+		Target:        "7fffff0000000000000000000000000000000000000000000000000000000000",
+	}
+
+	var result dcrjson.GetBlockTemplateResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+
+	// Ensure the result marshals back to the same data.
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetMiningInfoResult ensures a getmininginfo result, including the stake
+// specific fields, round trips through JSON as expected.
+func TestGetMiningInfoResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"blocks":100,"currentblocksize":1000,` +
+		`"currentblocktx":5,"difficulty":1.5,"stakedifficulty":200000000,` +
+		`"errors":"","generate":false,"genproclimit":-1,` +
+		`"hashespersec":0,"networkhashps":12345,"pooledtx":3,` +
+		`"ticketpoolsize":40960,"testnet":true}`
+	expected := dcrjson.GetMiningInfoResult{
+		Blocks:           100,
+		CurrentBlockSize: 1000,
+		CurrentBlockTx:   5,
+		Difficulty:       1.5,
+		StakeDifficulty:  200000000,
+		GenProcLimit:     -1,
+		NetworkHashPS:    12345,
+		PooledTx:         3,
+		TicketPoolSize:   40960,
+		TestNet:          true,
+	}
+
+	var result dcrjson.GetMiningInfoResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+
+	// Ensure the result marshals back to the same data.
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetNetTotalsResult ensures the getnettotals result round trips through
+// JSON both with and without the optional upload target.
+func TestGetNetTotalsResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		marshalled string
+		expected   dcrjson.GetNetTotalsResult
+	}{{
+		name: "without upload target",
+		marshalled: `{"totalbytesrecv":1000,"totalbytessent":2000,` +
+			`"timemillis":1500000000000}`,
+		expected: dcrjson.GetNetTotalsResult{
+			TotalBytesRecv: 1000,
+			TotalBytesSent: 2000,
+			TimeMillis:     1500000000000,
+		},
+	}, {
+		name: "with upload target",
+		marshalled: `{"totalbytesrecv":1000,"totalbytessent":2000,` +
+			`"timemillis":1500000000000,"uploadtarget":{` +
+			`"timeframe":86400,"target":5000,"target_reached":false,` +
+			`"serve_historical_blocks":true,` +
+			`"bytes_left_in_cycle":3000,"time_left_in_cycle":600}}`,
+		expected: dcrjson.GetNetTotalsResult{
+			TotalBytesRecv: 1000,
+			TotalBytesSent: 2000,
+			TimeMillis:     1500000000000,
+			UploadTarget: &dcrjson.GetNetTotalsUploadTarget{
+				TimeFrame:             86400,
+				Target:                5000,
+				ServeHistoricalBlocks: true,
+				BytesLeftInCycle:      3000,
+				TimeLeftInCycle:       600,
+			},
+		},
+	}}
+
+	for i, test := range tests {
+		var result dcrjson.GetNetTotalsResult
+		err := json.Unmarshal([]byte(test.marshalled), &result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Test #%d (%s) unexpected unmarshalled result - "+
+				"got %+v, want %+v", i, test.name, result,
+				test.expected)
+			continue
+		}
+
+		remarshalled, err := json.Marshal(&result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if string(remarshalled) != test.marshalled {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - got "+
+				"%s, want %s", i, test.name, remarshalled,
+				test.marshalled)
+		}
+	}
+}
+
+// TestSearchRawTransactionsResult ensures a page of searchrawtransactions
+// results as served with the vinextra option set unmarshals as expected.
+func TestSearchRawTransactionsResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `[{"txid":"123","version":1,"locktime":0,"vin":[{` +
+		`"txid":"456","vout":1,"tree":0,"amountin":2.5,` +
+		`"blockheight":100,"blockindex":2,"scriptSig":{"asm":"","hex":""},` +
+		`"prevOut":{"addresses":["DsAddress"],"value":2.5},` +
+		`"sequence":4294967295}],"vout":[],"blockhash":"789",` +
+		`"confirmations":10,"time":1500000000,"blocktime":1500000000},` +
+		`{"txid":"abc","version":1,"locktime":0,"vin":[],"vout":[]}]`
+	amountIn := 2.5
+	blockHeight := uint32(100)
+	blockIndex := uint32(2)
+	expected := []dcrjson.SearchRawTransactionsResult{{
+		Txid:     "123",
+		Version:  1,
+		LockTime: 0,
+		Vin: []dcrjson.VinPrevOut{{
+			Txid:        "456",
+			Vout:        1,
+			Tree:        0,
+			AmountIn:    &amountIn,
+			BlockHeight: &blockHeight,
+			BlockIndex:  &blockIndex,
+			ScriptSig:   &dcrjson.ScriptSig{},
+			PrevOut: &dcrjson.PrevOut{
+				Addresses: []string{"DsAddress"},
+				Value:     2.5,
+			},
+			Sequence: 4294967295,
+		}},
+		Vout:          []dcrjson.Vout{},
+		BlockHash:     "789",
+		Confirmations: 10,
+		Time:          1500000000,
+		Blocktime:     1500000000,
+	}, {
+		Txid:     "abc",
+		Version:  1,
+		LockTime: 0,
+		Vin:      []dcrjson.VinPrevOut{},
+		Vout:     []dcrjson.Vout{},
+	}}
+
+	var result []dcrjson.SearchRawTransactionsResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+}
+
+// TestGetTxOutResult ensures a gettxout result round trips through JSON as
+// expected.
+func TestGetTxOutResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"bestblock":"123","confirmations":6,"value":1.5,` +
+		`"scriptPubKey":{"asm":"OP_DUP","hex":"76","reqSigs":1,` +
+		`"type":"pubkeyhash","addresses":["DsAddress"]},"version":1,` +
+		`"coinbase":true}`
+	expected := dcrjson.GetTxOutResult{
+		BestBlock:     "123",
+		Confirmations: 6,
+		Value:         1.5,
+		ScriptPubKey: dcrjson.ScriptPubKeyResult{
+			Asm:       "OP_DUP",
+			Hex:       "76",
+			ReqSigs:   1,
+			Type:      "pubkeyhash",
+			Addresses: []string{"DsAddress"},
+		},
+		Version:  1,
+		Coinbase: true,
+	}
+
+	var result dcrjson.GetTxOutResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+
+	// Ensure the result marshals back to the same data.
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetRawMempoolVerboseResult ensures a verbose getrawmempool result, which
+// is a map of transaction hashes to the details of the transactions, round
+// trips through JSON as expected.
+func TestGetRawMempoolVerboseResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"123":{"size":250,"fee":0.001,"time":1500000000,` +
+		`"height":100,"startingpriority":1.5,"currentpriority":2.5,` +
+		`"depends":["456"]}}`
+	expected := map[string]dcrjson.GetRawMempoolVerboseResult{
+		"123": {
+			Size:             250,
+			Fee:              0.001,
+			Time:             1500000000,
+			Height:           100,
+			StartingPriority: 1.5,
+			CurrentPriority:  2.5,
+			Depends:          []string{"456"},
+		},
+	}
+
+	var result map[string]dcrjson.GetRawMempoolVerboseResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+
+	// Ensure the result marshals back to the same data.
+	remarshalled, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestPingResult ensures the ping result round trips through JSON and the
+// timestamp is omitted when it was not provided.
+func TestPingResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		marshalled string
+		expected   dcrjson.PingResult
+	}{{
+		name:       "without timestamp",
+		marshalled: `{"timemillis":1500000000123}`,
+		expected:   dcrjson.PingResult{TimeMillis: 1500000000123},
+	}, {
+		name:       "with timestamp",
+		marshalled: `{"timestamp":1500000000000,"timemillis":1500000000123}`,
+		expected: dcrjson.PingResult{
+			Timestamp:  dcrjson.Int64(1500000000000),
+			TimeMillis: 1500000000123,
+		},
+	}}
+
+	for i, test := range tests {
+		var result dcrjson.PingResult
+		err := json.Unmarshal([]byte(test.marshalled), &result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Test #%d (%s) unexpected unmarshalled result - "+
+				"got %+v, want %+v", i, test.name, result,
+				test.expected)
+			continue
+		}
+
+		remarshalled, err := json.Marshal(&result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if string(remarshalled) != test.marshalled {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - got "+
+				"%s, want %s", i, test.name, remarshalled,
+				test.marshalled)
+		}
+	}
+}
+
+// TestGetBlockHeaderVerboseResult ensures the getblockheader verbose result
+// round trips through JSON with the stake related header fields typed.
+func TestGetBlockHeaderVerboseResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"hash":"00000a","confirmations":2,"version":4,` +
+		`"previousblockhash":"000009","merkleroot":"0b","stakeroot":"0c",` +
+		`"votebits":1,"finalstate":"0d0e0f101112","voters":5,` +
+		`"freshstake":3,"revocations":1,"poolsize":40960,` +
+		`"bits":"1b01ffff","sbits":2.5,"height":100,"size":1500,` +
+		`"time":1500000000,"nonce":7,"extradata":"0102",` +
+		`"stakeversion":4,"difficulty":32767.74,` +
+		`"nextblockhash":"00000b"}`
+	expected := dcrjson.GetBlockHeaderVerboseResult{
+		Hash:          "00000a",
+		Confirmations: 2,
+		Version:       4,
+		PreviousHash:  "000009",
+		MerkleRoot:    "0b",
+		StakeRoot:     "0c",
+		VoteBits:      1,
+		FinalState:    "0d0e0f101112",
+		Voters:        5,
+		FreshStake:    3,
+		Revocations:   1,
+		PoolSize:      40960,
+		Bits:          "1b01ffff",
+		SBits:         2.5,
+		Height:        100,
+		Size:          1500,
+		Time:          1500000000,
+		Nonce:         7,
+		ExtraData:     "0102",
+		StakeVersion:  4,
+		Difficulty:    32767.74,
+		NextHash:      "00000b",
+	}
+
+	var result dcrjson.GetBlockHeaderVerboseResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetPeerInfoResult ensures the getpeerinfo result marshals and unmarshals
+// as expected, including omitting the optional fields when they are not set.
+func TestGetPeerInfoResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		marshalled string
+		expected   dcrjson.GetPeerInfoResult
+	}{
+		{
+			name: "all fields",
+			marshalled: `{"id":3,"addr":"127.0.0.1:9108",` +
+				`"addrlocal":"127.0.0.1:50000","services":"00000001",` +
+				`"lastsend":1500000010,"lastrecv":1500000020,` +
+				`"bytessent":1000,"bytesrecv":2000,` +
+				`"conntime":1500000000,"timeoffset":-2,` +
+				`"pingtime":1500,"pingwait":250,"version":3,` +
+				`"subver":"/dcrwire:0.2.0/dcrd:1.0.0/","inbound":true,` +
+				`"startingheight":150000,"currentheight":150010,` +
+				`"banscore":10,"syncnode":true}`,
+			expected: dcrjson.GetPeerInfoResult{
+				ID:             3,
+				Addr:           "127.0.0.1:9108",
+				AddrLocal:      "127.0.0.1:50000",
+				Services:       "00000001",
+				LastSend:       1500000010,
+				LastRecv:       1500000020,
+				BytesSent:      1000,
+				BytesRecv:      2000,
+				ConnTime:       1500000000,
+				TimeOffset:     -2,
+				PingTime:       1500,
+				PingWait:       250,
+				Version:        3,
+				SubVer:         "/dcrwire:0.2.0/dcrd:1.0.0/",
+				Inbound:        true,
+				StartingHeight: 150000,
+				CurrentHeight:  150010,
+				BanScore:       10,
+				SyncNode:       true,
+			},
+		},
+		{
+			name: "optional fields omitted",
+			marshalled: `{"id":4,"addr":"127.0.0.1:9108",` +
+				`"services":"00000001","lastsend":0,"lastrecv":0,` +
+				`"bytessent":0,"bytesrecv":0,"conntime":1500000000,` +
+				`"timeoffset":0,"pingtime":0,"version":3,` +
+				`"subver":"","inbound":false,"startingheight":0,` +
+				`"banscore":0,"syncnode":false}`,
+			expected: dcrjson.GetPeerInfoResult{
+				ID:       4,
+				Addr:     "127.0.0.1:9108",
+				Services: "00000001",
+				ConnTime: 1500000000,
+				Version:  3,
+			},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		var result dcrjson.GetPeerInfoResult
+		err := json.Unmarshal([]byte(test.marshalled), &result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Test #%d (%s) unexpected unmarshalled result "+
+				"- got %+v, want %+v", i, test.name, result,
+				test.expected)
+			continue
+		}
+		remarshalled, err := json.Marshal(&result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if string(remarshalled) != test.marshalled {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - "+
+				"got %s, want %s", i, test.name, remarshalled,
+				test.marshalled)
+		}
+	}
+}
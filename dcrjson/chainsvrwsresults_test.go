@@ -0,0 +1,46 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrjson"
+)
+
+// TestRescanResult ensures the rescan result marshals and unmarshals as
+// expected.
+func TestRescanResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"discovereddata":[{"hash":"00000a",` +
+		`"transactions":["0100","0200"]},{"hash":"00000c",` +
+		`"transactions":["0300"]}]}`
+	expected := dcrjson.RescanResult{
+		DiscoveredData: []dcrjson.RescannedBlock{
+			{Hash: "00000a", Transactions: []string{"0100", "0200"}},
+			{Hash: "00000c", Transactions: []string{"0300"}},
+		},
+	}
+
+	var result dcrjson.RescanResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Fatalf("unexpected marshalled data - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
@@ -15,11 +15,15 @@ import (
 // type must be a registered type.  All commands provided by this package are
 // registered by default.
 func CmdMethod(cmd interface{}) (string, error) {
+	return defaultRegistry.CmdMethod(cmd)
+}
+
+// CmdMethod returns the method for the passed command.  The provided command
+// type must be registered with the registry.
+func (r *Registry) CmdMethod(cmd interface{}) (string, error) {
 	// Look up the cmd type and error out if not registered.
 	rt := reflect.TypeOf(cmd)
-	registerLock.RLock()
-	method, ok := concreteTypeToMethod[rt]
-	registerLock.RUnlock()
+	method, ok := r.lookupCmd(rt)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return "", makeError(ErrUnregisteredMethod, str)
@@ -32,11 +36,15 @@ func CmdMethod(cmd interface{}) (string, error) {
 // provided method must be associated with a registered type.  All commands
 // provided by this package are registered by default.
 func MethodUsageFlags(method string) (UsageFlag, error) {
+	return defaultRegistry.MethodUsageFlags(method)
+}
+
+// MethodUsageFlags returns the usage flags for the passed command method.  The
+// provided method must be registered with the registry.
+func (r *Registry) MethodUsageFlags(method string) (UsageFlag, error) {
 	// Look up details about the provided method and error out if not
 	// registered.
-	registerLock.RLock()
-	info, ok := methodToInfo[method]
-	registerLock.RUnlock()
+	_, info, ok := r.lookupMethod(method)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return 0, makeError(ErrUnregisteredMethod, str)
@@ -223,12 +231,15 @@ func methodUsageText(rtp reflect.Type, defaults map[int]reflect.Value, method st
 // provided method must be associated with a registered type.  All commands
 // provided by this package are registered by default.
 func MethodUsageText(method string) (string, error) {
+	return defaultRegistry.MethodUsageText(method)
+}
+
+// MethodUsageText returns a one-line usage string for the provided method.  The
+// provided method must be registered with the registry.
+func (r *Registry) MethodUsageText(method string) (string, error) {
 	// Look up details about the provided method and error out if not
 	// registered.
-	registerLock.RLock()
-	rtp, ok := methodToConcreteType[method]
-	info := methodToInfo[method]
-	registerLock.RUnlock()
+	rtp, info, ok := r.lookupMethod(method)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return "", makeError(ErrUnregisteredMethod, str)
@@ -242,9 +253,9 @@ func MethodUsageText(method string) (string, error) {
 
 	// Generate and store the usage string for future calls and return it.
 	usage := methodUsageText(rtp, info.defaults, method)
-	registerLock.Lock()
+	r.mtx.Lock()
 	info.usage = usage
-	methodToInfo[method] = info
-	registerLock.Unlock()
+	r.methodToInfo[method] = info
+	r.mtx.Unlock()
 	return usage, nil
 }
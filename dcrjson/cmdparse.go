@@ -6,6 +6,7 @@
 package dcrjson
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -32,16 +33,18 @@ func makeParams(rt reflect.Type, rv reflect.Value) []interface{} {
 	return params
 }
 
-// MarshalCmd marshals the passed command to a JSON-RPC request byte slice that
-// is suitable for transmission to an RPC server.  The provided command type
-// must be a registered type.  All commands provided by this package are
-// registered by default.
-func MarshalCmd(id interface{}, cmd interface{}) ([]byte, error) {
+// newCmdRequest creates a JSON-RPC request for the passed command.  The
+// provided command type must be a registered type.
+func newCmdRequest(id interface{}, cmd interface{}) (*Request, error) {
+	return defaultRegistry.newCmdRequest(id, cmd)
+}
+
+// newCmdRequest creates a JSON-RPC request for the passed command.  The
+// provided command type must be registered with the registry.
+func (r *Registry) newCmdRequest(id interface{}, cmd interface{}) (*Request, error) {
 	// Look up the cmd type and error out if not registered.
 	rt := reflect.TypeOf(cmd)
-	registerLock.RLock()
-	method, ok := concreteTypeToMethod[rt]
-	registerLock.RUnlock()
+	method, ok := r.lookupCmd(rt)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return nil, makeError(ErrUnregisteredMethod, str)
@@ -59,8 +62,23 @@ func MarshalCmd(id interface{}, cmd interface{}) ([]byte, error) {
 	// them if they are non-nil.
 	params := makeParams(rt.Elem(), rv.Elem())
 
-	// Generate and marshal the final JSON-RPC request.
-	rawCmd, err := NewRequest(id, method, params)
+	// Generate the final JSON-RPC request.
+	return NewRequest(id, method, params)
+}
+
+// MarshalCmd marshals the passed command to a JSON-RPC request byte slice that
+// is suitable for transmission to an RPC server.  The provided command type
+// must be a registered type.  All commands provided by this package are
+// registered by default.
+func MarshalCmd(id interface{}, cmd interface{}) ([]byte, error) {
+	return defaultRegistry.MarshalCmd(id, cmd)
+}
+
+// MarshalCmd marshals the passed command to a JSON-RPC request byte slice that
+// is suitable for transmission to an RPC server.  The provided command type
+// must be registered with the registry.
+func (r *Registry) MarshalCmd(id interface{}, cmd interface{}) ([]byte, error) {
+	rawCmd, err := r.newCmdRequest(id, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -109,12 +127,16 @@ func populateDefaults(numParams int, info *methodInfo, rv reflect.Value) {
 // so long as the method type contained within the marshalled request is
 // registered.
 func UnmarshalCmd(r *Request) (interface{}, error) {
-	registerLock.RLock()
-	rtp, ok := methodToConcreteType[r.Method]
-	info := methodToInfo[r.Method]
-	registerLock.RUnlock()
+	return defaultRegistry.UnmarshalCmd(r)
+}
+
+// UnmarshalCmd unmarshals a JSON-RPC request into a suitable concrete command
+// so long as the method type contained within the marshalled request is
+// registered with the registry.
+func (r *Registry) UnmarshalCmd(req *Request) (interface{}, error) {
+	rtp, info, ok := r.lookupMethod(req.Method)
 	if !ok {
-		str := fmt.Sprintf("%q is not registered", r.Method)
+		str := fmt.Sprintf("%q is not registered", req.Method)
 		return nil, makeError(ErrUnregisteredMethod, str)
 	}
 	rt := rtp.Elem()
@@ -122,7 +144,7 @@ func UnmarshalCmd(r *Request) (interface{}, error) {
 	rv := rvp.Elem()
 
 	// Ensure the number of parameters are correct.
-	numParams := len(r.Params)
+	numParams := len(req.Params)
 	if err := checkNumParams(numParams, &info); err != nil {
 		return nil, err
 	}
@@ -133,7 +155,7 @@ func UnmarshalCmd(r *Request) (interface{}, error) {
 		rvf := rv.Field(i)
 		// Unmarshal the parameter into the struct field.
 		concreteVal := rvf.Addr().Interface()
-		if err := json.Unmarshal(r.Params[i], &concreteVal); err != nil {
+		if err := json.Unmarshal(req.Params[i], &concreteVal); err != nil {
 			// The most common error is the wrong type, so
 			// explicitly detect that error and make it nicer.
 			fieldName := strings.ToLower(rt.Field(i).Name)
@@ -161,6 +183,104 @@ func UnmarshalCmd(r *Request) (interface{}, error) {
 	return rvp.Interface(), nil
 }
 
+// jsonValueKind returns a description of the kind of JSON value the passed raw
+// JSON starts with along with whether or not it is compatible with the passed
+// reflect kind.  Types that implement json.Unmarshaler are assumed to be
+// compatible with any JSON value since they control their own decoding.
+func jsonValueKind(raw json.RawMessage, rt reflect.Type) (string, bool) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return "nothing", false
+	}
+
+	var jsonKind string
+	var compatible bool
+	kind := rt.Kind()
+	switch c := trimmed[0]; {
+	case c == '"':
+		jsonKind = "string"
+		compatible = kind == reflect.String
+	case c == 't' || c == 'f':
+		jsonKind = "bool"
+		compatible = kind == reflect.Bool
+	case c == '-' || (c >= '0' && c <= '9'):
+		jsonKind = "number"
+		compatible = isNumeric(kind)
+	case c == '[':
+		jsonKind = "array"
+		compatible = kind == reflect.Slice || kind == reflect.Array
+	case c == '{':
+		jsonKind = "object"
+		compatible = kind == reflect.Struct || kind == reflect.Map
+	case c == 'n':
+		jsonKind = "null"
+	default:
+		return "invalid JSON", false
+	}
+
+	unmarshalerType := reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	if reflect.PtrTo(rt).Implements(unmarshalerType) {
+		compatible = jsonKind != "null"
+	}
+	return jsonKind, compatible
+}
+
+// ValidateCmdParams checks that the passed JSON-RPC parameters are suitable for
+// the provided method without unmarshalling them.  The number of parameters
+// must be valid for the method, taking any optional parameters into account,
+// and each parameter must be the kind of JSON value that is able to unmarshal
+// into the associated field of the registered command type.  A null parameter
+// is only accepted for optional fields.  Any errors will be of type Error and
+// describe the first offending parameter.
+//
+// This allows malformed requests to be rejected with a descriptive error
+// before they are dispatched.  The method must be associated with a registered
+// type.  All commands provided by this package are registered by default.
+func ValidateCmdParams(method string, params []json.RawMessage) error {
+	return defaultRegistry.ValidateCmdParams(method, params)
+}
+
+// ValidateCmdParams checks that the passed JSON-RPC parameters are suitable for
+// the provided method, which must be registered with the registry.  It is
+// otherwise identical to the package-level ValidateCmdParams function.
+func (r *Registry) ValidateCmdParams(method string, params []json.RawMessage) error {
+	rtp, info, ok := r.lookupMethod(method)
+	if !ok {
+		str := fmt.Sprintf("%q is not registered", method)
+		return makeError(ErrUnregisteredMethod, str)
+	}
+
+	// Ensure the number of parameters are correct.
+	if err := checkNumParams(len(params), &info); err != nil {
+		return err
+	}
+
+	// Ensure each parameter is compatible with its struct field.  Optional
+	// fields are pointers, so check against the type they point to.
+	rt := rtp.Elem()
+	for i, param := range params {
+		rtf := rt.Field(i)
+		fieldType := rtf.Type
+		isOptional := fieldType.Kind() == reflect.Ptr
+		if isOptional {
+			fieldType = fieldType.Elem()
+		}
+
+		jsonKind, compatible := jsonValueKind(param, fieldType)
+		if jsonKind == "null" && isOptional {
+			continue
+		}
+		if !compatible {
+			fieldName := strings.ToLower(rtf.Name)
+			str := fmt.Sprintf("parameter #%d '%s' must be type %v "+
+				"(got %s)", i+1, fieldName, fieldType, jsonKind)
+			return makeError(ErrInvalidType, str)
+		}
+	}
+
+	return nil
+}
+
 // isNumeric returns whether the passed reflect kind is a signed or unsigned
 // integer of any magnitude or a float of any magnitude.
 func isNumeric(kind reflect.Kind) bool {
@@ -510,12 +630,17 @@ func assignField(paramNum int, fieldName string, dest reflect.Value, src reflect
 //     the string as marshalled JSON and calling json.Unmarshal into the
 //     destination field
 func NewCmd(method string, args ...interface{}) (interface{}, error) {
+	return defaultRegistry.NewCmd(method, args...)
+}
+
+// NewCmd provides a generic mechanism to create a new command that can marshal
+// to a JSON-RPC request while respecting the requirements of the provided
+// method, which must be registered with the registry.  It is otherwise
+// identical to the package-level NewCmd function.
+func (r *Registry) NewCmd(method string, args ...interface{}) (interface{}, error) {
 	// Look up details about the provided method.  Any methods that aren't
 	// registered are an error.
-	registerLock.RLock()
-	rtp, ok := methodToConcreteType[method]
-	info := methodToInfo[method]
-	registerLock.RUnlock()
+	rtp, info, ok := r.lookupMethod(method)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return nil, makeError(ErrUnregisteredMethod, str)
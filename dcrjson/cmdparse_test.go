@@ -518,3 +518,116 @@ func TestUnmarshalCmdErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestValidateCmdParams ensures parameters are validated against the number
+// and types of the fields of the registered command as expected.
+func TestValidateCmdParams(t *testing.T) {
+	t.Parallel()
+
+	raw := func(params ...string) []json.RawMessage {
+		rawParams := make([]json.RawMessage, 0, len(params))
+		for _, param := range params {
+			rawParams = append(rawParams, json.RawMessage(param))
+		}
+		return rawParams
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		params []json.RawMessage
+		err    *dcrjson.Error
+	}{
+		{
+			name:   "required param only",
+			method: "getblock",
+			params: raw(`"123"`),
+		},
+		{
+			name:   "all optional params",
+			method: "getblock",
+			params: raw(`"123"`, `true`, `false`),
+		},
+		{
+			name:   "null optional param",
+			method: "getblock",
+			params: raw(`"123"`, `null`),
+		},
+		{
+			name:   "array and object params",
+			method: "createrawtransaction",
+			params: raw(`[{"txid":"123","vout":1,"tree":0}]`,
+				` {"456":0.0123}`, `12312333333`),
+		},
+		{
+			name:   "named string type",
+			method: "estimatesmartfee",
+			params: raw(`6`, `"economical"`),
+		},
+		{
+			name:   "unregistered method",
+			method: "bogusmethod",
+			params: nil,
+			err:    &dcrjson.Error{Code: dcrjson.ErrUnregisteredMethod},
+		},
+		{
+			name:   "too many params",
+			method: "getblockcount",
+			params: raw(`"bogusparam"`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrNumParams},
+		},
+		{
+			name:   "too few params",
+			method: "getblock",
+			params: nil,
+			err:    &dcrjson.Error{Code: dcrjson.ErrNumParams},
+		},
+		{
+			name:   "null required param",
+			method: "getblock",
+			params: raw(`null`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrInvalidType},
+		},
+		{
+			name:   "number for string param",
+			method: "getblock",
+			params: raw(`1`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrInvalidType},
+		},
+		{
+			name:   "string for optional bool param",
+			method: "getblock",
+			params: raw(`"123"`, `"true"`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrInvalidType},
+		},
+		{
+			name:   "object for array param",
+			method: "createrawtransaction",
+			params: raw(`{}`, `{}`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrInvalidType},
+		},
+		{
+			name:   "invalid JSON",
+			method: "getblock",
+			params: raw(`}`),
+			err:    &dcrjson.Error{Code: dcrjson.ErrInvalidType},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		err := dcrjson.ValidateCmdParams(test.method, test.params)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("Test #%d (%s) unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		jerr, ok := err.(dcrjson.Error)
+		if !ok || jerr.Code != test.err.Code {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want "+
+				"code %v", i, test.name, err, test.err.Code)
+		}
+	}
+}
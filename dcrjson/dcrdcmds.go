@@ -129,6 +129,24 @@ func NewGetStakeDifficultyCmd() *GetStakeDifficultyCmd {
 	return &GetStakeDifficultyCmd{}
 }
 
+// GetStakePoolInfoCmd defines the getstakepoolinfo JSON-RPC command.
+// Optionally, Count indicates how many blocks, starting with the best block and
+// walking backwards, to return the ticket pool information for.
+type GetStakePoolInfoCmd struct {
+	Count *int32
+}
+
+// NewGetStakePoolInfoCmd returns a new instance which can be used to issue a
+// JSON-RPC getstakepoolinfo command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetStakePoolInfoCmd(count *int32) *GetStakePoolInfoCmd {
+	return &GetStakePoolInfoCmd{
+		Count: count,
+	}
+}
+
 // GetStakeVersionInfoCmd returns stake version info for the current interval.
 // Optionally, Count indicates how many additional intervals to return.
 type GetStakeVersionInfoCmd struct {
@@ -168,6 +186,19 @@ func NewGetTicketPoolValueCmd() *GetTicketPoolValueCmd {
 	return &GetTicketPoolValueCmd{}
 }
 
+// GetTicketsForAddressCmd defines the getticketsforaddress JSON-RPC command.
+type GetTicketsForAddressCmd struct {
+	Address string
+}
+
+// NewGetTicketsForAddressCmd returns a new instance which can be used to issue
+// a getticketsforaddress JSON-RPC command.
+func NewGetTicketsForAddressCmd(addr string) *GetTicketsForAddressCmd {
+	return &GetTicketsForAddressCmd{
+		Address: addr,
+	}
+}
+
 // GetVoteInfoCmd returns voting results over a range of blocks.  Count
 // indicates how many blocks are walked backwards.
 type GetVoteInfoCmd struct {
@@ -301,9 +332,11 @@ func init() {
 	MustRegisterCmd("existsmempooltxs", (*ExistsMempoolTxsCmd)(nil), flags)
 	MustRegisterCmd("getcoinsupply", (*GetCoinSupplyCmd)(nil), flags)
 	MustRegisterCmd("getstakedifficulty", (*GetStakeDifficultyCmd)(nil), flags)
+	MustRegisterCmd("getstakepoolinfo", (*GetStakePoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getstakeversioninfo", (*GetStakeVersionInfoCmd)(nil), flags)
 	MustRegisterCmd("getstakeversions", (*GetStakeVersionsCmd)(nil), flags)
 	MustRegisterCmd("getticketpoolvalue", (*GetTicketPoolValueCmd)(nil), flags)
+	MustRegisterCmd("getticketsforaddress", (*GetTicketsForAddressCmd)(nil), flags)
 	MustRegisterCmd("getvoteinfo", (*GetVoteInfoCmd)(nil), flags)
 	MustRegisterCmd("livetickets", (*LiveTicketsCmd)(nil), flags)
 	MustRegisterCmd("missedtickets", (*MissedTicketsCmd)(nil), flags)
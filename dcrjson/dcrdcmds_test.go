@@ -42,6 +42,56 @@ func TestDcrdCmds(t *testing.T) {
 				LevelSpec: "trace",
 			},
 		},
+		{
+			name: "getcoinsupply",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getcoinsupply")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetCoinSupplyCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getcoinsupply","params":[],"id":1}`,
+			unmarshalled: &dcrjson.GetCoinSupplyCmd{},
+		},
+		{
+			name: "getstakepoolinfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getstakepoolinfo")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetStakePoolInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getstakepoolinfo","params":[],"id":1}`,
+			unmarshalled: &dcrjson.GetStakePoolInfoCmd{
+				Count: nil,
+			},
+		},
+		{
+			name: "getstakepoolinfo count",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getstakepoolinfo", 10)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetStakePoolInfoCmd(dcrjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getstakepoolinfo","params":[10],"id":1}`,
+			unmarshalled: &dcrjson.GetStakePoolInfoCmd{
+				Count: dcrjson.Int32(10),
+			},
+		},
+		{
+			name: "getstakeversioninfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getstakeversioninfo", 2)
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetStakeVersionInfoCmd(2)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getstakeversioninfo","params":[2],"id":1}`,
+			unmarshalled: &dcrjson.GetStakeVersionInfoCmd{
+				Count: dcrjson.Int32(2),
+			},
+		},
 		{
 			name: "getstakeversions",
 			newCmd: func() (interface{}, error) {
@@ -56,6 +106,19 @@ func TestDcrdCmds(t *testing.T) {
 				Count: 1,
 			},
 		},
+		{
+			name: "getticketsforaddress",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd("getticketsforaddress", "DsTestAddr")
+			},
+			staticCmd: func() interface{} {
+				return dcrjson.NewGetTicketsForAddressCmd("DsTestAddr")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getticketsforaddress","params":["DsTestAddr"],"id":1}`,
+			unmarshalled: &dcrjson.GetTicketsForAddressCmd{
+				Address: "DsTestAddr",
+			},
+		},
 		{
 			name: "getvoteinfo",
 			newCmd: func() (interface{}, error) {
@@ -5,6 +5,64 @@
 
 package dcrjson
 
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ExistsAddressesResult models the data returned from the existsaddresses
+// command.  The command returns a hex-encoded bitset where each bit indicates
+// whether or not the address at the corresponding position in the request
+// exists.  Bit i is stored in byte i/8 at position i%8 from the least
+// significant bit.
+type ExistsAddressesResult struct {
+	bits []byte
+}
+
+// Exists returns whether or not the address at the passed position in the
+// existsaddresses request exists.  False is returned for positions outside of
+// the bitset.
+func (r *ExistsAddressesResult) Exists(i int) bool {
+	if i < 0 || i>>3 >= len(r.bits) {
+		return false
+	}
+	return r.bits[i>>3]&(1<<uint(i&7)) != 0
+}
+
+// Count returns the number of addresses in the existsaddresses request which
+// exist.
+func (r *ExistsAddressesResult) Count() int {
+	var count int
+	for _, b := range r.bits {
+		for ; b != 0; b &= b - 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// MarshalJSON marshals the result to the hex-encoded bitset returned by the
+// existsaddresses command.  This satisfies the json.Marshaler interface.
+func (r *ExistsAddressesResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(r.bits))
+}
+
+// UnmarshalJSON unmarshals the hex-encoded bitset returned by the
+// existsaddresses command into the result.  This satisfies the
+// json.Unmarshaler interface.
+func (r *ExistsAddressesResult) UnmarshalJSON(b []byte) error {
+	var hexBits string
+	if err := json.Unmarshal(b, &hexBits); err != nil {
+		return err
+	}
+	bits, err := hex.DecodeString(hexBits)
+	if err != nil {
+		return err
+	}
+	r.bits = bits
+	return nil
+}
+
 // GetStakeDifficultyResult models the data returned from the
 // getstakedifficulty command.
 type GetStakeDifficultyResult struct {
@@ -34,6 +92,23 @@ type GetStakeVersionInfoResult struct {
 	Intervals     []VersionInterval `json:"intervals"`
 }
 
+// StakePoolInfo models the ticket pool information for a single block in
+// GetStakePoolInfoResult.  PoolSizeAll is the sum of the live ticket pool size
+// and the number of immature tickets.
+type StakePoolInfo struct {
+	Hash        string `json:"hash"`
+	Height      int64  `json:"height"`
+	PoolSize    uint32 `json:"poolsize"`
+	Immature    int64  `json:"immature"`
+	PoolSizeAll int64  `json:"poolsizeall"`
+}
+
+// GetStakePoolInfoResult models the data returned from the getstakepoolinfo
+// command.
+type GetStakePoolInfoResult struct {
+	PoolInfo []StakePoolInfo `json:"poolinfo"`
+}
+
 // VersionBits models a generic version:bits tuple.
 type VersionBits struct {
 	Version uint32 `json:"version"`
@@ -78,6 +153,50 @@ type Agenda struct {
 	Choices        []Choice `json:"choices"`
 }
 
+// TicketStatus describes the state of a ticket in the ticket lifecycle.
+type TicketStatus string
+
+const (
+	// TicketStatusImmature indicates the ticket has been mined but has not
+	// yet reached maturity, so it is not eligible to vote.
+	TicketStatusImmature TicketStatus = "immature"
+
+	// TicketStatusLive indicates the ticket is mature and in the live
+	// ticket pool where it is eligible to be selected to vote.
+	TicketStatusLive TicketStatus = "live"
+
+	// TicketStatusVoted indicates the ticket was selected and has voted.
+	TicketStatusVoted TicketStatus = "voted"
+
+	// TicketStatusMissed indicates the ticket was selected but did not vote.
+	TicketStatusMissed TicketStatus = "missed"
+
+	// TicketStatusExpired indicates the ticket reached its expiry without
+	// being selected to vote.
+	TicketStatusExpired TicketStatus = "expired"
+
+	// TicketStatusRevoked indicates the ticket was missed or expired and has
+	// since been revoked.
+	TicketStatusRevoked TicketStatus = "revoked"
+)
+
+// TicketForAddressResult models the data of a single ticket returned from the
+// getticketsforaddress command.  The maturity and expiry heights are the
+// heights at which the ticket becomes live and expires, respectively.
+type TicketForAddressResult struct {
+	Hash           string       `json:"hash"`
+	Height         int64        `json:"height"`
+	MaturityHeight int64        `json:"maturityheight"`
+	ExpiryHeight   int64        `json:"expiryheight"`
+	Status         TicketStatus `json:"status"`
+}
+
+// GetTicketsForAddressResult models the data returned from the
+// getticketsforaddress command.
+type GetTicketsForAddressResult struct {
+	Tickets []TicketForAddressResult `json:"tickets"`
+}
+
 // GetVoteInfoResult models the data returned from the getvoteinfo command.
 type GetVoteInfoResult struct {
 	CurrentHeight int64    `json:"currentheight"`
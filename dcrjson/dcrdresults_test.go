@@ -0,0 +1,231 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrjson"
+)
+
+// TestExistsAddressesResult ensures the hex-encoded bitset returned by the
+// existsaddresses command unmarshals into a result which reports the expected
+// existence of each address and marshals back to the same data.
+func TestExistsAddressesResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		marshalled string
+		exists     []bool
+		count      int
+	}{
+		{
+			name:       "empty",
+			marshalled: `""`,
+			exists:     []bool{false},
+			count:      0,
+		},
+		{
+			name:       "single byte",
+			marshalled: `"05"`,
+			exists: []bool{true, false, true, false, false, false,
+				false, false, false},
+			count: 2,
+		},
+		{
+			name:       "multiple bytes",
+			marshalled: `"8001"`,
+			exists: []bool{false, false, false, false, false, false,
+				false, true, true, false},
+			count: 2,
+		},
+	}
+
+	for i, test := range tests {
+		var result dcrjson.ExistsAddressesResult
+		err := json.Unmarshal([]byte(test.marshalled), &result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected unmarshal error: %v", i,
+				test.name, err)
+			continue
+		}
+		for j, want := range test.exists {
+			if got := result.Exists(j); got != want {
+				t.Errorf("Test #%d (%s) unexpected existence for "+
+					"address %d - got %v, want %v", i, test.name,
+					j, got, want)
+			}
+		}
+		if result.Exists(-1) {
+			t.Errorf("Test #%d (%s) negative position exists", i,
+				test.name)
+		}
+		if got := result.Count(); got != test.count {
+			t.Errorf("Test #%d (%s) unexpected count - got %d, want %d",
+				i, test.name, got, test.count)
+		}
+
+		marshalled, err := json.Marshal(&result)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected marshal error: %v", i,
+				test.name, err)
+			continue
+		}
+		if string(marshalled) != test.marshalled {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - got "+
+				"%s, want %s", i, test.name, marshalled,
+				test.marshalled)
+		}
+	}
+
+	// Ensure invalid hex is rejected.
+	var result dcrjson.ExistsAddressesResult
+	if err := json.Unmarshal([]byte(`"zz"`), &result); err == nil {
+		t.Errorf("did not receive expected error for invalid hex")
+	}
+}
+
+// TestGetStakePoolInfoResult ensures the getstakepoolinfo result unmarshals
+// from and marshals to the expected JSON.
+func TestGetStakePoolInfoResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"poolinfo":[{"hash":"00000000000000000000000000000000000000000000000000000000000000ff","height":1000,"poolsize":40960,"immature":1280,"poolsizeall":42240}]}`
+	expected := dcrjson.GetStakePoolInfoResult{
+		PoolInfo: []dcrjson.StakePoolInfo{{
+			Hash:        "00000000000000000000000000000000000000000000000000000000000000ff",
+			Height:      1000,
+			PoolSize:    40960,
+			Immature:    1280,
+			PoolSizeAll: 42240,
+		}},
+	}
+
+	var result dcrjson.GetStakePoolInfoResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error unmarshaling result: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected unmarshalled result - got %+v, want %+v",
+			result, expected)
+	}
+
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error marshaling result: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Errorf("unexpected marshalled result - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetStakeVersionInfoResult ensures the getstakeversioninfo result
+// unmarshals from and marshals to the expected JSON.
+func TestGetStakeVersionInfoResult(t *testing.T) {
+	t.Parallel()
+
+	const marshalled = `{"currentheight":4032,"hash":"0000000000000000000000000000000000000000000000000000000000000001","intervals":[{"startheight":2016,"endheight":4032,"posversions":[{"version":4,"count":9950},{"version":5,"count":130}],"voteversions":[{"version":4,"count":10000}]}]}`
+
+	var result dcrjson.GetStakeVersionInfoResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error unmarshaling result: %v", err)
+	}
+	if result.CurrentHeight != 4032 {
+		t.Errorf("unexpected current height - got %d, want %d",
+			result.CurrentHeight, 4032)
+	}
+	if len(result.Intervals) != 1 {
+		t.Fatalf("unexpected number of intervals - got %d, want %d",
+			len(result.Intervals), 1)
+	}
+	interval := result.Intervals[0]
+	if interval.StartHeight != 2016 || interval.EndHeight != 4032 {
+		t.Errorf("unexpected interval range - got [%d, %d], want "+
+			"[%d, %d]", interval.StartHeight, interval.EndHeight,
+			2016, 4032)
+	}
+	if len(interval.PoSVersions) != 2 || interval.PoSVersions[1].Version != 5 ||
+		interval.PoSVersions[1].Count != 130 {
+		t.Errorf("unexpected PoS versions - got %+v",
+			interval.PoSVersions)
+	}
+	if len(interval.VoteVersions) != 1 ||
+		interval.VoteVersions[0].Count != 10000 {
+		t.Errorf("unexpected vote versions - got %+v",
+			interval.VoteVersions)
+	}
+
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error marshaling result: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Errorf("unexpected marshalled result - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
+
+// TestGetVoteInfoResult ensures the getvoteinfo result, including the agendas
+// and the tallies of their choices, round trips through JSON.
+func TestGetVoteInfoResult(t *testing.T) {
+	t.Parallel()
+
+	marshalled := `{"currentheight":12000,"startheight":8065,` +
+		`"endheight":16128,"hash":"0000000000000000000000000000000000000000000000000000000000000001",` +
+		`"voteversion":4,"quorum":4032,"totalvotes":19000,"agendas":[{` +
+		`"id":"lnsupport","description":"Request developers begin work on Lightning Network (LN) integration",` +
+		`"mask":24,"starttime":1493164800,"expiretime":1508976000,` +
+		`"status":"started","quorumprogress":1,"choices":[{` +
+		`"id":"abstain","description":"abstain voting for change",` +
+		`"bits":0,"isabstain":true,"isno":false,"count":1000,` +
+		`"progress":0.05},{"id":"no","description":"no, do not work on integrating LN support",` +
+		`"bits":8,"isabstain":false,"isno":true,"count":2000,` +
+		`"progress":0.1},{"id":"yes","description":"yes, begin work on integrating LN support",` +
+		`"bits":16,"isabstain":false,"isno":false,"count":16000,` +
+		`"progress":0.8}]}]}`
+
+	var result dcrjson.GetVoteInfoResult
+	if err := json.Unmarshal([]byte(marshalled), &result); err != nil {
+		t.Fatalf("unexpected error unmarshaling result: %v", err)
+	}
+	if result.VoteVersion != 4 || result.Quorum != 4032 ||
+		result.TotalVotes != 19000 {
+		t.Errorf("unexpected vote info - got %+v", result)
+	}
+	if len(result.Agendas) != 1 {
+		t.Fatalf("unexpected number of agendas - got %d, want %d",
+			len(result.Agendas), 1)
+	}
+	agenda := result.Agendas[0]
+	if agenda.Id != "lnsupport" || agenda.Status != "started" ||
+		len(agenda.Choices) != 3 {
+		t.Fatalf("unexpected agenda - got %+v", agenda)
+	}
+	var total uint32
+	for _, choice := range agenda.Choices {
+		total += choice.Count
+	}
+	if total != result.TotalVotes {
+		t.Errorf("unexpected total of choice counts - got %d, want %d",
+			total, result.TotalVotes)
+	}
+	if !agenda.Choices[0].IsAbstain || !agenda.Choices[1].IsNo {
+		t.Errorf("unexpected abstain and no choices - got %+v",
+			agenda.Choices)
+	}
+
+	remarshalled, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("unexpected error marshaling result: %v", err)
+	}
+	if string(remarshalled) != marshalled {
+		t.Errorf("unexpected marshalled result - got %s, want %s",
+			remarshalled, marshalled)
+	}
+}
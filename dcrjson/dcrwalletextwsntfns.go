@@ -35,6 +35,9 @@ const (
 	// StakeDifficultyNtfnMethod is the method of the daemon
 	// stakedifficulty notification.
 	StakeDifficultyNtfnMethod = "stakedifficulty"
+
+	// VoteNtfnMethod is the method of the daemon vote notification.
+	VoteNtfnMethod = "vote"
 )
 
 // TicketPurchasedNtfn is a type handling custom marshaling and
@@ -160,6 +163,32 @@ func NewStakeDifficultyNtfn(hash string, height int32, stakeDiff int64) *StakeDi
 	}
 }
 
+// VoteNtfn is a type handling custom marshaling and unmarshaling of vote JSON
+// websocket notifications.  It is sent when a vote is cast, and carries the
+// hash of the vote transaction, the hash and height of the block the vote is
+// for, the hash of the ticket used to vote, and the vote bits and version of
+// the vote.
+type VoteNtfn struct {
+	TxHash      string
+	BlockHash   string
+	Height      int32
+	TicketHash  string
+	VoteBits    uint16
+	VoteVersion uint32
+}
+
+// NewVoteNtfn creates a new VoteNtfn.
+func NewVoteNtfn(txHash string, blockHash string, height int32, ticketHash string, voteBits uint16, voteVersion uint32) *VoteNtfn {
+	return &VoteNtfn{
+		TxHash:      txHash,
+		BlockHash:   blockHash,
+		Height:      height,
+		TicketHash:  ticketHash,
+		VoteBits:    voteBits,
+		VoteVersion: voteVersion,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -172,4 +201,5 @@ func init() {
 	MustRegisterCmd(SpentAndMissedTicketsNtfnMethod, (*SpentAndMissedTicketsNtfn)(nil), flags)
 	MustRegisterCmd(NewTicketsNtfnMethod, (*NewTicketsNtfn)(nil), flags)
 	MustRegisterCmd(StakeDifficultyNtfnMethod, (*StakeDifficultyNtfn)(nil), flags)
+	MustRegisterCmd(VoteNtfnMethod, (*VoteNtfn)(nil), flags)
 }
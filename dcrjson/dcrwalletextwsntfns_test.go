@@ -120,6 +120,24 @@ func TestDcrwalletChainSvrWsNtfns(t *testing.T) {
 				Tickets:   []string{"a", "b"},
 			},
 		},
+		{
+			name: "vote",
+			newNtfn: func() (interface{}, error) {
+				return dcrjson.NewCmd("vote", "123", "1234", 100, "12345", 1, 4)
+			},
+			staticNtfn: func() interface{} {
+				return dcrjson.NewVoteNtfn("123", "1234", 100, "12345", 1, 4)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"vote","params":["123","1234",100,"12345",1,4],"id":null}`,
+			unmarshalled: &dcrjson.VoteNtfn{
+				TxHash:      "123",
+				BlockHash:   "1234",
+				Height:      100,
+				TicketHash:  "12345",
+				VoteBits:    1,
+				VoteVersion: 4,
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -504,12 +504,18 @@ func isValidResultType(kind reflect.Kind) bool {
 //   "help--result0":    "List of commands"
 //   "help--result1":    "Help for specified command"
 func GenerateHelp(method string, descs map[string]string, resultTypes ...interface{}) (string, error) {
+	return defaultRegistry.GenerateHelp(method, descs, resultTypes...)
+}
+
+// GenerateHelp generates and returns help output for the provided method and
+// result types given a map to provide the appropriate keys for the method
+// synopsis, field descriptions, conditions, and result descriptions.  The
+// method must be registered with the registry.  It is otherwise identical to
+// the package-level GenerateHelp function.
+func (r *Registry) GenerateHelp(method string, descs map[string]string, resultTypes ...interface{}) (string, error) {
 	// Look up details about the provided method and error out if not
 	// registered.
-	registerLock.RLock()
-	rtp, ok := methodToConcreteType[method]
-	info := methodToInfo[method]
-	registerLock.RUnlock()
+	rtp, info, ok := r.lookupMethod(method)
 	if !ok {
 		str := fmt.Sprintf("%q is not registered", method)
 		return "", makeError(ErrUnregisteredMethod, str)
@@ -5,12 +5,14 @@
 package dcrjson
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 
 	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
 )
 
 // EncodeConcatenatedHashes serializes a slice of chainhash.Hash values into a
@@ -60,6 +62,101 @@ func DecodeConcatenatedHashes(hashes string) ([]chainhash.Hash, error) {
 	return decoded, nil
 }
 
+// ForEachConcatenatedHash decodes a single string of concatenated hex-encoded
+// hashes and invokes the passed function with each hash in order as it is
+// decoded.  This is useful for large sets of hashes since, unlike
+// DecodeConcatenatedHashes, the full slice of decoded hashes is never held in
+// memory.
+//
+// The input is validated identically to DecodeConcatenatedHashes and any
+// errors due to malformed input are of the same type.  Note that the length is
+// checked before any hashes are decoded, however, hashes preceding one which
+// is not valid hex will have already been passed to the function.
+//
+// Iteration stops as soon as the function returns an error and that error is
+// returned.
+func ForEachConcatenatedHash(hashes string, fn func(hash chainhash.Hash) error) error {
+	numHashes := len(hashes) / (2 * chainhash.HashSize)
+	if numHashes*2*chainhash.HashSize != len(hashes) {
+		return &RPCError{
+			Code:    ErrRPCInvalidParameter,
+			Message: "Hashes is not evenly divisible by the hash size",
+		}
+	}
+	var hash chainhash.Hash
+	hashSrcCpy := make([]byte, 2*chainhash.HashSize)
+	for b := 0; b < len(hashes); b += 2 * chainhash.HashSize {
+		copy(hashSrcCpy, hashes[b:])
+		_, err := hex.Decode(hash[:], hashSrcCpy)
+		if err != nil {
+			return &RPCError{
+				Code: ErrRPCDecodeHexString,
+				Message: "Parameter contains invalid hexadecimal " +
+					"encoding: " + string(hashSrcCpy),
+			}
+		}
+		if err := fn(hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeConcatenatedHeaders serializes a slice of wire.BlockHeader values into
+// a string of hex-encoded bytes.  Every header is serialized in the same format
+// it is transmitted on the wire, which is wire.MaxBlockHeaderPayload bytes.
+func EncodeConcatenatedHeaders(headers []wire.BlockHeader) (string, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(headers) * wire.MaxBlockHeaderPayload)
+	for i := range headers {
+		if err := headers[i].Serialize(&buf); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeConcatenatedHeaders returns a slice of wire.BlockHeader values created
+// by decoding a single string of concatenated hex-encoded serialized headers.
+//
+// The length of the string must be evenly divisible by twice the serialized
+// header size in order for the parameter to be valid.  This function assumes
+// the input is from a JSON-RPC request and any errors will be of type *RPCError
+// with an ErrRPCInvalidParameter or ErrRPCDecodeHexString error code.
+func DecodeConcatenatedHeaders(headers string) ([]wire.BlockHeader, error) {
+	const encodedHeaderLen = 2 * wire.MaxBlockHeaderPayload
+	numHeaders := len(headers) / encodedHeaderLen
+	if numHeaders*encodedHeaderLen != len(headers) {
+		return nil, &RPCError{
+			Code: ErrRPCInvalidParameter,
+			Message: "Headers is not evenly divisible by the " +
+				"header size",
+		}
+	}
+	headerBytes, err := hex.DecodeString(headers)
+	if err != nil {
+		return nil, &RPCError{
+			Code: ErrRPCDecodeHexString,
+			Message: "Parameter contains invalid hexadecimal " +
+				"encoding: " + err.Error(),
+		}
+	}
+	decoded := make([]wire.BlockHeader, numHeaders)
+	for i := range decoded {
+		offset := i * wire.MaxBlockHeaderPayload
+		err := decoded[i].FromBytes(headerBytes[offset : offset+
+			wire.MaxBlockHeaderPayload])
+		if err != nil {
+			return nil, &RPCError{
+				Code:    ErrRPCInvalidParameter,
+				Message: "Unable to decode header: " + err.Error(),
+			}
+		}
+	}
+	return decoded, nil
+}
+
 // EncodeConcatenatedVoteBits encodes a slice of VoteBits into a serialized byte
 // slice.  The entirety of the voteBits are encoded individually in series as
 // follows:
@@ -143,3 +240,130 @@ func DecodeConcatenatedVoteBits(voteBitsString string) ([]stake.VoteBits, error)
 
 	return dvbs, nil
 }
+
+// voteBitApprovePrevBlock is the bit of the vote bits which indicates approval
+// of the regular transaction tree of the previous block.  It is not part of any
+// agenda.
+const voteBitApprovePrevBlock = 0x0001
+
+// ValidateVoteChoices ensures the passed vote bits only consist of valid choices
+// for the provided agendas, such as those returned by the getvoteinfo command,
+// so that votes which would be rejected are not assembled.  It is typically
+// called on each set of vote bits prior to EncodeConcatenatedVoteBits.
+//
+// An error naming the offending agenda is returned when the masks of any of the
+// agendas overlap or the bits within the mask of an agenda do not correspond to
+// one of its choices.  An error is also returned when any bits other than the
+// bit which approves the previous block are set outside of every agenda mask.
+func ValidateVoteChoices(vb stake.VoteBits, agendas []Agenda) error {
+	usedMask := uint16(voteBitApprovePrevBlock)
+	for i := range agendas {
+		agenda := &agendas[i]
+		if agenda.Mask&voteBitApprovePrevBlock != 0 {
+			return fmt.Errorf("mask %#04x of agenda %q includes the "+
+				"previous block approval bit", agenda.Mask, agenda.Id)
+		}
+		if agenda.Mask&usedMask != 0 {
+			for j := range agendas[:i] {
+				if agendas[j].Mask&agenda.Mask != 0 {
+					return fmt.Errorf("mask %#04x of agenda %q "+
+						"conflicts with mask %#04x of agenda %q",
+						agenda.Mask, agenda.Id, agendas[j].Mask,
+						agendas[j].Id)
+				}
+			}
+		}
+		usedMask |= agenda.Mask
+
+		bits := vb.Bits & agenda.Mask
+		var isChoice bool
+		for _, choice := range agenda.Choices {
+			if choice.Bits == bits {
+				isChoice = true
+				break
+			}
+		}
+		if !isChoice {
+			return fmt.Errorf("vote bits %#04x do not correspond to a "+
+				"choice of agenda %q", bits, agenda.Id)
+		}
+	}
+
+	if undefined := vb.Bits &^ usedMask; undefined != 0 {
+		return fmt.Errorf("vote bits %#04x are not defined by any agenda",
+			undefined)
+	}
+
+	return nil
+}
+
+// matchIndexSize is the number of bytes each index is encoded with by
+// EncodeMatchIndices.
+const matchIndexSize = 4
+
+// EncodeMatchIndices serializes a slice of indices, such as the positions of
+// the items in a watched set which matched a block, into a string of
+// hex-encoded bytes.  Every index is encoded as a 4-byte little-endian unsigned
+// integer.  The indices must be strictly increasing so that the encoding of a
+// set of indices is unique.
+func EncodeMatchIndices(indices []uint32) (string, error) {
+	b := make([]byte, len(indices)*matchIndexSize)
+	for i, index := range indices {
+		if i > 0 && index <= indices[i-1] {
+			return "", fmt.Errorf("match indices are not strictly "+
+				"increasing (index %d at position %d follows %d)",
+				index, i, indices[i-1])
+		}
+		binary.LittleEndian.PutUint32(b[i*matchIndexSize:], index)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// DecodeMatchIndices decodes a string of indices encoded by EncodeMatchIndices.
+// The indices must be strictly increasing and each of them must be less than
+// the passed number of items in the set they refer to.
+//
+// This function assumes the input is from a JSON-RPC request and any errors
+// will be of type *RPCError with an ErrRPCInvalidParameter or
+// ErrRPCDecodeHexString error code.
+func DecodeMatchIndices(encoded string, numItems uint32) ([]uint32, error) {
+	b, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, &RPCError{
+			Code: ErrRPCDecodeHexString,
+			Message: "Parameter contains invalid hexadecimal " +
+				"encoding: " + encoded,
+		}
+	}
+	if len(b)%matchIndexSize != 0 {
+		return nil, &RPCError{
+			Code: ErrRPCInvalidParameter,
+			Message: "Match indices are not evenly divisible by " +
+				"the index size",
+		}
+	}
+
+	indices := make([]uint32, len(b)/matchIndexSize)
+	for i := range indices {
+		index := binary.LittleEndian.Uint32(b[i*matchIndexSize:])
+		if index >= numItems {
+			return nil, &RPCError{
+				Code: ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Match index %d is out of "+
+					"range for %d items", index, numItems),
+			}
+		}
+		if i > 0 && index <= indices[i-1] {
+			return nil, &RPCError{
+				Code: ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Match indices are not "+
+					"strictly increasing (index %d at position "+
+					"%d follows %d)", index, i, indices[i-1]),
+			}
+		}
+		indices[i] = index
+	}
+
+	return indices, nil
+}
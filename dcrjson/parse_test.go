@@ -7,13 +7,18 @@ package dcrjson_test
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"testing"
 
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
 	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrjson"
-	"strings"
+	"github.com/decred/dcrd/wire"
 )
 
 func decodeHash(reversedHash string) chainhash.Hash {
@@ -239,3 +244,293 @@ func TestInvalidDecodeConcatenatedHashes(t *testing.T) {
 		}
 	}
 }
+
+// TestForEachConcatenatedHash ensures ForEachConcatenatedHash yields the
+// expected hashes in order, stops on the first error returned by the callback,
+// and rejects malformed input the same way as DecodeConcatenatedHashes.
+func TestForEachConcatenatedHash(t *testing.T) {
+	// Test data taken from Decred's first three mainnet blocks
+	testHashes := []chainhash.Hash{
+		decodeHash("298e5cc3d985bfe7f81dc135f360abe089edd4396b86d2de66b0cef42b21d980"),
+		decodeHash("000000000000437482b6d47f82f374cde539440ddb108b0a76886f0d87d126b9"),
+		decodeHash("000000000000c41019872ff7db8fd2e9bfa05f42d3f8fee8e895e8c1e5b8dcba"),
+	}
+	concatenatedHashes := dcrjson.EncodeConcatenatedHashes(testHashes)
+
+	var yielded []chainhash.Hash
+	err := dcrjson.ForEachConcatenatedHash(concatenatedHashes,
+		func(hash chainhash.Hash) error {
+			yielded = append(yielded, hash)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ForEachConcatenatedHash failed: %v", err)
+	}
+	if !reflect.DeepEqual(yielded, testHashes) {
+		t.Fatalf("unexpected yielded hashes - got %v, want %v",
+			yielded, testHashes)
+	}
+
+	// Ensure iteration stops on the first callback error and that error is
+	// returned.
+	errStop := errors.New("stop")
+	var numCalls int
+	err = dcrjson.ForEachConcatenatedHash(concatenatedHashes,
+		func(hash chainhash.Hash) error {
+			numCalls++
+			if hash == testHashes[1] {
+				return errStop
+			}
+			return nil
+		})
+	if err != errStop {
+		t.Fatalf("unexpected error - got %v, want %v", err, errStop)
+	}
+	if numCalls != 2 {
+		t.Fatalf("unexpected number of callback invocations - got %d, "+
+			"want %d", numCalls, 2)
+	}
+
+	// Ensure malformed input is rejected with the same error codes as
+	// DecodeConcatenatedHashes.
+	tests := []struct {
+		name string
+		str  string
+		code dcrjson.RPCErrorCode
+	}{
+		{"invalid length", concatenatedHashes[1:], dcrjson.ErrRPCInvalidParameter},
+		{"not hex", concatenatedHashes[:2*chainhash.HashSize] +
+			strings.Repeat("g", 2*chainhash.HashSize),
+			dcrjson.ErrRPCDecodeHexString},
+	}
+	for i, test := range tests {
+		numCalls = 0
+		err := dcrjson.ForEachConcatenatedHash(test.str,
+			func(hash chainhash.Hash) error {
+				numCalls++
+				return nil
+			})
+		rpcErr, ok := err.(*dcrjson.RPCError)
+		if !ok {
+			t.Errorf("Test #%d (%s) unexpected error type - got %T, "+
+				"want *dcrjson.RPCError", i, test.name, err)
+			continue
+		}
+		if rpcErr.Code != test.code {
+			t.Errorf("Test #%d (%s) unexpected error code - got %v, "+
+				"want %v", i, test.name, rpcErr.Code, test.code)
+		}
+		_, decodeErr := dcrjson.DecodeConcatenatedHashes(test.str)
+		if decodeErr == nil || decodeErr.(*dcrjson.RPCError).Code != test.code {
+			t.Errorf("Test #%d (%s) mismatched DecodeConcatenatedHashes "+
+				"error - got %v", i, test.name, decodeErr)
+		}
+	}
+	if numCalls != 1 {
+		t.Fatalf("unexpected number of callback invocations before "+
+			"invalid hex - got %d, want %d", numCalls, 1)
+	}
+}
+
+// TestConcatenatedHeaders ensures block headers survive a round trip through
+// EncodeConcatenatedHeaders and the getheadersinrange result decode helper and
+// that invalid encodings are rejected.
+func TestConcatenatedHeaders(t *testing.T) {
+	headers := []wire.BlockHeader{
+		chaincfg.MainNetParams.GenesisBlock.Header,
+		chaincfg.TestNet2Params.GenesisBlock.Header,
+		chaincfg.SimNetParams.GenesisBlock.Header,
+	}
+
+	// Test from 0 to N of the headers.
+	for i := 0; i <= len(headers); i++ {
+		encoded, err := dcrjson.EncodeConcatenatedHeaders(headers[:i])
+		if err != nil {
+			t.Fatalf("EncodeConcatenatedHeaders #%d: unexpected "+
+				"error: %v", i, err)
+		}
+		if len(encoded) != i*2*wire.MaxBlockHeaderPayload {
+			t.Fatalf("EncodeConcatenatedHeaders #%d: unexpected "+
+				"length - got %d, want %d", i, len(encoded),
+				i*2*wire.MaxBlockHeaderPayload)
+		}
+
+		result := dcrjson.GetHeadersInRangeResult{Headers: encoded}
+		decoded, err := result.DecodeHeaders()
+		if err != nil {
+			t.Fatalf("DecodeHeaders #%d: unexpected error: %v", i,
+				err)
+		}
+		if len(decoded) != i {
+			t.Fatalf("DecodeHeaders #%d: unexpected number of "+
+				"headers - got %d, want %d", i, len(decoded), i)
+		}
+		for j := range decoded {
+			if decoded[j].BlockHash() != headers[j].BlockHash() {
+				t.Fatalf("DecodeHeaders #%d: mismatched header "+
+					"%d - got %v, want %v", i, j,
+					spew.Sdump(decoded[j]),
+					spew.Sdump(headers[j]))
+			}
+		}
+	}
+
+	encoded, err := dcrjson.EncodeConcatenatedHeaders(headers[:1])
+	if err != nil {
+		t.Fatalf("EncodeConcatenatedHeaders: unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		str  string
+		code dcrjson.RPCErrorCode
+	}{
+		{"short", encoded[:len(encoded)-2], dcrjson.ErrRPCInvalidParameter},
+		{"not hex", "g" + encoded[1:], dcrjson.ErrRPCDecodeHexString},
+	}
+	for i, test := range tests {
+		_, err := dcrjson.DecodeConcatenatedHeaders(test.str)
+		rpcError, ok := err.(*dcrjson.RPCError)
+		if !ok {
+			t.Errorf("Test #%d (%s): unexpected error type - got "+
+				"%T, want *dcrjson.RPCError", i, test.name, err)
+			continue
+		}
+		if rpcError.Code != test.code {
+			t.Errorf("Test #%d (%s): unexpected error code - got "+
+				"%v, want %v", i, test.name, rpcError.Code,
+				test.code)
+		}
+	}
+}
+
+// TestMatchIndices ensures match indices survive a round trip through
+// EncodeMatchIndices and DecodeMatchIndices and that invalid sets of indices
+// are rejected by both.
+func TestMatchIndices(t *testing.T) {
+	indices := []uint32{0, 1, 5, 256, 70000}
+	encoded, err := dcrjson.EncodeMatchIndices(indices)
+	if err != nil {
+		t.Fatalf("EncodeMatchIndices: unexpected error: %v", err)
+	}
+	const wantEncoded = "00000000" + "01000000" + "05000000" + "00010000" +
+		"70110100"
+	if encoded != wantEncoded {
+		t.Fatalf("EncodeMatchIndices: unexpected encoding - got %s, "+
+			"want %s", encoded, wantEncoded)
+	}
+	decoded, err := dcrjson.DecodeMatchIndices(encoded, 70001)
+	if err != nil {
+		t.Fatalf("DecodeMatchIndices: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, indices) {
+		t.Fatalf("DecodeMatchIndices: unexpected indices - got %v, "+
+			"want %v", decoded, indices)
+	}
+
+	// Ensure an empty set of indices round trips.
+	encoded, err = dcrjson.EncodeMatchIndices(nil)
+	if err != nil || encoded != "" {
+		t.Fatalf("EncodeMatchIndices: unexpected result for no "+
+			"indices - got %q, %v", encoded, err)
+	}
+	decoded, err = dcrjson.DecodeMatchIndices(encoded, 0)
+	if err != nil || len(decoded) != 0 {
+		t.Fatalf("DecodeMatchIndices: unexpected result for no "+
+			"indices - got %v, %v", decoded, err)
+	}
+
+	// Ensure indices which are not strictly increasing are not encoded.
+	for _, indices := range [][]uint32{{2, 1}, {1, 1}} {
+		if _, err := dcrjson.EncodeMatchIndices(indices); err == nil {
+			t.Errorf("EncodeMatchIndices: did not receive expected "+
+				"error for %v", indices)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		encoded  string
+		numItems uint32
+		code     dcrjson.RPCErrorCode
+	}{
+		{"not hex", "0000000g", 10, dcrjson.ErrRPCDecodeHexString},
+		{"short index", "000000", 10, dcrjson.ErrRPCInvalidParameter},
+		{"out of range", "0a000000", 10, dcrjson.ErrRPCInvalidParameter},
+		{"decreasing", "0200000001000000", 10, dcrjson.ErrRPCInvalidParameter},
+		{"duplicate", "0100000001000000", 10, dcrjson.ErrRPCInvalidParameter},
+	}
+	for i, test := range tests {
+		_, err := dcrjson.DecodeMatchIndices(test.encoded, test.numItems)
+		rpcErr, ok := err.(*dcrjson.RPCError)
+		if !ok {
+			t.Errorf("Test #%d (%s) unexpected error type - got %T, "+
+				"want *dcrjson.RPCError", i, test.name, err)
+			continue
+		}
+		if rpcErr.Code != test.code {
+			t.Errorf("Test #%d (%s) unexpected error code - got %v, "+
+				"want %v", i, test.name, rpcErr.Code, test.code)
+		}
+	}
+}
+
+// TestValidateVoteChoices ensures ValidateVoteChoices accepts vote bits which
+// only consist of valid agenda choices and rejects everything else with an
+// error naming the offending agenda.
+func TestValidateVoteChoices(t *testing.T) {
+	choices := func(bits ...uint16) []dcrjson.Choice {
+		choices := make([]dcrjson.Choice, 0, len(bits))
+		for _, b := range bits {
+			choices = append(choices, dcrjson.Choice{Bits: b})
+		}
+		return choices
+	}
+	agendas := []dcrjson.Agenda{{
+		Id:      "sdiffalgorithm",
+		Mask:    0x0006,
+		Choices: choices(0x0000, 0x0002, 0x0004),
+	}, {
+		Id:      "lnsupport",
+		Mask:    0x0018,
+		Choices: choices(0x0000, 0x0008, 0x0010),
+	}}
+
+	tests := []struct {
+		name    string
+		bits    uint16
+		agendas []dcrjson.Agenda
+		errStr  string
+	}{
+		{"abstain all", 0x0001, agendas, ""},
+		{"no approval", 0x0000, agendas, ""},
+		{"choices of both agendas", 0x0015, agendas, ""},
+		{"undefined choice", 0x0007, agendas, "sdiffalgorithm"},
+		{"undefined bits", 0x0021, agendas, "not defined"},
+		{"overlapping masks", 0x0001, append(agendas, dcrjson.Agenda{
+			Id:      "overlap",
+			Mask:    0x000c,
+			Choices: choices(0x0000),
+		}), "overlap"},
+		{"approval bit in mask", 0x0001, []dcrjson.Agenda{{
+			Id:      "approval",
+			Mask:    0x0003,
+			Choices: choices(0x0000),
+		}}, "approval"},
+	}
+	for i, test := range tests {
+		vb := stake.VoteBits{Bits: test.bits}
+		err := dcrjson.ValidateVoteChoices(vb, test.agendas)
+		if test.errStr == "" {
+			if err != nil {
+				t.Errorf("Test #%d (%s) unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.errStr) {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want "+
+				"error containing %q", i, test.name, err,
+				test.errStr)
+		}
+	}
+}
@@ -83,13 +83,55 @@ type methodInfo struct {
 	usage        string
 }
 
-var (
+// Registry houses a set of registered commands along with the information
+// needed to marshal and unmarshal them.  Each registry is independent of all
+// others, which allows multiple logically separate RPC services to be
+// provided in the same process without their commands colliding.
+//
+// The package-level functions which deal with registered commands, such as
+// RegisterCmd, MarshalCmd, and UnmarshalCmd, operate on a default registry
+// which all commands provided by this package are registered with.
+//
+// A Registry is safe for concurrent access.
+type Registry struct {
 	// These fields are used to map the registered types to method names.
-	registerLock         sync.RWMutex
-	methodToConcreteType = make(map[string]reflect.Type)
-	methodToInfo         = make(map[string]methodInfo)
-	concreteTypeToMethod = make(map[reflect.Type]string)
-)
+	mtx                  sync.RWMutex
+	methodToConcreteType map[string]reflect.Type
+	methodToInfo         map[string]methodInfo
+	concreteTypeToMethod map[reflect.Type]string
+}
+
+// NewRegistry returns a new empty command registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		methodToConcreteType: make(map[string]reflect.Type),
+		methodToInfo:         make(map[string]methodInfo),
+		concreteTypeToMethod: make(map[reflect.Type]string),
+	}
+}
+
+// defaultRegistry is the registry used by the package-level functions.  All
+// commands provided by this package are registered with it.
+var defaultRegistry = NewRegistry()
+
+// lookupMethod returns the concrete type and information for the passed
+// method along with whether or not it is registered.
+func (r *Registry) lookupMethod(method string) (reflect.Type, methodInfo, bool) {
+	r.mtx.RLock()
+	rtp, ok := r.methodToConcreteType[method]
+	info := r.methodToInfo[method]
+	r.mtx.RUnlock()
+	return rtp, info, ok
+}
+
+// lookupCmd returns the method the passed command type is registered as along
+// with whether or not it is registered.
+func (r *Registry) lookupCmd(rt reflect.Type) (string, bool) {
+	r.mtx.RLock()
+	method, ok := r.concreteTypeToMethod[rt]
+	r.mtx.RUnlock()
+	return method, ok
+}
 
 // baseKindString returns the base kind for a given reflect.Type after
 // indirecting through all pointers.
@@ -156,10 +198,16 @@ func isAcceptableKind(kind reflect.Kind) bool {
 // is recommended to simply pass a nil pointer cast to the appropriate type.
 // For example, (*FooCmd)(nil).
 func RegisterCmd(method string, cmd interface{}, flags UsageFlag) error {
-	registerLock.Lock()
-	defer registerLock.Unlock()
+	return defaultRegistry.RegisterCmd(method, cmd, flags)
+}
 
-	if _, ok := methodToConcreteType[method]; ok {
+// RegisterCmd registers a new command with the registry.  It is otherwise
+// identical to the package-level RegisterCmd function.
+func (r *Registry) RegisterCmd(method string, cmd interface{}, flags UsageFlag) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.methodToConcreteType[method]; ok {
 		str := fmt.Sprintf("method %q is already registered", method)
 		return makeError(ErrDuplicateMethod, str)
 	}
@@ -255,15 +303,15 @@ func RegisterCmd(method string, cmd interface{}, flags UsageFlag) error {
 	}
 
 	// Update the registration maps.
-	methodToConcreteType[method] = rtp
-	methodToInfo[method] = methodInfo{
+	r.methodToConcreteType[method] = rtp
+	r.methodToInfo[method] = methodInfo{
 		maxParams:    numFields,
 		numReqParams: numFields - numOptFields,
 		numOptParams: numOptFields,
 		defaults:     defaults,
 		flags:        flags,
 	}
-	concreteTypeToMethod[rtp] = method
+	r.concreteTypeToMethod[rtp] = method
 	return nil
 }
 
@@ -271,7 +319,13 @@ func RegisterCmd(method string, cmd interface{}, flags UsageFlag) error {
 // if there is an error.  This should only be called from package init
 // functions.
 func MustRegisterCmd(method string, cmd interface{}, flags UsageFlag) {
-	if err := RegisterCmd(method, cmd, flags); err != nil {
+	defaultRegistry.MustRegisterCmd(method, cmd, flags)
+}
+
+// MustRegisterCmd performs the same function as RegisterCmd except it panics
+// if there is an error.
+func (r *Registry) MustRegisterCmd(method string, cmd interface{}, flags UsageFlag) {
+	if err := r.RegisterCmd(method, cmd, flags); err != nil {
 		panic(fmt.Sprintf("failed to register type %q: %v\n", method,
 			err))
 	}
@@ -280,11 +334,17 @@ func MustRegisterCmd(method string, cmd interface{}, flags UsageFlag) {
 // RegisteredCmdMethods returns a sorted list of methods for all registered
 // commands.
 func RegisteredCmdMethods() []string {
-	registerLock.Lock()
-	defer registerLock.Unlock()
+	return defaultRegistry.RegisteredCmdMethods()
+}
+
+// RegisteredCmdMethods returns a sorted list of methods for all commands
+// registered with the registry.
+func (r *Registry) RegisteredCmdMethods() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
 
-	methods := make([]string, 0, len(methodToInfo))
-	for k := range methodToInfo {
+	methods := make([]string, 0, len(r.methodToInfo))
+	for k := range r.methodToInfo {
 		methods = append(methods, k)
 	}
 
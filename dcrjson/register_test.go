@@ -6,6 +6,7 @@
 package dcrjson_test
 
 import (
+	"encoding/json"
 	"reflect"
 	"sort"
 	"testing"
@@ -262,3 +263,83 @@ func TestRegisteredCmdMethods(t *testing.T) {
 		t.Fatal("RegisteredCmdMethods: methods are not sorted")
 	}
 }
+
+// TestRegistry ensures commands registered with a separate registry are
+// independent of the default registry and other registries.
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	type registryTestCmd struct {
+		Name  string
+		Count *int `jsonrpcdefault:"5"`
+	}
+
+	// Ensure a method that is registered with the default registry may also
+	// be registered with a new registry and that a new method registered
+	// with it is not visible to the default registry or other registries.
+	registry := dcrjson.NewRegistry()
+	err := registry.RegisterCmd("getblock", (*registryTestCmd)(nil), 0)
+	if err != nil {
+		t.Fatalf("RegisterCmd: unexpected error: %v", err)
+	}
+	registry.MustRegisterCmd("registrytestcmd", (*registryTestCmd)(nil), 0)
+	err = registry.RegisterCmd("registrytestcmd", (*registryTestCmd)(nil), 0)
+	if jerr, ok := err.(dcrjson.Error); !ok ||
+		jerr.Code != dcrjson.ErrDuplicateMethod {
+		t.Fatalf("RegisterCmd: unexpected error for duplicate method - "+
+			"got %v, want %v", err, dcrjson.ErrDuplicateMethod)
+	}
+	want := []string{"getblock", "registrytestcmd"}
+	if methods := registry.RegisteredCmdMethods(); !reflect.DeepEqual(methods, want) {
+		t.Fatalf("RegisteredCmdMethods: got %v, want %v", methods, want)
+	}
+	if _, err := dcrjson.MethodUsageFlags("registrytestcmd"); err == nil {
+		t.Fatal("MethodUsageFlags: method registered with a separate " +
+			"registry is visible to the default registry")
+	}
+	if _, err := dcrjson.NewRegistry().MethodUsageFlags("registrytestcmd"); err == nil {
+		t.Fatal("MethodUsageFlags: method registered with a separate " +
+			"registry is visible to another registry")
+	}
+
+	// Ensure commands round trip through the registry with defaults
+	// populated.
+	cmd, err := registry.NewCmd("registrytestcmd", "test")
+	if err != nil {
+		t.Fatalf("NewCmd: unexpected error: %v", err)
+	}
+	method, err := registry.CmdMethod(cmd)
+	if err != nil || method != "registrytestcmd" {
+		t.Fatalf("CmdMethod: got %q (err %v), want %q", method, err,
+			"registrytestcmd")
+	}
+	marshalled, err := registry.MarshalCmd(1, cmd)
+	if err != nil {
+		t.Fatalf("MarshalCmd: unexpected error: %v", err)
+	}
+	wantMarshalled := `{"jsonrpc":"1.0","method":"registrytestcmd","params":["test"],"id":1}`
+	if string(marshalled) != wantMarshalled {
+		t.Fatalf("MarshalCmd: got %s, want %s", marshalled,
+			wantMarshalled)
+	}
+	if _, err := dcrjson.MarshalCmd(1, cmd); err == nil {
+		t.Fatal("MarshalCmd: command registered with a separate " +
+			"registry marshalled with the default registry")
+	}
+	var request dcrjson.Request
+	if err := json.Unmarshal(marshalled, &request); err != nil {
+		t.Fatalf("unexpected error unmarshalling request: %v", err)
+	}
+	unmarshalled, err := registry.UnmarshalCmd(&request)
+	if err != nil {
+		t.Fatalf("UnmarshalCmd: unexpected error: %v", err)
+	}
+	wantCmd := &registryTestCmd{Name: "test", Count: dcrjson.Int(5)}
+	if !reflect.DeepEqual(unmarshalled, wantCmd) {
+		t.Fatalf("UnmarshalCmd: got %+v, want %+v", unmarshalled, wantCmd)
+	}
+	usage, err := registry.MethodUsageText("registrytestcmd")
+	if err != nil || usage != `registrytestcmd "name" (count=5)` {
+		t.Fatalf("MethodUsageText: got %q (err %v)", usage, err)
+	}
+}
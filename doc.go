@@ -99,6 +99,10 @@ Application Options:
                             high priority for relaying
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (1000)
+      --maxorphantxage=     Max amount of time to keep an orphan transaction in
+                            memory while waiting for its missing parents.
+                            Valid time units are {s, m, h}.  0 to disable
+                            (15m0s)
       --generate            Generate (mine) bitcoins using the CPU
       --miningaddr=         Add the specified payment address to the list of
                             addresses to use for generated blocks -- At least
@@ -119,6 +123,8 @@ Application Options:
       --nopeerbloomfilters  Disable bloom filtering support.
       --sigcachemaxsize=    The maximum number of entries in the signature
                             verification cache.
+      --blockcachesize=     The maximum total size in bytes of recently accessed
+                            blocks to keep in memory (0 to disable) (16777216)
       --blocksonly          Do not accept transactions from remote peers.
       --relaynonstd         Relay non-standard transactions regardless of the
                             default settings for the active network.
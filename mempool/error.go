@@ -6,6 +6,8 @@
 package mempool
 
 import (
+	"fmt"
+
 	"github.com/decred/dcrd/blockchain"
 	"github.com/decred/dcrd/wire"
 )
@@ -28,6 +30,75 @@ func (e RuleError) Error() string {
 	return e.Err.Error()
 }
 
+// ErrorCode identifies the specific transaction standardness rule that was
+// violated.
+type ErrorCode int
+
+// These constants are used to identify a specific TxRuleError.
+const (
+	// ErrOther indicates the rule violation is not one of the specific
+	// standardness rules identified below.  The RejectCode and Description
+	// of the error must be used to determine the reason for the violation.
+	ErrOther ErrorCode = iota
+
+	// ErrTxSerType indicates a transaction is not serialized with all of
+	// its required data.
+	ErrTxSerType
+
+	// ErrTxVersion indicates a transaction version is not in the range of
+	// versions allowed by the policy.
+	ErrTxVersion
+
+	// ErrTxNotFinalized indicates a transaction is not finalized.
+	ErrTxNotFinalized
+
+	// ErrTxTooLarge indicates the serialized size of a transaction exceeds
+	// the maximum size allowed by the policy.
+	ErrTxTooLarge
+
+	// ErrSigScriptTooLarge indicates the size of a transaction input
+	// signature script exceeds the maximum size allowed by the policy.
+	ErrSigScriptTooLarge
+
+	// ErrSigScriptNotPushOnly indicates a transaction input signature
+	// script contains opcodes other than those which push data.
+	ErrSigScriptNotPushOnly
+
+	// ErrNonStandardPkScript indicates a transaction output public key
+	// script is not a standard form or version.
+	ErrNonStandardPkScript
+
+	// ErrDustOutput indicates a transaction output pays an amount that is
+	// considered dust.
+	ErrDustOutput
+
+	// ErrTooManyNullDataOutputs indicates a regular transaction has more
+	// outputs which only carry data than allowed by the policy.
+	ErrTooManyNullDataOutputs
+)
+
+// Map of ErrorCode values back to their constant names for pretty printing.
+var errorCodeStrings = map[ErrorCode]string{
+	ErrOther:                  "ErrOther",
+	ErrTxSerType:              "ErrTxSerType",
+	ErrTxVersion:              "ErrTxVersion",
+	ErrTxNotFinalized:         "ErrTxNotFinalized",
+	ErrTxTooLarge:             "ErrTxTooLarge",
+	ErrSigScriptTooLarge:      "ErrSigScriptTooLarge",
+	ErrSigScriptNotPushOnly:   "ErrSigScriptNotPushOnly",
+	ErrNonStandardPkScript:    "ErrNonStandardPkScript",
+	ErrDustOutput:             "ErrDustOutput",
+	ErrTooManyNullDataOutputs: "ErrTooManyNullDataOutputs",
+}
+
+// String returns the ErrorCode as a human-readable name.
+func (e ErrorCode) String() string {
+	if s := errorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ErrorCode (%d)", int(e))
+}
+
 // TxRuleError identifies a rule violation.  It is used to indicate that
 // processing of a transaction failed due to one of the many validation
 // rules.  The caller can use type assertions to determine if a failure was
@@ -35,6 +106,7 @@ func (e RuleError) Error() string {
 // ascertain the specific reason for the rule violation.
 type TxRuleError struct {
 	RejectCode  wire.RejectCode // The code to send with reject messages
+	ErrorCode   ErrorCode       // Describes the kind of standardness error
 	Description string          // Human readable description of the issue
 }
 
@@ -51,6 +123,16 @@ func txRuleError(c wire.RejectCode, desc string) RuleError {
 	}
 }
 
+// stdRuleError creates an underlying TxRuleError for a violation of the
+// standardness rule identified by the passed error code and returns a
+// RuleError that encapsulates it.
+func stdRuleError(errCode ErrorCode, c wire.RejectCode, desc string) RuleError {
+	return RuleError{
+		Err: TxRuleError{RejectCode: c, ErrorCode: errCode,
+			Description: desc},
+	}
+}
+
 // chainRuleError returns a RuleError that encapsulates the given
 // blockchain.RuleError.
 func chainRuleError(chainErr blockchain.RuleError) RuleError {
@@ -56,6 +56,10 @@ const (
 	// TODO Set this based up the stake difficulty retargeting interval?
 	heightDiffToPruneTicket = 288
 
+	// orphanExpireScanInterval is the minimum amount of time in between
+	// scans of the orphan pool to evict expired transactions.
+	orphanExpireScanInterval = time.Minute * 5
+
 	// heightDiffToPruneVotes is the number of blocks to pass by in terms
 	// of height before SSGen relating to that block are pruned.
 	heightDiffToPruneVotes = 10
@@ -166,6 +170,12 @@ type Policy struct {
 	// of big orphans.
 	MaxOrphanTxSize int
 
+	// MaxOrphanTxAge is the maximum amount of time an orphan transaction
+	// is retained while waiting for its missing parents to become
+	// available.  Orphans older than this are evicted from the orphan pool.
+	// A value of zero disables age based eviction.
+	MaxOrphanTxAge time.Duration
+
 	// MaxSigOpsPerTx is the maximum number of signature operations
 	// in a single transaction we will relay or mine.  It is a fraction
 	// of the max signature operations for a block.
@@ -198,6 +208,14 @@ type TxDesc struct {
 	StartingPriority float64
 }
 
+// orphanTx is a normal transaction that references an ancestor transaction
+// that is not yet available.  It also contains additional information related
+// to it such as an expiration time to help prevent caching the orphan forever.
+type orphanTx struct {
+	tx         *dcrutil.Tx
+	expiration time.Time
+}
+
 // TxPool is used as a source of transactions that need to be mined into blocks
 // and relayed to other peers.  It is safe for concurrent access from multiple
 // peers.
@@ -208,7 +226,7 @@ type TxPool struct {
 	mtx           sync.RWMutex
 	cfg           Config
 	pool          map[chainhash.Hash]*TxDesc
-	orphans       map[chainhash.Hash]*dcrutil.Tx
+	orphans       map[chainhash.Hash]*orphanTx
 	orphansByPrev map[chainhash.Hash]map[chainhash.Hash]*dcrutil.Tx
 	addrindex     map[string]map[chainhash.Hash]struct{} // maps address to txs
 	outpoints     map[wire.OutPoint]*dcrutil.Tx
@@ -219,6 +237,12 @@ type TxPool struct {
 
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
+
+	// nextExpireScan is the time after which the orphan pool will be
+	// scanned in order to evict orphans.  This is NOT a hard deadline as
+	// the scan will only run when an orphan is added to the pool as opposed
+	// to on an unconditional timer.
+	nextExpireScan time.Time
 }
 
 // insertVote inserts a vote into the map of block votes.
@@ -316,10 +340,11 @@ func (mp *TxPool) removeOrphan(txHash *chainhash.Hash) {
 	log.Tracef("Removing orphan transaction %v", txHash)
 
 	// Nothing to do if passed tx is not an orphan.
-	tx, exists := mp.orphans[*txHash]
+	otx, exists := mp.orphans[*txHash]
 	if !exists {
 		return
 	}
+	tx := otx.tx
 
 	// Remove the reference from the previous orphan index.
 	for _, txIn := range tx.MsgTx().TxIn {
@@ -350,10 +375,35 @@ func (mp *TxPool) RemoveOrphan(txHash *chainhash.Hash) {
 }
 
 // limitNumOrphans limits the number of orphan transactions by evicting a random
-// orphan if adding a new one would cause it to overflow the max allowed.
+// orphan if adding a new one would cause it to overflow the max allowed.  It
+// also periodically evicts any orphans which have exceeded the maximum orphan
+// age allowed by the policy.
 //
 // This function MUST be called with the mempool lock held (for writes).
 func (mp *TxPool) limitNumOrphans() error {
+	// Scan through the orphan pool and remove any expired orphans when it's
+	// time.  This is done for efficiency so the scan only happens
+	// periodically instead of on every orphan added to the pool.
+	if now := time.Now(); mp.cfg.Policy.MaxOrphanTxAge > 0 &&
+		now.After(mp.nextExpireScan) {
+
+		origNumOrphans := len(mp.orphans)
+		for txHash, otx := range mp.orphans {
+			if now.After(otx.expiration) {
+				mp.removeOrphan(&txHash)
+			}
+		}
+
+		// Set next expiration scan to occur after the scan interval.
+		mp.nextExpireScan = now.Add(orphanExpireScanInterval)
+
+		numOrphans := len(mp.orphans)
+		if numExpired := origNumOrphans - numOrphans; numExpired > 0 {
+			log.Debugf("Expired %d orphan transactions (remaining: "+
+				"%d)", numExpired, numOrphans)
+		}
+	}
+
 	if len(mp.orphans)+1 > mp.cfg.Policy.MaxOrphanTxs &&
 		mp.cfg.Policy.MaxOrphanTxs > 0 {
 
@@ -388,15 +438,20 @@ func (mp *TxPool) limitNumOrphans() error {
 	return nil
 }
 
-// addOrphan adds an orphan transaction to the orphan pool.
+// addOrphan adds an orphan transaction to the orphan pool which expires at the
+// provided time.  A zero expiration time means the orphan never expires due
+// to age.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) addOrphan(tx *dcrutil.Tx) {
+func (mp *TxPool) addOrphan(tx *dcrutil.Tx, expiration time.Time) {
 	// Limit the number orphan transactions to prevent memory exhaustion.  A
 	// random orphan is evicted to make room if needed.
 	mp.limitNumOrphans()
 
-	mp.orphans[*tx.Hash()] = tx
+	mp.orphans[*tx.Hash()] = &orphanTx{
+		tx:         tx,
+		expiration: expiration,
+	}
 	for _, txIn := range tx.MsgTx().TxIn {
 		originTxHash := txIn.PreviousOutPoint.Hash
 		if _, exists := mp.orphansByPrev[originTxHash]; !exists {
@@ -433,7 +488,11 @@ func (mp *TxPool) maybeAddOrphan(tx *dcrutil.Tx) error {
 	}
 
 	// Add the orphan if the none of the above disqualified it.
-	mp.addOrphan(tx)
+	var expiration time.Time
+	if mp.cfg.Policy.MaxOrphanTxAge > 0 {
+		expiration = time.Now().Add(mp.cfg.Policy.MaxOrphanTxAge)
+	}
+	mp.addOrphan(tx, expiration)
 
 	return nil
 }
@@ -773,13 +832,17 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash, includeRecentBlock bo
 // MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
 // more details.
 //
+// The orphan expiration is the time the transaction expires when it is being
+// retried from the orphan pool and is otherwise the zero time.  It is used to
+// reject orphans which still have unknown inputs once they have expired.
+//
 // This function MUST be called with the mempool lock held (for writes).
 // DECRED - TODO
 // We need to make sure thing also assigns the TxType after it evaluates the tx,
 // so that we can easily pick different stake tx types from the mempool later.
 // This should probably be done at the bottom using "IsSStx" etc functions.
 // It should also set the dcrutil tree type for the tx as well.
-func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allowHighFees bool) ([]*chainhash.Hash, error) {
+func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allowHighFees bool, orphanExpiration time.Time) ([]*chainhash.Hash, error) {
 	msgTx := tx.MsgTx()
 	txHash := tx.Hash()
 	// Don't accept the transaction if it already exists in the pool.  This
@@ -838,9 +901,11 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 	// forbid their relaying.
 	medianTime := mp.cfg.PastMedianTime()
 	if !mp.cfg.Policy.RelayNonStd {
+		stdPolicy := DefaultStandardnessPolicy()
+		stdPolicy.MaxTxVersion = mp.cfg.Policy.MaxTxVersion
+		stdPolicy.MinRelayTxFee = mp.cfg.Policy.MinRelayTxFee
 		err := checkTransactionStandard(tx, txType, nextBlockHeight,
-			medianTime, mp.cfg.Policy.MinRelayTxFee,
-			mp.cfg.Policy.MaxTxVersion)
+			medianTime, &stdPolicy)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -849,9 +914,15 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 			if !found {
 				rejectCode = wire.RejectNonstandard
 			}
+			var errCode ErrorCode
+			if rerr, ok := err.(RuleError); ok {
+				if txErr, ok := rerr.Err.(TxRuleError); ok {
+					errCode = txErr.ErrorCode
+				}
+			}
 			str := fmt.Sprintf("transaction %v is not standard: %v",
 				txHash, err)
-			return nil, txRuleError(rejectCode, str)
+			return nil, stdRuleError(errCode, rejectCode, str)
 		}
 	}
 
@@ -960,38 +1031,14 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 	}
 	delete(utxoView.Entries(), *txHash)
 
-	// Transaction is an orphan if any of the inputs don't exist.
-	var missingParents []*chainhash.Hash
-	for i, txIn := range msgTx.TxIn {
-		if i == 0 && txType == stake.TxTypeSSGen {
-			continue
-		}
-
-		entry := utxoView.LookupEntry(&txIn.PreviousOutPoint.Hash)
-		if entry == nil || entry.IsFullySpent() {
-			// Must make a copy of the hash here since the iterator
-			// is replaced and taking its address directly would
-			// result in all of the entries pointing to the same
-			// memory location and thus all be the final hash.
-			hashCopy := txIn.PreviousOutPoint.Hash
-			missingParents = append(missingParents, &hashCopy)
-
-			// Prevent a panic in the logger by continuing here if the
-			// transaction input is nil.
-			if entry == nil {
-				log.Tracef("Transaction %v uses unknown input %v "+
-					"and will be considered an orphan", txHash,
-					txIn.PreviousOutPoint.Hash)
-				continue
-			}
-			if entry.IsFullySpent() {
-				log.Tracef("Transaction %v uses full spent input %v "+
-					"and will be considered an orphan", txHash,
-					txIn.PreviousOutPoint.Hash)
-			}
-		}
+	// Transaction is an orphan if any of the inputs don't exist, while it is
+	// rejected outright if any of them are already spent or it is an orphan
+	// which has expired.
+	missingParents, err := checkInputsAvailable(tx, txType, utxoView,
+		&mp.cfg.Policy, orphanExpiration, time.Now())
+	if err != nil {
+		return nil, err
 	}
-
 	if len(missingParents) > 0 {
 		return missingParents, nil
 	}
@@ -1080,7 +1127,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 	// transaction does not exceeed 1000 less than the reserved space for
 	// high-priority transactions, don't require a fee for it.
 	// This applies to non-stake transactions only.
-	serializedSize := int64(msgTx.SerializeSize())
+	serializedSize := int64(MsgTxSerializeSize(msgTx))
 	minFee := calcMinRequiredTxRelayFee(serializedSize,
 		mp.cfg.Policy.MinRelayTxFee)
 	if txType == stake.TxTypeRegular { // Non-stake only
@@ -1202,6 +1249,66 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 	return nil, nil
 }
 
+// checkInputsAvailable checks that all of the outputs referenced by the inputs
+// of the passed transaction are available in the provided view.  Inputs which
+// reference outputs of transactions that are unknown to the view are reported
+// separately from those that reference outputs which are known to be spent
+// since the former might become available later and are therefore retryable
+// as orphans, while the latter can never become valid again.
+//
+// The hashes of the unknown transactions are returned as missing parents and a
+// rule error with a reject code of RejectDuplicate is returned when any inputs
+// reference spent outputs.  The stake base input of votes is skipped since it
+// does not reference a real output.
+//
+// Unknown inputs are only retryable until the passed orphan expiration, which
+// is the zero time for transactions that are not already orphans.  A rule error
+// is returned instead of the missing parents when the maximum orphan age of the
+// passed policy is enabled and the expiration is before the passed time.
+func checkInputsAvailable(tx *dcrutil.Tx, txType stake.TxType, utxoView *blockchain.UtxoViewpoint, policy *Policy, orphanExpiration, now time.Time) ([]*chainhash.Hash, error) {
+	txHash := tx.Hash()
+	var missingParents []*chainhash.Hash
+	for i, txIn := range tx.MsgTx().TxIn {
+		if i == 0 && txType == stake.TxTypeSSGen {
+			continue
+		}
+
+		prevOut := &txIn.PreviousOutPoint
+		entry := utxoView.LookupEntry(&prevOut.Hash)
+		if entry == nil {
+			// Must make a copy of the hash here since the iterator
+			// is replaced and taking its address directly would
+			// result in all of the entries pointing to the same
+			// memory location and thus all be the final hash.
+			hashCopy := prevOut.Hash
+			missingParents = append(missingParents, &hashCopy)
+			log.Tracef("Transaction %v uses unknown input %v and "+
+				"will be considered an orphan", txHash, prevOut.Hash)
+			continue
+		}
+
+		if entry.IsOutputSpent(prevOut.Index) {
+			str := fmt.Sprintf("transaction %v spends already spent "+
+				"output %v", txHash, prevOut)
+			return nil, txRuleError(wire.RejectDuplicate, str)
+		}
+	}
+
+	// Orphans are no longer retryable once they have been waiting for their
+	// missing parents for longer than the maximum age allowed by the
+	// policy.
+	if len(missingParents) > 0 && policy.MaxOrphanTxAge > 0 &&
+		!orphanExpiration.IsZero() && now.After(orphanExpiration) {
+
+		str := fmt.Sprintf("orphan transaction %v expired at %v while "+
+			"waiting for its missing parents", txHash,
+			orphanExpiration)
+		return nil, txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return missingParents, nil
+}
+
 // MaybeAcceptTransaction is the main workhorse for handling insertion of new
 // free-standing transactions into a memory pool.  It includes functionality
 // such as rejecting duplicate transactions, ensuring transactions follow all
@@ -1213,7 +1320,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit, allow
 func (mp *TxPool) MaybeAcceptTransaction(tx *dcrutil.Tx, isNew, rateLimit bool) ([]*chainhash.Hash, error) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	hashes, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true)
+	hashes, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true,
+		time.Time{})
 	mp.mtx.Unlock()
 
 	return hashes, err
@@ -1259,12 +1367,13 @@ func (mp *TxPool) processOrphans(hash *chainhash.Hash) []*dcrutil.Tx {
 			// leaving them in the orphan pool if not all parent
 			// transactions are known yet.
 			orphanHash := tx.Hash()
+			otx := mp.orphans[*orphanHash]
 			mp.removeOrphan(orphanHash)
 
 			// Potentially accept the transaction into the
 			// transaction pool.
 			missingParents, err := mp.maybeAcceptTransaction(tx,
-				true, true, true)
+				true, true, true, otx.expiration)
 			if err != nil {
 				// TODO: Remove orphans that depend on this
 				// failed transaction.
@@ -1275,8 +1384,9 @@ func (mp *TxPool) processOrphans(hash *chainhash.Hash) []*dcrutil.Tx {
 
 			if len(missingParents) > 0 {
 				// Transaction is still an orphan, so add it
-				// back.
-				mp.addOrphan(tx)
+				// back with its original expiration.  Expired
+				// orphans are rejected above.
+				mp.addOrphan(tx, otx.expiration)
 				continue
 			}
 
@@ -1399,7 +1509,7 @@ func (mp *TxPool) ProcessTransaction(tx *dcrutil.Tx, allowOrphan, rateLimit, all
 	// Potentially accept the transaction to the memory pool.
 	var missingParents []*chainhash.Hash
 	missingParents, err = mp.maybeAcceptTransaction(tx, true, rateLimit,
-		allowHighFees)
+		allowHighFees, time.Time{})
 	if err != nil {
 		return nil, err
 	}
@@ -1430,12 +1540,12 @@ func (mp *TxPool) ProcessTransaction(tx *dcrutil.Tx, allowOrphan, rateLimit, all
 		// NOTE: RejectDuplicate is really not an accurate
 		// reject code here, but it matches the reference
 		// implementation and there isn't a better choice due
-		// to the limited number of reject codes.  Missing
-		// inputs is assumed to mean they are already spent
-		// which is not really always the case.
+		// to the limited number of reject codes.  Inputs which
+		// reference spent outputs are rejected before this
+		// point, so missing inputs here are always unknown.
 		str := fmt.Sprintf("orphan transaction %v references "+
-			"outputs of unknown or fully-spent "+
-			"transaction %v", tx.Hash(), missingParents[0])
+			"outputs of unknown transaction %v", tx.Hash(),
+			missingParents[0])
 		return nil, txRuleError(wire.RejectDuplicate, str)
 	}
 
@@ -1594,7 +1704,7 @@ func New(cfg *Config) *TxPool {
 	return &TxPool{
 		cfg:           *cfg,
 		pool:          make(map[chainhash.Hash]*TxDesc),
-		orphans:       make(map[chainhash.Hash]*dcrutil.Tx),
+		orphans:       make(map[chainhash.Hash]*orphanTx),
 		orphansByPrev: make(map[chainhash.Hash]map[chainhash.Hash]*dcrutil.Tx),
 		outpoints:     make(map[wire.OutPoint]*dcrutil.Tx),
 		votes:         make(map[chainhash.Hash][]*VoteTx),
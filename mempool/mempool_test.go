@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain"
+	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainec"
 	"github.com/decred/dcrd/chaincfg/chainhash"
@@ -652,3 +653,204 @@ func TestOrphanEviction(t *testing.T) {
 		}
 	}
 }
+
+// TestOrphanExpiration ensures that orphans which have been in the orphan pool
+// longer than the maximum orphan age allowed by the policy are evicted.
+func TestOrphanExpiration(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// Create a chain of transactions rooted with the first spendable output
+	// provided by the harness.
+	chainedTxns, err := harness.CreateTxChain(outputs[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Add an orphan that expires immediately and ensure it is accepted.
+	harness.txPool.cfg.Policy.MaxOrphanTxAge = time.Nanosecond
+	expiredTx := chainedTxns[1]
+	_, err = harness.txPool.ProcessTransaction(expiredTx, true, false, true)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid orphan %v",
+			err)
+	}
+	if !harness.txPool.IsOrphanInPool(expiredTx.Hash()) {
+		t.Fatal("IsOrphanInPool: false for accepted orphan")
+	}
+
+	// Force the next orphan addition to scan for expired orphans and add
+	// another orphan that does not expire.  Ensure the expired orphan was
+	// evicted while the new one remains.
+	time.Sleep(time.Millisecond)
+	harness.txPool.nextExpireScan = time.Time{}
+	harness.txPool.cfg.Policy.MaxOrphanTxAge = time.Hour
+	tx := chainedTxns[2]
+	_, err = harness.txPool.ProcessTransaction(tx, true, false, true)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid orphan %v",
+			err)
+	}
+	if harness.txPool.IsOrphanInPool(expiredTx.Hash()) {
+		t.Fatal("IsOrphanInPool: true for expired orphan")
+	}
+	if !harness.txPool.IsOrphanInPool(tx.Hash()) {
+		t.Fatal("IsOrphanInPool: false for unexpired orphan")
+	}
+}
+
+// TestSpentInputReject ensures that transactions which spend outputs that are
+// known to be spent are rejected as opposed to being treated as orphans.
+func TestSpentInputReject(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// Mark the spendable output provided by the harness as spent in the
+	// fake chain and create a transaction which spends it.
+	prevOut := &outputs[0].outPoint
+	harness.chain.utxos.LookupEntry(&prevOut.Hash).SpendOutput(prevOut.Index)
+	tx, err := harness.CreateSignedTx(outputs, 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+
+	// Ensure the transaction is rejected even though orphans are allowed.
+	acceptedTxns, err := harness.txPool.ProcessTransaction(tx, true, false,
+		true)
+	if err == nil {
+		t.Fatal("ProcessTransaction: did not fail on transaction " +
+			"spending a spent output")
+	}
+	code, extracted := extractRejectCode(err)
+	if !extracted {
+		t.Fatalf("ProcessTransaction: failed to extract reject code "+
+			"from error %q", err)
+	}
+	if code != wire.RejectDuplicate {
+		t.Fatalf("ProcessTransaction: unexpected reject code -- got "+
+			"%v, want %v", code, wire.RejectDuplicate)
+	}
+	if len(acceptedTxns) != 0 {
+		t.Fatalf("ProcessTransaction: reported %d accepted "+
+			"transactions from failed attempt", len(acceptedTxns))
+	}
+
+	// Ensure the transaction is in neither the orphan pool nor the
+	// transaction pool.
+	if harness.txPool.IsOrphanInPool(tx.Hash()) {
+		t.Fatal("IsOrphanInPool: true for rejected transaction")
+	}
+	if harness.txPool.IsTransactionInPool(tx.Hash()) {
+		t.Fatal("IsTransactionInPool: true for rejected transaction")
+	}
+}
+
+// TestCheckInputsAvailable ensures inputs which reference unknown outputs are
+// reported as missing parents until the orphan expiration allowed by the
+// policy has passed while inputs which reference spent outputs are rejected.
+func TestCheckInputsAvailable(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	utxoView := harness.chain.utxos
+
+	// Create a transaction which spends a known output and one that spends
+	// an unknown output.
+	knownTx, err := harness.CreateSignedTx(outputs[0:1], 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	chainedTxns, err := harness.CreateTxChain(outputs[0], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	unknownTx := chainedTxns[1]
+
+	now := time.Now()
+	tests := []struct {
+		name        string
+		tx          *dcrutil.Tx
+		maxAge      time.Duration
+		expiration  time.Time
+		wantMissing int
+		wantCode    wire.RejectCode
+		wantErr     bool
+	}{{
+		name: "known input",
+		tx:   knownTx,
+	}, {
+		name:        "unknown input",
+		tx:          unknownTx,
+		maxAge:      time.Hour,
+		wantMissing: 1,
+	}, {
+		name:        "unknown input of unexpired orphan",
+		tx:          unknownTx,
+		maxAge:      time.Hour,
+		expiration:  now.Add(time.Minute),
+		wantMissing: 1,
+	}, {
+		name:       "unknown input of expired orphan",
+		tx:         unknownTx,
+		maxAge:     time.Hour,
+		expiration: now.Add(-time.Minute),
+		wantCode:   wire.RejectNonstandard,
+		wantErr:    true,
+	}, {
+		name:        "unknown input of expired orphan without max age",
+		tx:          unknownTx,
+		expiration:  now.Add(-time.Minute),
+		wantMissing: 1,
+	}}
+
+	for i, test := range tests {
+		policy := Policy{MaxOrphanTxAge: test.maxAge}
+		missing, err := checkInputsAvailable(test.tx,
+			stake.TxTypeRegular, utxoView, &policy, test.expiration,
+			now)
+		if test.wantErr {
+			code, extracted := extractRejectCode(err)
+			if err == nil || !extracted || code != test.wantCode {
+				t.Errorf("Test #%d (%s): unexpected error - got "+
+					"%v, want reject code %v", i, test.name,
+					err, test.wantCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test #%d (%s): unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+		if len(missing) != test.wantMissing {
+			t.Errorf("Test #%d (%s): unexpected number of missing "+
+				"parents - got %d, want %d", i, test.name,
+				len(missing), test.wantMissing)
+		}
+	}
+
+	// Ensure the transaction which spends the known output is rejected once
+	// the output is marked spent.
+	prevOut := &outputs[0].outPoint
+	utxoView.LookupEntry(&prevOut.Hash).SpendOutput(prevOut.Index)
+	policy := Policy{MaxOrphanTxAge: time.Hour}
+	_, err = checkInputsAvailable(knownTx, stake.TxTypeRegular, utxoView,
+		&policy, time.Time{}, now)
+	code, extracted := extractRejectCode(err)
+	if err == nil || !extracted || code != wire.RejectDuplicate {
+		t.Fatalf("checkInputsAvailable: unexpected error for spent "+
+			"input - got %v, want reject code %v", err,
+			wire.RejectDuplicate)
+	}
+}
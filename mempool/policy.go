@@ -11,6 +11,7 @@ import (
 
 	"github.com/decred/dcrd/blockchain"
 	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/txscript"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
@@ -74,6 +75,24 @@ const (
 		txscript.ScriptVerifyLowS
 )
 
+// MsgTxSerializeSize returns the size of the passed transaction the fee policy
+// uses to calculate fee rates and minimum required fees.
+//
+// Unlike some other cryptocurrencies, Decred does not discount the signature
+// scripts that make up the witness portion of a transaction, so this is the
+// full serialized size of the transaction including both the prefix and the
+// witness.
+func MsgTxSerializeSize(msgTx *wire.MsgTx) int {
+	return msgTx.SerializeSize()
+}
+
+// TxSerializeSize returns the size of the passed transaction the fee policy
+// uses to calculate fee rates and minimum required fees.  See
+// MsgTxSerializeSize for more details.
+func TxSerializeSize(tx *dcrutil.Tx) int {
+	return MsgTxSerializeSize(tx.MsgTx())
+}
+
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
 // transaction with the passed serialized size to be accepted into the memory
 // pool and relayed.
@@ -226,10 +245,9 @@ func checkInputsStandard(tx *dcrutil.Tx, txType stake.TxType, utxoView *blockcha
 // checkPkScriptStandard performs a series of checks on a transaction output
 // script (public key script) to ensure it is a "standard" public key script.
 // A standard public key script is one that is a recognized form, and for
-// multi-signature scripts, only contains from 1 to maxStandardMultiSigKeys
-// public keys.
+// multi-signature scripts, only contains from 1 to maxMultiSigKeys public keys.
 func checkPkScriptStandard(version uint16, pkScript []byte,
-	scriptClass txscript.ScriptClass) error {
+	scriptClass txscript.ScriptClass, maxMultiSigKeys int) error {
 	// Only default Bitcoin-style script is standard except for
 	// null data outputs.
 	if version != wire.DefaultPkScriptVersion {
@@ -249,15 +267,15 @@ func checkPkScriptStandard(version uint16, pkScript []byte,
 		}
 
 		// A standard multi-signature public key script must contain
-		// from 1 to maxStandardMultiSigKeys public keys.
+		// from 1 to maxMultiSigKeys public keys.
 		if numPubKeys < 1 {
 			str := "multi-signature script with no pubkeys"
 			return txRuleError(wire.RejectNonstandard, str)
 		}
-		if numPubKeys > maxStandardMultiSigKeys {
+		if numPubKeys > maxMultiSigKeys {
 			str := fmt.Sprintf("multi-signature script with %d "+
 				"public keys which is more than the allowed "+
-				"max of %d", numPubKeys, maxStandardMultiSigKeys)
+				"max of %d", numPubKeys, maxMultiSigKeys)
 			return txRuleError(wire.RejectNonstandard, str)
 		}
 
@@ -356,6 +374,53 @@ func isDust(txOut *wire.TxOut, minRelayTxFee dcrutil.Amount) bool {
 	return txOut.Value*1000/(3*int64(totalSize)) < int64(minRelayTxFee)
 }
 
+// StandardnessPolicy houses the policy, as opposed to consensus, limits which
+// are used to determine whether or not a transaction is standard.
+type StandardnessPolicy struct {
+	// MaxTxVersion is the maximum transaction version that is considered
+	// standard.
+	MaxTxVersion uint16
+
+	// MinRelayTxFee is the minimum transaction relay fee in atoms/kB which
+	// is used to determine whether or not an output is dust.
+	MinRelayTxFee dcrutil.Amount
+
+	// MaxTxSize is the maximum serialized size of a standard transaction.
+	MaxTxSize int
+
+	// MaxSigScriptSize is the maximum size of a standard transaction input
+	// signature script.
+	MaxSigScriptSize int
+
+	// MaxMultiSigKeys is the maximum number of public keys a standard
+	// multi-signature output script may contain.
+	MaxMultiSigKeys int
+
+	// MaxNullDataOutputs is the maximum number of outputs which only carry
+	// data that a standard regular transaction may contain.
+	MaxNullDataOutputs int
+
+	// PastMedianTime defines the function to retrieve the median time of
+	// the recent blocks in the main chain which is used to determine
+	// whether or not transactions with time based lock times are
+	// finalized.  When it is nil, such transactions are only considered
+	// finalized when all of their inputs have the maximum sequence number.
+	PastMedianTime func() time.Time
+}
+
+// DefaultStandardnessPolicy returns the standardness policy which is used by
+// the mempool when it is not otherwise configured.
+func DefaultStandardnessPolicy() StandardnessPolicy {
+	return StandardnessPolicy{
+		MaxTxVersion:       wire.TxVersion,
+		MinRelayTxFee:      DefaultMinRelayTxFee,
+		MaxTxSize:          maxStandardTxSize,
+		MaxSigScriptSize:   maxStandardSigScriptSize,
+		MaxMultiSigKeys:    maxStandardMultiSigKeys,
+		MaxNullDataOutputs: maxNullDataOutputs,
+	}
+}
+
 // checkTransactionStandard performs a series of checks on a transaction to
 // ensure it is a "standard" transaction.  A standard transaction is one that
 // conforms to several additional limiting cases over what is considered a
@@ -364,8 +429,7 @@ func isDust(txOut *wire.TxOut, minRelayTxFee dcrutil.Amount) bool {
 // of recognized forms, and not containing "dust" outputs (those that are
 // so small it costs more to process them than they are worth).
 func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
-	medianTime time.Time, minRelayTxFee dcrutil.Amount,
-	maxTxVersion uint16) error {
+	medianTime time.Time, policy *StandardnessPolicy) error {
 
 	// The transaction must be a currently supported version and serialize
 	// type.
@@ -373,18 +437,19 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 	if msgTx.SerType != wire.TxSerializeFull {
 		str := fmt.Sprintf("transaction is not serialized with all "+
 			"required data -- type %v", msgTx.SerType)
-		return txRuleError(wire.RejectNonstandard, str)
+		return stdRuleError(ErrTxSerType, wire.RejectNonstandard, str)
 	}
-	if msgTx.Version > maxTxVersion || msgTx.Version < 1 {
+	if msgTx.Version > policy.MaxTxVersion || msgTx.Version < 1 {
 		str := fmt.Sprintf("transaction version %d is not in the "+
-			"valid range of %d-%d", msgTx.Version, 1, maxTxVersion)
-		return txRuleError(wire.RejectNonstandard, str)
+			"valid range of %d-%d", msgTx.Version, 1,
+			policy.MaxTxVersion)
+		return stdRuleError(ErrTxVersion, wire.RejectNonstandard, str)
 	}
 
 	// The transaction must be finalized to be standard and therefore
 	// considered for inclusion in a block.
 	if !blockchain.IsFinalizedTransaction(tx, height, medianTime) {
-		return txRuleError(wire.RejectNonstandard,
+		return stdRuleError(ErrTxNotFinalized, wire.RejectNonstandard,
 			"transaction is not finalized")
 	}
 
@@ -393,10 +458,10 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 	// size of a transaction.  This also helps mitigate CPU exhaustion
 	// attacks.
 	serializedLen := msgTx.SerializeSize()
-	if serializedLen > maxStandardTxSize {
+	if serializedLen > policy.MaxTxSize {
 		str := fmt.Sprintf("transaction size of %v is larger than max "+
-			"allowed size of %v", serializedLen, maxStandardTxSize)
-		return txRuleError(wire.RejectNonstandard, str)
+			"allowed size of %v", serializedLen, policy.MaxTxSize)
+		return stdRuleError(ErrTxTooLarge, wire.RejectNonstandard, str)
 	}
 
 	for i, txIn := range msgTx.TxIn {
@@ -404,12 +469,13 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 		// maximum size allowed for a standard transaction.  See
 		// the comment on maxStandardSigScriptSize for more details.
 		sigScriptLen := len(txIn.SignatureScript)
-		if sigScriptLen > maxStandardSigScriptSize {
+		if sigScriptLen > policy.MaxSigScriptSize {
 			str := fmt.Sprintf("transaction input %d: signature "+
 				"script size of %d bytes is large than max "+
 				"allowed size of %d bytes", i, sigScriptLen,
-				maxStandardSigScriptSize)
-			return txRuleError(wire.RejectNonstandard, str)
+				policy.MaxSigScriptSize)
+			return stdRuleError(ErrSigScriptTooLarge,
+				wire.RejectNonstandard, str)
 		}
 
 		// Each transaction input signature script must only contain
@@ -417,7 +483,8 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 		if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
 			str := fmt.Sprintf("transaction input %d: signature "+
 				"script is not push only", i)
-			return txRuleError(wire.RejectNonstandard, str)
+			return stdRuleError(ErrSigScriptNotPushOnly,
+				wire.RejectNonstandard, str)
 		}
 
 	}
@@ -427,7 +494,8 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
 		scriptClass := txscript.GetScriptClass(txOut.Version, txOut.PkScript)
-		err := checkPkScriptStandard(txOut.Version, txOut.PkScript, scriptClass)
+		err := checkPkScriptStandard(txOut.Version, txOut.PkScript,
+			scriptClass, policy.MaxMultiSigKeys)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -437,7 +505,8 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 				rejectCode = wire.RejectNonstandard
 			}
 			str := fmt.Sprintf("transaction output %d: %v", i, err)
-			return txRuleError(rejectCode, str)
+			return stdRuleError(ErrNonStandardPkScript, rejectCode,
+				str)
 		}
 
 		// Accumulate the number of outputs which only carry data.  For
@@ -445,26 +514,62 @@ func checkTransactionStandard(tx *dcrutil.Tx, txType stake.TxType, height int64,
 		// "dust".
 		if scriptClass == txscript.NullDataTy {
 			numNullDataOutputs++
-		} else if txType == stake.TxTypeRegular && isDust(txOut, minRelayTxFee) {
+		} else if txType == stake.TxTypeRegular &&
+			isDust(txOut, policy.MinRelayTxFee) {
+
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
-			return txRuleError(wire.RejectDust, str)
+			return stdRuleError(ErrDustOutput, wire.RejectDust, str)
 		}
 	}
 
-	// A standard transaction must not have more than one output script that
-	// only carries data. However, certain types of standard stake transactions
-	// are allowed to have multiple OP_RETURN outputs, so only throw an error here
-	// if the tx is TxTypeRegular.
-	if numNullDataOutputs > maxNullDataOutputs && txType == stake.TxTypeRegular {
-		str := "more than one transaction output in a nulldata script for a " +
-			"regular type tx"
-		return txRuleError(wire.RejectNonstandard, str)
+	// A standard transaction must not have more than the maximum allowed
+	// number of output scripts that only carry data.  However, certain
+	// types of standard stake transactions are allowed to have multiple
+	// OP_RETURN outputs, so only throw an error here if the tx is
+	// TxTypeRegular.
+	if numNullDataOutputs > policy.MaxNullDataOutputs &&
+		txType == stake.TxTypeRegular {
+
+		str := fmt.Sprintf("%d transaction outputs in a nulldata "+
+			"script for a regular type tx which is more than the "+
+			"allowed max of %d", numNullDataOutputs,
+			policy.MaxNullDataOutputs)
+		return stdRuleError(ErrTooManyNullDataOutputs,
+			wire.RejectNonstandard, str)
 	}
 
 	return nil
 }
 
+// CheckTransactionStandard performs the same standardness checks the mempool
+// applies to transactions before accepting them, such as ensuring the
+// transaction is finalized as of the passed height and is a supported version
+// and serialize type, its size and input signature scripts are within the
+// limits of the policy, its output scripts are of recognized forms, and it
+// does not contain dust outputs.  These checks are policy as opposed to
+// consensus rules, so a transaction which fails them may still be valid in a
+// block.
+//
+// The maximum transaction size of the policy is further limited to the maximum
+// transaction size allowed by the passed network parameters.
+//
+// The returned error is a RuleError which encapsulates a TxRuleError when the
+// transaction is not standard.  The ErrorCode field of the TxRuleError
+// identifies the specific rule that was violated.
+func CheckTransactionStandard(tx *dcrutil.Tx, height int64, params *chaincfg.Params, policy StandardnessPolicy) error {
+	if policy.MaxTxSize > params.MaxTxSize {
+		policy.MaxTxSize = params.MaxTxSize
+	}
+	var medianTime time.Time
+	if policy.PastMedianTime != nil {
+		medianTime = policy.PastMedianTime()
+	}
+
+	txType := stake.DetermineTxType(tx.MsgTx())
+	return checkTransactionStandard(tx, txType, height, medianTime, &policy)
+}
+
 // minInt is a helper function to return the minimum of two ints.  This avoids
 // a math import and the need to cast to floats.
 func minInt(a, b int) int {
@@ -97,6 +97,46 @@ func TestCalcMinRequiredTxRelayFee(t *testing.T) {
 	}
 }
 
+// TestTxSerializeSize ensures the size used by the fee policy is the full
+// serialized size of a transaction, including the signature scripts.
+func TestTxSerializeSize(t *testing.T) {
+	msgTx := wire.NewMsgTx()
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0,
+		wire.TxTreeRegular), nil))
+	msgTx.AddTxOut(wire.NewTxOut(1e8, []byte{txscript.OP_TRUE}))
+
+	baseSize := MsgTxSerializeSize(msgTx)
+	for _, sigScriptLen := range []int{0, 1, 110, 1000} {
+		msgTx.TxIn[0].SignatureScript = make([]byte, sigScriptLen)
+
+		var buf bytes.Buffer
+		if err := msgTx.Serialize(&buf); err != nil {
+			t.Fatalf("Serialize: unexpected error: %v", err)
+		}
+		size := MsgTxSerializeSize(msgTx)
+		if size != buf.Len() {
+			t.Fatalf("MsgTxSerializeSize: unexpected size for "+
+				"signature script len %d - got %d, want %d",
+				sigScriptLen, size, buf.Len())
+		}
+		if txSize := TxSerializeSize(dcrutil.NewTx(msgTx)); txSize != size {
+			t.Fatalf("TxSerializeSize: unexpected size for "+
+				"signature script len %d - got %d, want %d",
+				sigScriptLen, txSize, size)
+		}
+
+		// Ensure every signature script byte, along with its length
+		// prefix, counts towards the size without any discount.
+		wantSize := baseSize + sigScriptLen +
+			wire.VarIntSerializeSize(uint64(sigScriptLen)) - 1
+		if size != wantSize {
+			t.Fatalf("MsgTxSerializeSize: unexpected size for "+
+				"signature script len %d - got %d, want %d",
+				sigScriptLen, size, wantSize)
+		}
+	}
+}
+
 // TestCheckPkScriptStandard tests the checkPkScriptStandard API.
 func TestCheckPkScriptStandard(t *testing.T) {
 	var pubKeys [][]byte
@@ -191,7 +231,8 @@ func TestCheckPkScriptStandard(t *testing.T) {
 			continue
 		}
 		scriptClass := txscript.GetScriptClass(0, script)
-		got := checkPkScriptStandard(0, script, scriptClass)
+		got := checkPkScriptStandard(0, script, scriptClass,
+			maxStandardMultiSigKeys)
 		if (test.isStandard && got != nil) ||
 			(!test.isStandard && got == nil) {
 
@@ -328,6 +369,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 		height     int64
 		isStandard bool
 		code       wire.RejectCode
+		errCode    ErrorCode
 	}{
 		{
 			name: "Typical pay-to-pubkey-hash transaction",
@@ -353,6 +395,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrTxSerType,
 		},
 		{
 			name: "Transaction version too high",
@@ -366,6 +409,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrTxVersion,
 		},
 		{
 			name: "Transaction is not finalized",
@@ -383,6 +427,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrTxNotFinalized,
 		},
 		{
 			name: "Transaction size is too large",
@@ -400,6 +445,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrTxTooLarge,
 		},
 		{
 			name: "Signature script size is too large",
@@ -418,6 +464,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrSigScriptTooLarge,
 		},
 		{
 			name: "Signature script that does more than push data",
@@ -436,6 +483,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrSigScriptNotPushOnly,
 		},
 		{
 			name: "Valid but non standard public key script",
@@ -452,6 +500,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrNonStandardPkScript,
 		},
 		{
 			name: "More than four nulldata outputs",
@@ -480,6 +529,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectNonstandard,
+			errCode:    ErrTooManyNullDataOutputs,
 		},
 		{
 			name: "Dust output",
@@ -496,6 +546,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: false,
 			code:       wire.RejectDust,
+			errCode:    ErrDustOutput,
 		},
 		{
 			name: "One nulldata output with 0 amount (standard)",
@@ -515,12 +566,13 @@ func TestCheckTransactionStandard(t *testing.T) {
 	}
 
 	medianTime := time.Now()
+	policy := DefaultStandardnessPolicy()
+	policy.MaxTxVersion = maxTxVersion
 	for _, test := range tests {
 		// Ensure standardness is as expected.
 		tx := dcrutil.NewTx(&test.tx)
 		err := checkTransactionStandard(tx, stake.DetermineTxType(&test.tx),
-			test.height, medianTime, DefaultMinRelayTxFee,
-			maxTxVersion)
+			test.height, medianTime, &policy)
 		if err == nil && test.isStandard {
 			// Test passes since function returned standard for a
 			// transaction which is intended to be standard.
@@ -558,5 +610,160 @@ func TestCheckTransactionStandard(t *testing.T) {
 				txrerr.RejectCode, test.code)
 			continue
 		}
+
+		// Ensure the error code is the expected one.
+		if txrerr.ErrorCode != test.errCode {
+			t.Errorf("checkTransactionStandard (%s): unexpected "+
+				"error code - got %v, want %v", test.name,
+				txrerr.ErrorCode, test.errCode)
+			continue
+		}
+	}
+}
+
+// TestCheckTransactionStandardPolicy ensures the exported
+// CheckTransactionStandard function applies the limits of the provided
+// standardness policy and network parameters and reports the specific rule
+// that was violated.
+func TestCheckTransactionStandardPolicy(t *testing.T) {
+	// Create a transaction with a time based lock time which is otherwise
+	// standard under the default policy.
+	prevOutHash, err := chainhash.NewHashFromStr("01")
+	if err != nil {
+		t.Fatalf("NewHashFromStr: unexpected error: %v", err)
+	}
+	addrHash := [20]byte{0x01}
+	addr, err := dcrutil.NewAddressPubKeyHash(addrHash[:],
+		&chaincfg.SimNetParams, chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	medianTime := time.Unix(1500000000, 0)
+	msgTx := wire.MsgTx{
+		SerType: wire.TxSerializeFull,
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Hash: *prevOutHash, Index: 1},
+			Sequence:         0,
+			SignatureScript:  bytes.Repeat([]byte{0x00}, 65),
+		}},
+		TxOut: []*wire.TxOut{{
+			Value:    100000000,
+			PkScript: pkScript,
+		}, {
+			Value:    0,
+			PkScript: []byte{txscript.OP_RETURN},
+		}},
+		LockTime: uint32(medianTime.Unix() - 1),
+	}
+	tx := dcrutil.NewTx(&msgTx)
+	txSize := msgTx.SerializeSize()
+	pastMedianTime := func() time.Time { return medianTime }
+
+	// smallTxParams are network parameters which do not allow transactions
+	// as large as the test transaction.
+	smallTxParams := chaincfg.SimNetParams
+	smallTxParams.MaxTxSize = txSize - 1
+
+	tests := []struct {
+		name    string
+		params  *chaincfg.Params
+		modify  func(policy *StandardnessPolicy)
+		errCode ErrorCode
+		err     bool
+	}{{
+		name:   "standard",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {},
+	}, {
+		name:   "no past median time",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.PastMedianTime = nil
+		},
+		errCode: ErrTxNotFinalized,
+		err:     true,
+	}, {
+		name:   "version too high",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.MaxTxVersion = 0
+		},
+		errCode: ErrTxVersion,
+		err:     true,
+	}, {
+		name:   "size over policy",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.MaxTxSize = txSize - 1
+		},
+		errCode: ErrTxTooLarge,
+		err:     true,
+	}, {
+		name:    "size over network max",
+		params:  &smallTxParams,
+		modify:  func(policy *StandardnessPolicy) {},
+		errCode: ErrTxTooLarge,
+		err:     true,
+	}, {
+		name:   "signature script over policy",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.MaxSigScriptSize = 64
+		},
+		errCode: ErrSigScriptTooLarge,
+		err:     true,
+	}, {
+		name:   "dust due to relay fee",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.MinRelayTxFee = 1e9
+		},
+		errCode: ErrDustOutput,
+		err:     true,
+	}, {
+		name:   "nulldata outputs over policy",
+		params: &chaincfg.SimNetParams,
+		modify: func(policy *StandardnessPolicy) {
+			policy.MaxNullDataOutputs = 0
+		},
+		errCode: ErrTooManyNullDataOutputs,
+		err:     true,
+	}}
+
+	for i, test := range tests {
+		policy := DefaultStandardnessPolicy()
+		policy.PastMedianTime = pastMedianTime
+		test.modify(&policy)
+		err := CheckTransactionStandard(tx, 1000, test.params, policy)
+		if !test.err {
+			if err != nil {
+				t.Errorf("Test #%d (%s): unexpected error: %v", i,
+					test.name, err)
+			}
+			continue
+		}
+
+		rerr, ok := err.(RuleError)
+		if !ok {
+			t.Errorf("Test #%d (%s): unexpected error type - got %T",
+				i, test.name, err)
+			continue
+		}
+		txrerr, ok := rerr.Err.(TxRuleError)
+		if !ok {
+			t.Errorf("Test #%d (%s): unexpected error type - got %T",
+				i, test.name, rerr.Err)
+			continue
+		}
+		if txrerr.ErrorCode != test.errCode {
+			t.Errorf("Test #%d (%s): unexpected error code - got %v, "+
+				"want %v", i, test.name, txrerr.ErrorCode,
+				test.errCode)
+		}
 	}
 }
@@ -291,7 +291,7 @@ func SortParentsByVotes(mp *mempool.TxPool, currentTopBlock chainhash.Hash, bloc
 	// Fetch the vote metadata for the provided block hashes from the
 	// mempool and filter out any blocks that do not have the minimum
 	// required number of votes.
-	minVotesRequired := (params.TicketsPerBlock / 2) + 1
+	minVotesRequired := uint16(blockchain.RequiredVotesForBlock(params))
 	voteMetadata := mp.VotesForBlocks(blocks)
 	filtered := make([]*blockWithNumVotes, 0, lenBlocks)
 	for i := range blocks {
@@ -1404,7 +1404,7 @@ mempoolLoop:
 		// during calcMinRelayFee which rounds up to the nearest full
 		// kilobyte boundary.  This is beneficial since it provides an
 		// incentive to create smaller transactions.
-		txSize := tx.MsgTx().SerializeSize()
+		txSize := mempool.TxSerializeSize(tx)
 		prioItem.feePerKB = (float64(txDesc.Fee) * float64(kilobyte)) /
 			float64(txSize)
 		prioItem.fee = txDesc.Fee
@@ -1965,8 +1965,8 @@ mempoolLoop:
 	// Return nil if we don't yet have enough voters; sometimes it takes a
 	// bit for the mempool to sync with the votes map and we end up down
 	// here despite having the relevant votes available in the votes map.
-	minimumVotesRequired :=
-		int((server.chainParams.TicketsPerBlock / 2) + 1)
+	minimumVotesRequired := blockchain.RequiredVotesForBlock(
+		server.chainParams)
 	if nextBlockHeight >= stakeValidationHeight &&
 		voters < minimumVotesRequired {
 		minrLog.Warnf("incongruent number of voters in mempool " +
@@ -2055,6 +2055,13 @@ mempoolLoop:
 		blockVersion = generatedBlockVersionTest
 	}
 
+	// Never generate a block with an older version than the one a majority
+	// of the network has upgraded to since it would be rejected.
+	requiredVersion := blockManager.chain.CalcNextBlockVersion()
+	if blockVersion < requiredVersion {
+		blockVersion = requiredVersion
+	}
+
 	// Figure out stake version.
 	generatedStakeVersion, err := blockManager.chain.CalcStakeVersionByHash(prevHash)
 	if err != nil {
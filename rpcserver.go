@@ -208,6 +208,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getgenerate":           handleGetGenerate,
 	"gethashespersec":       handleGetHashesPerSec,
 	"getheaders":            handleGetHeaders,
+	"getheadersinrange":     handleGetHeadersInRange,
 	"getinfo":               handleGetInfo,
 	"getmempoolinfo":        handleGetMempoolInfo,
 	"getmininginfo":         handleGetMiningInfo,
@@ -217,6 +218,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getrawmempool":         handleGetRawMempool,
 	"getrawtransaction":     handleGetRawTransaction,
 	"getstakedifficulty":    handleGetStakeDifficulty,
+	"getstakepoolinfo":      handleGetStakePoolInfo,
 	"getstakeversioninfo":   handleGetStakeVersionInfo,
 	"getstakeversions":      handleGetStakeVersions,
 	"getticketpoolvalue":    handleGetTicketPoolValue,
@@ -303,6 +305,8 @@ var rpcUnimplemented = map[string]struct{}{
 	"getblockchaininfo": {},
 	"getchaintips":      {},
 	"getnetworkinfo":    {},
+	"invalidateblock":   {},
+	"reconsiderblock":   {},
 }
 
 // Commands that are available to a limited user
@@ -2118,6 +2122,7 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		Size:          blockHeader.Size,
 		Time:          blockHeader.Timestamp.Unix(),
 		Nonce:         blockHeader.Nonce,
+		ExtraData:     hex.EncodeToString(blockHeader.ExtraData[:]),
 		StakeVersion:  blockHeader.StakeVersion,
 		Difficulty:    getDifficultyRatio(blockHeader.Bits),
 		NextHash:      nextHashString,
@@ -2458,7 +2463,8 @@ func (state *gbtWorkState) blockTemplateResult(bm *blockManager, useCoinbaseValu
 	msgBlock := template.Block
 	header := &msgBlock.Header
 	adjustedTime := state.timeSource.AdjustedTime()
-	maxTime := adjustedTime.Add(time.Second * blockchain.MaxTimeOffsetSeconds)
+	maxTime := adjustedTime.Add(blockchain.MaxFutureBlockTime(
+		bm.server.chainParams))
 	if header.Timestamp.After(maxTime) {
 		return nil, &dcrjson.RPCError{
 			Code: dcrjson.ErrRPCOutOfRange,
@@ -2749,6 +2755,11 @@ func (state *gbtWorkState) blockTemplateResult(bm *blockManager, useCoinbaseValu
 	templateID := encodeTemplateID(state.prevHash, state.lastGenerated)
 	reply := dcrjson.GetBlockTemplateResult{
 		Header:        hex.EncodeToString(headerBytes),
+		Version:       header.Version,
+		Height:        int64(header.Height),
+		PreviousHash:  header.PrevBlock.String(),
+		Bits:          strconv.FormatInt(int64(header.Bits), 16),
+		CurTime:       header.Timestamp.Unix(),
 		SigOpLimit:    blockchain.MaxSigOpsPerBlock,
 		SizeLimit:     maxBlockSize,
 		Transactions:  transactions,
@@ -3249,6 +3260,65 @@ func handleGetHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return &dcrjson.GetHeadersResult{Headers: hexBlockHeaders}, nil
 }
 
+// handleGetHeadersInRange implements the getheadersinrange command.
+func handleGetHeadersInRange(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*dcrjson.GetHeadersInRangeCmd)
+
+	// Ensure the requested range is sane and limited to the same maximum
+	// number of headers as the wire protocol headers message.
+	if c.StartHeight < 0 {
+		return nil, rpcInvalidError("Start height must not be less "+
+			"than zero - got %d", c.StartHeight)
+	}
+	if c.EndHeight <= c.StartHeight {
+		return nil, rpcInvalidError("End height must be greater than "+
+			"the start height - got start %d, end %d",
+			c.StartHeight, c.EndHeight)
+	}
+	if c.EndHeight-c.StartHeight > wire.MaxBlockHeadersPerMsg {
+		return nil, rpcInvalidError("Range must not include more than "+
+			"%d headers - got start %d, end %d",
+			wire.MaxBlockHeadersPerMsg, c.StartHeight, c.EndHeight)
+	}
+	best := s.chain.BestSnapshot()
+	if c.EndHeight > best.Height+1 {
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("End height %d is more than one "+
+				"past the best chain height %d", c.EndHeight,
+				best.Height),
+		}
+	}
+
+	blockHashes, err := s.chain.HeightRange(c.StartHeight, c.EndHeight)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCDatabase,
+			Message: "Failed to fetch hashes of block headers: " +
+				err.Error(),
+		}
+	}
+	blockHeaders, err := fetchHeaders(s.server.db, blockHashes)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCDatabase,
+			Message: "Failed to fetch headers in range: " +
+				err.Error(),
+		}
+	}
+
+	headers := make([]wire.BlockHeader, len(blockHeaders))
+	for i, h := range blockHeaders {
+		headers[i] = *h
+	}
+	hexHeaders, err := dcrjson.EncodeConcatenatedHeaders(headers)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Failed to serialize block headers")
+	}
+	return &dcrjson.GetHeadersInRangeResult{Headers: hexHeaders}, nil
+}
+
 // handleGetInfo implements the getinfo command. We only return the fields
 // that are not related to wallet functionality.
 func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3310,6 +3380,11 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		return nil, rpcInternalError(err.Error(),
 			"Could not calculate next stake difficulty")
 	}
+	_, poolSize, _, err := s.chain.NextLotteryData()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not obtain ticket pool size")
+	}
 
 	result := dcrjson.GetMiningInfoResult{
 		Blocks:           best.Height,
@@ -3322,6 +3397,7 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		HashesPerSec:     int64(s.server.cpuMiner.HashesPerSecond()),
 		NetworkHashPS:    networkHashesPerSec,
 		PooledTx:         uint64(s.server.txMemPool.Count()),
+		TicketPoolSize:   int64(poolSize),
 		TestNet:          cfg.TestNet,
 	}
 	return &result, nil
@@ -3710,6 +3786,40 @@ func convertVersionMap(m map[int]int) []dcrjson.VersionCount {
 	return sorted
 }
 
+// handleGetStakePoolInfo implements the getstakepoolinfo command.
+func handleGetStakePoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*dcrjson.GetStakePoolInfoCmd)
+	count := int32(1)
+	if c.Count != nil {
+		count = *c.Count
+		if count <= 0 {
+			return nil, rpcInvalidError("Invalid parameter, count " +
+				"must be > 0")
+		}
+	}
+
+	poolSizes, err := s.chain.StakePoolSizes(count)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not obtain stake pool sizes")
+	}
+
+	result := dcrjson.GetStakePoolInfoResult{
+		PoolInfo: make([]dcrjson.StakePoolInfo, 0, len(poolSizes)),
+	}
+	for _, ps := range poolSizes {
+		result.PoolInfo = append(result.PoolInfo, dcrjson.StakePoolInfo{
+			Hash:        ps.Hash.String(),
+			Height:      ps.Height,
+			PoolSize:    ps.PoolSize,
+			Immature:    ps.ImmatureTickets,
+			PoolSizeAll: int64(ps.PoolSize) + ps.ImmatureTickets,
+		})
+	}
+
+	return result, nil
+}
+
 // handleGetStakeVersionInfo implements the getstakeversioninfo command.
 func handleGetStakeVersionInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	count := int32(1)
@@ -4331,7 +4441,8 @@ func handleGetWorkSubmission(s *rpcServer, hexData string) (interface{}, error)
 	block := dcrutil.NewBlockDeepCopyCoinbase(msgBlock)
 
 	// Ensure the submitted block hash is less than the target difficulty.
-	err = blockchain.CheckProofOfWork(block, activeNetParams.PowLimit)
+	err = blockchain.CheckProofOfWork(&block.MsgBlock().Header,
+		activeNetParams.Params)
 	if err != nil {
 		// Anything other than a rule violation is an unexpected error,
 		// so return that error as an internal error.
@@ -4498,7 +4609,13 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	}
 	s.server.BroadcastMessage(wire.NewMsgPing(nonce))
 
-	return nil, nil
+	// Echo the timestamp provided by the caller, if any, so it may measure
+	// the round trip time of the command.
+	c := cmd.(*dcrjson.PingCmd)
+	return &dcrjson.PingResult{
+		Timestamp:  c.Timestamp,
+		TimeMillis: time.Now().UnixNano() / int64(time.Millisecond),
+	}, nil
 }
 
 // handleRebroadcastMissed implements the rebroadcastmissed command.
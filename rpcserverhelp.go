@@ -325,6 +325,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-merkleroot":        "The merkle root of the regular transaction tree",
 	"getblockheaderverboseresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
 	"getblockheaderverboseresult-nonce":             "The block nonce",
+	"getblockheaderverboseresult-extradata":         "Extra data field for the block, which is typically used as an extra nonce by miners",
 	"getblockheaderverboseresult-bits":              "The bits which represent the block difficulty",
 	"getblockheaderverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
 	"getblockheaderverboseresult-previousblockhash": "The hash of the previous block",
@@ -426,6 +427,16 @@ var helpDescsEnUS = map[string]string{
 	"getstakedifficultyresult-current": "The current top block's stake difficulty",
 	"getstakedifficultyresult-next":    "The calculated stake difficulty of the next block",
 
+	// GetStakePoolInfoCmd help.
+	"getstakepoolinfo--synopsis":      "Returns the ticket pool size and number of immature tickets for the best block and optionally its ancestors.",
+	"getstakepoolinfo-count":          "Number of blocks to return, starting with the best block and walking backwards.",
+	"getstakepoolinforesult-poolinfo": "Array of ticket pool information per block.",
+	"stakepoolinfo-hash":              "Hash of the block.",
+	"stakepoolinfo-height":            "Height of the block.",
+	"stakepoolinfo-poolsize":          "The number of live tickets in the ticket pool as of the block.",
+	"stakepoolinfo-immature":          "The number of tickets purchased in the block and the blocks before it that have not yet matured.",
+	"stakepoolinfo-poolsizeall":       "The total number of mined tickets which have not been spent or revoked, which is the pool size plus the immature tickets.",
+
 	// GetStakeVersionInfoCmd help.
 	"getstakeversioninfo--synopsis":           "Returns stake version statistics for one or more stake version intervals.",
 	"getstakeversioninfo-count":               "Number of intervals to return.",
@@ -523,6 +534,12 @@ var helpDescsEnUS = map[string]string{
 	"getheaders-hashstop":      "Optional block hash to stop including block headers for",
 	"getheadersresult-headers": "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 
+	// GetHeadersInRangeCmd help.
+	"getheadersinrange--synopsis":     "Returns the concatenated serialized headers of the main chain blocks from the start height up to, but not including, the end height",
+	"getheadersinrange-startheight":   "The height of the first block header to return",
+	"getheadersinrange-endheight":     "The height one past the last block header to return (at most 2000 more than the start height and one more than the best chain height)",
+	"getheadersinrangeresult-headers": "Concatenated serialized block headers of the requested range in hex",
+
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
@@ -545,6 +562,7 @@ var helpDescsEnUS = map[string]string{
 	"getmininginforesult-hashespersec":     "Recent hashes per second performance measurement while generating coins",
 	"getmininginforesult-networkhashps":    "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":         "Number of transactions in the memory pool",
+	"getmininginforesult-ticketpoolsize":   "Number of live tickets in the ticket pool as of the latest best block",
 	"getmininginforesult-testnet":          "Whether or not server is using testnet",
 
 	// GetMiningInfoCmd help.
@@ -563,6 +581,15 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytesrecv": "Total bytes received",
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
+	"getnettotalsresult-uploadtarget":   "The upload target limits (omitted when the node does not limit uploads)",
+
+	// GetNetTotalsUploadTarget help.
+	"getnettotalsuploadtarget-timeframe":               "Length of the upload target cycle in seconds",
+	"getnettotalsuploadtarget-target":                  "Number of bytes which may be uploaded per cycle",
+	"getnettotalsuploadtarget-target_reached":          "Whether or not the target has been reached in the current cycle",
+	"getnettotalsuploadtarget-serve_historical_blocks": "Whether or not historical blocks are still served",
+	"getnettotalsuploadtarget-bytes_left_in_cycle":     "Number of bytes which may still be uploaded in the current cycle",
+	"getnettotalsuploadtarget-time_left_in_cycle":      "Number of seconds remaining in the current cycle",
 
 	// GetPeerInfoResult help.
 	"getpeerinforesult-id":             "A unique node ID",
@@ -654,7 +681,13 @@ var helpDescsEnUS = map[string]string{
 
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
-		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
+		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.\n" +
+		"The optional timestamp is echoed back in the result so the round trip time of the command may be measured.",
+	"ping-timestamp": "An arbitrary timestamp, such as the current time of the caller in milliseconds, to echo back in the result",
+
+	// PingResult help.
+	"pingresult-timestamp":  "The timestamp provided with the command (omitted when not provided)",
+	"pingresult-timemillis": "The time of the server when the command was handled in milliseconds since 1 Jan 1970 GMT",
 
 	// RebroadcastMissed help.
 	"rebroadcastmissed--synopsis": "Asks the daemon to rebroadcast missed votes.\n",
@@ -776,6 +809,13 @@ var helpDescsEnUS = map[string]string{
 	"rescan--synopsis":   "Rescan blocks for transactions matching the loaded transaction filter.",
 	"rescan-blockhashes": "Concatenated block hashes to rescan.  Each next block must be a child of the previous.",
 
+	// RescanResult help.
+	"rescanresult-discovereddata": "Data for each rescanned block which contains transactions matching the filter",
+
+	// RescannedBlock help.
+	"rescannedblock-hash":         "The hash of the block",
+	"rescannedblock-transactions": "The matching transactions in the block, serialized and hex-encoded",
+
 	// -------- Decred-specific help --------
 
 	// EstimateFee help.
@@ -916,11 +956,13 @@ var rpcResultTypes = map[string][]interface{}{
 	"getcurrentnet":         {(*uint32)(nil)},
 	"getdifficulty":         {(*float64)(nil)},
 	"getstakedifficulty":    {(*dcrjson.GetStakeDifficultyResult)(nil)},
+	"getstakepoolinfo":      {(*dcrjson.GetStakePoolInfoResult)(nil)},
 	"getstakeversioninfo":   {(*dcrjson.GetStakeVersionInfoResult)(nil)},
 	"getstakeversions":      {(*dcrjson.GetStakeVersionsResult)(nil)},
 	"getgenerate":           {(*bool)(nil)},
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*dcrjson.GetHeadersResult)(nil)},
+	"getheadersinrange":     {(*dcrjson.GetHeadersInRangeResult)(nil)},
 	"getinfo":               {(*dcrjson.InfoChainResult)(nil)},
 	"getmempoolinfo":        {(*dcrjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*dcrjson.GetMiningInfoResult)(nil)},
@@ -938,7 +980,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"livetickets":           {(*dcrjson.LiveTicketsResult)(nil)},
 	"missedtickets":         {(*dcrjson.MissedTicketsResult)(nil)},
 	"node":                  nil,
-	"ping":                  nil,
+	"ping":                  {(*dcrjson.PingResult)(nil)},
 	"rebroadcastmissed":     nil,
 	"rebroadcastwinners":    nil,
 	"searchrawtransactions": {(*string)(nil), (*[]dcrjson.SearchRawTransactionsResult)(nil)},
@@ -966,7 +1008,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"notifynewtransactions":       nil,
 	"notifyreceived":              nil,
 	"notifyspent":                 nil,
-	"rescan":                      nil,
+	"rescan":                      {(*dcrjson.RescanResult)(nil)},
 	"stopnotifyblocks":            nil,
 	"stopnotifynewtransactions":   nil,
 	"stopnotifyreceived":          nil,
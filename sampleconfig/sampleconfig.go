@@ -244,6 +244,10 @@ const FileContents = `[Application Options]
 ; Limit orphan transaction pool to 1000 transactions.
 ; maxorphantx=1000
 
+; Evict orphan transactions which have been waiting for their missing parents
+; for longer than 15 minutes.  Set to 0 to disable age based eviction.
+; maxorphantxage=15m
+
 ; Do not accept transactions from remote peers.
 ; blocksonly=1
 
@@ -189,4 +189,10 @@ var (
 
 	// ErrP2SHStakeOpCodes indicates a P2SH script contained stake op codes.
 	ErrP2SHStakeOpCodes = errors.New("stake opcodes were found in a p2sh script")
+
+	// ErrNonStandardScript is returned by ExtractOutputAddress when the
+	// passed script is not one of the standard script classes which pay
+	// to a single address.
+	ErrNonStandardScript = errors.New("script does not pay to a single " +
+		"standard address")
 )
@@ -444,6 +444,36 @@ func TestIsPayToScriptHash(t *testing.T) {
 	}
 }
 
+// TestIsStakeScripts ensures the stake script classification functions report
+// the expected results for all the scripts in scriptClassTests.
+func TestIsStakeScripts(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range scriptClassTests {
+		script := mustParseShortForm(test.script)
+		checks := []struct {
+			name  string
+			fn    func([]byte) bool
+			class txscript.ScriptClass
+		}{
+			{"IsStakeSubmission", txscript.IsStakeSubmission,
+				txscript.StakeSubmissionTy},
+			{"IsStakeGen", txscript.IsStakeGen, txscript.StakeGenTy},
+			{"IsStakeRevocation", txscript.IsStakeRevocation,
+				txscript.StakeRevocationTy},
+			{"IsStakeChange", txscript.IsStakeChange,
+				txscript.StakeSubChangeTy},
+		}
+		for _, check := range checks {
+			shouldBe := test.class == check.class
+			if got := check.fn(script); got != shouldBe {
+				t.Errorf("%s: expected %s %v, got %v", test.name,
+					check.name, shouldBe, got)
+			}
+		}
+	}
+}
+
 // TestHasCanonicalPushes ensures the canonicalPush function properly determines
 // what is considered a canonical push for the purposes of removeOpcodeByData.
 func TestHasCanonicalPushes(t *testing.T) {
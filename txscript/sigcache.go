@@ -8,6 +8,7 @@ package txscript
 import (
 	"bytes"
 	"sync"
+	"sync/atomic"
 
 	"github.com/decred/dcrd/chaincfg/chainec"
 	"github.com/decred/dcrd/chaincfg/chainhash"
@@ -35,6 +36,11 @@ type sigCacheEntry struct {
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
 type SigCache struct {
+	// The following fields are only accessed atomically and are placed
+	// first to ensure they are 64-bit aligned.
+	hits   uint64
+	misses uint64
+
 	sync.RWMutex
 	validSigs  map[chainhash.Hash]sigCacheEntry
 	maxEntries uint
@@ -65,12 +71,37 @@ func (s *SigCache) Exists(sigHash chainhash.Hash, sig chainec.Signature, pubKey
 		pkEqual := bytes.Equal(entry.pubKey.SerializeCompressed(),
 			pubKey.SerializeCompressed())
 		sigEqual := bytes.Equal(entry.sig.Serialize(), sig.Serialize())
-		return pkEqual && sigEqual
+		if pkEqual && sigEqual {
+			atomic.AddUint64(&s.hits, 1)
+			return true
+		}
 	}
 
+	atomic.AddUint64(&s.misses, 1)
 	return false
 }
 
+// Stats returns the number of lookups performed via Exists which found a
+// matching entry in the SigCache and the number which did not, respectively.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&s.hits), atomic.LoadUint64(&s.misses)
+}
+
+// HitRate returns the fraction of the lookups performed via Exists which found
+// a matching entry in the SigCache.  It is zero when no lookups have been
+// performed.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) HitRate() float64 {
+	hits, misses := s.Stats()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
 // Add adds an entry for a signature over 'sigHash' under public key 'pubKey'
 // to the signature cache. In the event that the SigCache is 'full', an
 // existing entry is randomly chosen to be evicted in order to make space for
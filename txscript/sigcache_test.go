@@ -144,3 +144,43 @@ func TestSigCacheAddMaxEntriesZeroOrNegative(t *testing.T) {
 			"been added", len(sigCache.validSigs))
 	}
 }
+
+// TestSigCacheHitRate ensures lookups in the signature cache are tallied and
+// the hit rate reflects them.
+func TestSigCacheHitRate(t *testing.T) {
+	sigCache := NewSigCache(200)
+	if rate := sigCache.HitRate(); rate != 0 {
+		t.Fatalf("unexpected hit rate without lookups - got %v, want 0",
+			rate)
+	}
+
+	msg1, sig1, key1, err := genRandomSig()
+	if err != nil {
+		t.Fatalf("unable to generate random signature test data")
+	}
+	msg2, sig2, key2, err := genRandomSig()
+	if err != nil {
+		t.Fatalf("unable to generate random signature test data")
+	}
+	sigCache.Add(*msg1, sig1, key1)
+
+	// Perform three lookups which hit and one which misses.
+	for i := 0; i < 3; i++ {
+		if !sigCache.Exists(*msg1, sig1, key1) {
+			t.Fatalf("previously added item not found in signature " +
+				"cache")
+		}
+	}
+	if sigCache.Exists(*msg2, sig2, key2) {
+		t.Fatalf("item not added to signature cache found")
+	}
+
+	hits, misses := sigCache.Stats()
+	if hits != 3 || misses != 1 {
+		t.Fatalf("unexpected stats - got %d hits and %d misses, want "+
+			"3 hits and 1 miss", hits, misses)
+	}
+	if rate := sigCache.HitRate(); rate != 0.75 {
+		t.Fatalf("unexpected hit rate - got %v, want 0.75", rate)
+	}
+}
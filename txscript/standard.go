@@ -474,6 +474,51 @@ func IsStakeOutput(pkScript []byte) bool {
 		class == StakeSubChangeTy
 }
 
+// IsStakeSubmission returns true if the script is a standard stake submission
+// (ticket purchase) output script tagged with OP_SSTX, false otherwise.  Both
+// the pay-to-pubkey-hash and pay-to-script-hash variants are recognized.  This
+// is the same classification used when validating stake transactions.
+func IsStakeSubmission(script []byte) bool {
+	pops, err := parseScript(script)
+	if err != nil {
+		return false
+	}
+	return isStakeSubmission(pops)
+}
+
+// IsStakeGen returns true if the script is a standard stake generation (vote)
+// output script tagged with OP_SSGEN, false otherwise.  Both the
+// pay-to-pubkey-hash and pay-to-script-hash variants are recognized.
+func IsStakeGen(script []byte) bool {
+	pops, err := parseScript(script)
+	if err != nil {
+		return false
+	}
+	return isStakeGen(pops)
+}
+
+// IsStakeRevocation returns true if the script is a standard stake revocation
+// output script tagged with OP_SSRTX, false otherwise.  Both the
+// pay-to-pubkey-hash and pay-to-script-hash variants are recognized.
+func IsStakeRevocation(script []byte) bool {
+	pops, err := parseScript(script)
+	if err != nil {
+		return false
+	}
+	return isStakeRevocation(pops)
+}
+
+// IsStakeChange returns true if the script is a standard stake submission
+// change output script tagged with OP_SSTXCHANGE, false otherwise.  Both the
+// pay-to-pubkey-hash and pay-to-script-hash variants are recognized.
+func IsStakeChange(script []byte) bool {
+	pops, err := parseScript(script)
+	if err != nil {
+		return false
+	}
+	return isSStxChange(pops)
+}
+
 // GetStakeOutSubclass extracts the subclass (P2PKH or P2SH)
 // from a stake output.
 func GetStakeOutSubclass(pkScript []byte) (ScriptClass, error) {
@@ -1312,6 +1357,35 @@ func extractOneBytePush(po parsedOpcode) int {
 	return int(po.data[0])
 }
 
+// ExtractOutputAddress returns the address the passed default version output
+// script pays to.  Only the standard script classes which pay to a single
+// address are supported, namely pay-to-pubkey, pay-to-pubkey-hash, and
+// pay-to-script-hash along with their alternate signature suite variants and
+// the stake tagged ticket, vote, revocation, and ticket change outputs which
+// wrap them.  ErrNonStandardScript is returned for all other scripts, such as
+// multi-signature, null data, and non-standard scripts, as well as those which
+// encode an invalid address.
+func ExtractOutputAddress(script []byte, chainParams *chaincfg.Params) (dcrutil.Address, error) {
+	class, addrs, _, err := ExtractPkScriptAddrs(DefaultScriptVersion,
+		script, chainParams)
+	if err != nil {
+		return nil, ErrNonStandardScript
+	}
+
+	switch class {
+	case PubKeyTy, PubkeyAltTy, PubKeyHashTy, PubkeyHashAltTy,
+		ScriptHashTy, StakeSubmissionTy, StakeGenTy, StakeRevocationTy,
+		StakeSubChangeTy:
+	default:
+		return nil, ErrNonStandardScript
+	}
+	if len(addrs) != 1 {
+		return nil, ErrNonStandardScript
+	}
+
+	return addrs[0], nil
+}
+
 // ExtractPkScriptAltSigType returns the signature scheme to use for an
 // alternative check signature script.
 func ExtractPkScriptAltSigType(pkScript []byte) (int, error) {
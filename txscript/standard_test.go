@@ -392,6 +392,89 @@ func TestExtractPkScriptAddrs(t *testing.T) {
 	}
 }
 
+// TestExtractOutputAddress ensures the single address paid to by the standard
+// output script classes is extracted and all other scripts are rejected with
+// ErrNonStandardScript.
+func TestExtractOutputAddress(t *testing.T) {
+	t.Parallel()
+
+	const (
+		pkHash     = "0x433ec2ac1ffa1b7b7d027f564529c57197f9ae88"
+		scriptHash = "0x433ec2ac1ffa1b7b7d027f564529c57197f9ae88"
+		pubKey     = "0x02192d74d0cb94344c9569c2e77901573d8d7903c3ebec3a" +
+			"957724895dca52c6b4"
+	)
+	pkHashAddr := newAddressPubKeyHash(decodeHex(pkHash[2:]))
+	scriptHashAddr := newAddressScriptHash(decodeHex(scriptHash[2:]))
+	tests := []struct {
+		name   string
+		script string
+		addr   dcrutil.Address
+		err    error
+	}{{
+		name:   "p2pk",
+		script: "DATA_33 " + pubKey + " CHECKSIG",
+		addr:   newAddressPubKey(decodeHex(pubKey[2:])),
+	}, {
+		name:   "p2pkh",
+		script: "DUP HASH160 DATA_20 " + pkHash + " EQUALVERIFY CHECKSIG",
+		addr:   pkHashAddr,
+	}, {
+		name:   "p2sh",
+		script: "HASH160 DATA_20 " + scriptHash + " EQUAL",
+		addr:   scriptHashAddr,
+	}, {
+		name: "ticket p2pkh",
+		script: "SSTX DUP HASH160 DATA_20 " + pkHash + " EQUALVERIFY " +
+			"CHECKSIG",
+		addr: pkHashAddr,
+	}, {
+		name:   "vote p2sh",
+		script: "SSGEN HASH160 DATA_20 " + scriptHash + " EQUAL",
+		addr:   scriptHashAddr,
+	}, {
+		name: "revocation p2pkh",
+		script: "SSRTX DUP HASH160 DATA_20 " + pkHash + " EQUALVERIFY " +
+			"CHECKSIG",
+		addr: pkHashAddr,
+	}, {
+		name:   "ticket change p2sh",
+		script: "SSTXCHANGE HASH160 DATA_20 " + scriptHash + " EQUAL",
+		addr:   scriptHashAddr,
+	}, {
+		name:   "multisig",
+		script: "1 DATA_33 " + pubKey + " 1 CHECKMULTISIG",
+		err:    txscript.ErrNonStandardScript,
+	}, {
+		name:   "null data",
+		script: "RETURN DATA_4 0x01020304",
+		err:    txscript.ErrNonStandardScript,
+	}, {
+		name:   "nonstandard",
+		script: "1 2 ADD 3 EQUAL",
+		err:    txscript.ErrNonStandardScript,
+	}, {
+		name:   "malformed",
+		script: "DATA_20 0x01",
+		err:    txscript.ErrNonStandardScript,
+	}}
+
+	for i, test := range tests {
+		script := mustParseShortForm(test.script)
+		addr, err := txscript.ExtractOutputAddress(script,
+			&chaincfg.MainNetParams)
+		if err != test.err {
+			t.Errorf("Test #%d (%s) unexpected error - got %v, want %v",
+				i, test.name, err, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(addr, test.addr) {
+			t.Errorf("Test #%d (%s) unexpected address - got %v, "+
+				"want %v", i, test.name, addr, test.addr)
+		}
+	}
+}
+
 // TestCalcScriptInfo ensures the CalcScriptInfo provides the expected results
 // for various valid and invalid script pairs.
 func TestCalcScriptInfo(t *testing.T) {
@@ -858,6 +941,60 @@ var scriptClassTests = []scriptClassTest{
 			"9ae88 EQUAL",
 		class: txscript.ScriptHashTy,
 	},
+	{
+		name: "stake submission p2pkh",
+		script: "SSTX DUP HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c5" +
+			"7197f9ae88 EQUALVERIFY CHECKSIG",
+		class: txscript.StakeSubmissionTy,
+	},
+	{
+		name: "stake submission p2sh",
+		script: "SSTX HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c57197f" +
+			"9ae88 EQUAL",
+		class: txscript.StakeSubmissionTy,
+	},
+	{
+		name: "stake generation p2pkh",
+		script: "SSGEN DUP HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c5" +
+			"7197f9ae88 EQUALVERIFY CHECKSIG",
+		class: txscript.StakeGenTy,
+	},
+	{
+		name: "stake generation p2sh",
+		script: "SSGEN HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c57197f" +
+			"9ae88 EQUAL",
+		class: txscript.StakeGenTy,
+	},
+	{
+		name: "stake revocation p2pkh",
+		script: "SSRTX DUP HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c5" +
+			"7197f9ae88 EQUALVERIFY CHECKSIG",
+		class: txscript.StakeRevocationTy,
+	},
+	{
+		name: "stake revocation p2sh",
+		script: "SSRTX HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c57197f" +
+			"9ae88 EQUAL",
+		class: txscript.StakeRevocationTy,
+	},
+	{
+		name: "stake submission change p2pkh",
+		script: "SSTXCHANGE DUP HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c5" +
+			"7197f9ae88 EQUALVERIFY CHECKSIG",
+		class: txscript.StakeSubChangeTy,
+	},
+	{
+		name: "stake submission change p2sh",
+		script: "SSTXCHANGE HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c57197f" +
+			"9ae88 EQUAL",
+		class: txscript.StakeSubChangeTy,
+	},
+	{
+		name: "stake submission with trailing opcode",
+		script: "SSTX HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c571" +
+			"97f9ae88 EQUAL NOP",
+		class: txscript.NonStandardTy,
+	},
 	{
 		// Nulldata with no data at all.
 		name:   "nulldata",
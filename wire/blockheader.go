@@ -88,6 +88,12 @@ type BlockHeader struct {
 const blockHeaderLen = 180
 
 // BlockHash computes the block identifier hash for the given block header.
+//
+// The hash is the BLAKE-256 (14 rounds) hash of the blockHeaderLen byte
+// serialized header as produced by Serialize.  The transactions of a block do
+// not need to be available to calculate it since they are committed to via the
+// merkle roots in the header.  It is the same hash that identifies the full
+// block, such as the one returned by MsgBlock.BlockHash.
 func (h *BlockHeader) BlockHash() chainhash.Hash {
 	// Encode the header and hash256 everything prior to the number of
 	// transactions.  Ignore the error returns since there is no way the